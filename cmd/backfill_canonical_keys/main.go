@@ -0,0 +1,98 @@
+// One-time backfill of Links.canonical_key for links submitted before
+// canonical keys were stored. Reports (but does not merge or delete) any
+// existing links found to share a canonical key.
+//
+// Usage: go run --tags fts5 ./cmd/backfill_canonical_keys [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/julianlk522/modeep/db"
+	util "github.com/julianlk522/modeep/handler/util"
+)
+
+type linkWithKey struct {
+	ID  string
+	URL string
+	Key string
+}
+
+func main() {
+	dry_run := flag.Bool("dry-run", false, "report duplicates without writing keys")
+	flag.Parse()
+
+	rows, err := db.Client.Query("SELECT id, url FROM Links ORDER BY submit_date ASC;")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var links []linkWithKey
+	for rows.Next() {
+		var l linkWithKey
+		if err := rows.Scan(&l.ID, &l.URL); err != nil {
+			log.Fatal(err)
+		}
+
+		l.Key, err = util.GetCanonicalURLKey(l.URL)
+		if err != nil {
+			log.Printf("skipping link %s (%s): %s", l.ID, l.URL, err)
+			continue
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Report duplicates, oldest link first
+	links_by_key := make(map[string][]linkWithKey)
+	var keys_in_order []string
+	for _, l := range links {
+		if _, ok := links_by_key[l.Key]; !ok {
+			keys_in_order = append(keys_in_order, l.Key)
+		}
+		links_by_key[l.Key] = append(links_by_key[l.Key], l)
+	}
+
+	var duplicates_count int
+	for _, key := range keys_in_order {
+		if len(links_by_key[key]) < 2 {
+			continue
+		}
+
+		duplicates_count += len(links_by_key[key]) - 1
+		log.Printf("duplicate canonical key %s:", key)
+		for _, l := range links_by_key[key] {
+			log.Printf("\t%s\t%s", l.ID, l.URL)
+		}
+	}
+	log.Printf("%d links checked, %d duplicates found", len(links), duplicates_count)
+
+	if *dry_run {
+		return
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	for _, l := range links {
+		if _, err = tx.Exec(
+			"UPDATE Links SET canonical_key = ? WHERE id = ?;",
+			l.Key,
+			l.ID,
+		); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("canonical keys set for %d links", len(links))
+}
@@ -0,0 +1,77 @@
+// One-time backfill of Links.domain for links submitted before domains
+// were stored.
+//
+// Usage: go run --tags fts5 ./cmd/backfill_link_domains [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/julianlk522/modeep/db"
+	util "github.com/julianlk522/modeep/handler/util"
+)
+
+type linkWithDomain struct {
+	ID     string
+	URL    string
+	Domain string
+}
+
+func main() {
+	dry_run := flag.Bool("dry-run", false, "report domains without writing them")
+	flag.Parse()
+
+	rows, err := db.Client.Query("SELECT id, url FROM Links WHERE domain IS NULL;")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var links []linkWithDomain
+	for rows.Next() {
+		var l linkWithDomain
+		if err := rows.Scan(&l.ID, &l.URL); err != nil {
+			log.Fatal(err)
+		}
+
+		l.Domain, err = util.GetLinkDomain(l.URL)
+		if err != nil {
+			log.Printf("skipping link %s (%s): %s", l.ID, l.URL, err)
+			continue
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *dry_run {
+		for _, l := range links {
+			log.Printf("%s\t%s\t%s", l.ID, l.Domain, l.URL)
+		}
+		log.Printf("%d links checked", len(links))
+		return
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	for _, l := range links {
+		if _, err = tx.Exec(
+			"UPDATE Links SET domain = ? WHERE id = ?;",
+			l.Domain,
+			l.ID,
+		); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("domains set for %d links", len(links))
+}
@@ -0,0 +1,76 @@
+// One-time backfill of page_content_fts for links submitted before page
+// content was indexed. Uses each link's latest archive snapshot; links
+// without one are skipped (refreshing them will index their content).
+//
+// Usage: go run --tags fts5 ./cmd/backfill_page_content
+package main
+
+import (
+	"database/sql"
+	"log"
+	"os"
+
+	"github.com/julianlk522/modeep/db"
+	util "github.com/julianlk522/modeep/handler/util"
+)
+
+func main() {
+	rows, err := db.Client.Query(
+		`SELECT id
+		FROM Links
+		WHERE id NOT IN (SELECT link_id FROM page_content_fts);`,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var link_ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Fatal(err)
+		}
+		link_ids = append(link_ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	var indexed_count int
+	for _, link_id := range link_ids {
+		snapshot, err := util.GetLatestArchiveSnapshot(link_id)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			log.Fatal(err)
+		}
+
+		content, err := os.ReadFile(util.Archive_dir + "/" + snapshot.Hash)
+		if err != nil {
+			log.Printf("skipping link %s: %s", link_id, err)
+			continue
+		}
+
+		if err = util.SetPageContentText(
+			tx,
+			link_id,
+			content,
+			snapshot.ContentType,
+		); err != nil {
+			log.Fatal(err)
+		}
+		indexed_count++
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("page content indexed for %d of %d links", indexed_count, len(link_ids))
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -60,6 +61,12 @@ func LoadSpellfix() {
 		"sqlite-spellfix1",
 		&sqlite3.SQLiteDriver{
 			ConnectHook: func(c *sqlite3.SQLiteConn) error {
+				// SQLite's own math functions are only compiled in with
+				// the sqlite_math_functions build tag
+				// (pow() is needed for hot sort)
+				if err := c.RegisterFunc("pow", math.Pow, true); err != nil {
+					return err
+				}
 				return c.LoadExtension(spellfix_path, "sqlite3_spellfix_init")
 			},
 		},
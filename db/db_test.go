@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -47,3 +48,55 @@ func TestLoadSpellfix(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestMigrate(t *testing.T) {
+	// separate from TestLoadSpellfix's in-memory DB, which is left
+	// unmigrated
+	TestClient, err := sql.Open("sqlite-spellfix1", "file:migrate_test?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("could not open in-memory DB: %s", err)
+	}
+	defer TestClient.Close()
+
+	var db_dir string
+	backend_root_path := os.Getenv("MODEEP_BACKEND_ROOT")
+	if backend_root_path == "" {
+		_, dbtest_file, _, _ := runtime.Caller(0)
+		db_dir = filepath.Dir(dbtest_file)
+	} else {
+		db_dir = backend_root_path + "/db"
+	}
+
+	sql_dump, err := os.ReadFile(filepath.Join(db_dir, "modeep_test.db.sql"))
+	if err != nil {
+		t.Fatalf("could not read sql dump: %s", err)
+	} else if _, err = TestClient.Exec(string(sql_dump)); err != nil {
+		t.Fatalf("could not execute sql dump: %s", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2nd run should skip all
+	for range 2 {
+		if err := Migrate(TestClient); err != nil {
+			t.Fatal(err)
+		}
+
+		var applied int
+		if err := TestClient.QueryRow(
+			`SELECT COUNT(*) FROM "Schema Migrations";`,
+		).Scan(&applied); err != nil {
+			t.Fatal(err)
+		} else if applied != len(names) {
+			t.Fatalf("expected %d applied migrations, got %d", len(names), applied)
+		}
+	}
+
+	// last migration's changes are present
+	if _, err := TestClient.Exec(`SELECT link_id, cat FROM "Link Global Cats" LIMIT 1;`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+const SCHEMA_MIGRATIONS_TABLE = `CREATE TABLE IF NOT EXISTS "Schema Migrations" (
+	name TEXT PRIMARY KEY,
+	applied_at TEXT NOT NULL
+);`
+
+// Applies any db/migrations not yet recorded in "Schema Migrations", in
+// filename order and each in its own tx so that a failed one can be
+// fixed and retried
+func Migrate(client *sql.DB) error {
+	if _, err := client.Exec(SCHEMA_MIGRATIONS_TABLE); err != nil {
+		return err
+	}
+
+	// sorted by filename
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		var applied bool
+		if err := client.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM "Schema Migrations" WHERE name = ?);`,
+			name,
+		).Scan(&applied); err != nil {
+			return err
+		} else if applied {
+			continue
+		}
+
+		if err := applyMigration(client, name); err != nil {
+			return fmt.Errorf("could not apply %s: %s", name, err)
+		}
+		log.Printf("Applied %s", name)
+	}
+
+	return nil
+}
+
+func applyMigration(client *sql.DB, name string) error {
+	migration, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(migration)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO "Schema Migrations" (name, applied_at) VALUES (?, ?);`,
+		name,
+		time.Now().UTC().Format("2006-01-02 15:04:05"),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
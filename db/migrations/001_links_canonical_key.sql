@@ -0,0 +1,4 @@
+-- Canonical key used for duplicate link detection (see GetCanonicalURLKey).
+-- After applying, run cmd/backfill_canonical_keys to populate existing links.
+ALTER TABLE Links ADD COLUMN canonical_key TEXT;
+CREATE INDEX IF NOT EXISTS links_canonical_key_idx ON Links(canonical_key);
@@ -0,0 +1,15 @@
+-- History of background link health checks (see RunLinkHealthChecker).
+-- status_code is 0 when no usable response was received.
+CREATE TABLE IF NOT EXISTS "Link Checks" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	link_id TEXT NOT NULL REFERENCES Links(id) ON DELETE CASCADE,
+	status_code INTEGER NOT NULL,
+	final_url TEXT,
+	latency_ms INTEGER,
+	checked_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS link_checks_link_id_idx ON "Link Checks"(link_id, checked_at);
+
+-- Current health derived from the above, denormalized for filtering/sorting
+ALTER TABLE Links ADD COLUMN health TEXT;
+ALTER TABLE Links ADD COLUMN last_checked_at TEXT;
@@ -0,0 +1,15 @@
+-- Page snapshots captured at submission / refresh time.
+-- Content lives in $MODEEP_BACKEND_ROOT/db/archive/<hash>.
+CREATE TABLE IF NOT EXISTS "Archive Snapshots" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	link_id TEXT NOT NULL REFERENCES Links(id) ON DELETE CASCADE,
+	hash TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes INTEGER NOT NULL,
+	-- 1 if the page was larger than MAX_ARCHIVE_SNAPSHOT_BYTES and only its
+	-- start was kept
+	truncated INTEGER NOT NULL DEFAULT 0,
+	archived_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS archive_snapshots_link_id_idx ON "Archive Snapshots"(link_id, archived_at);
+CREATE INDEX IF NOT EXISTS archive_snapshots_hash_idx ON "Archive Snapshots"(hash);
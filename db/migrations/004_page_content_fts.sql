@@ -0,0 +1,6 @@
+-- Readable text extracted from each link's page at submission / refresh
+-- time, searchable via the content_contains filter.
+CREATE VIRTUAL TABLE IF NOT EXISTS page_content_fts USING fts5(
+	link_id UNINDEXED,
+	content
+);
@@ -0,0 +1,33 @@
+-- Bulk bookmark imports (POST /links/import).
+-- Items are stored as pending when the job is created and processed in the
+-- background, so an interrupted job resumes on restart.
+CREATE TABLE IF NOT EXISTS "Import Jobs" (
+	id TEXT PRIMARY KEY,
+	submitted_by TEXT NOT NULL,
+	format TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'queued',
+	created_at TEXT NOT NULL,
+	finished_at TEXT,
+	-- why the job failed, if it did
+	error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS import_jobs_submitted_by_idx ON "Import Jobs"(submitted_by, created_at);
+-- One active (queued or running) job per user
+CREATE UNIQUE INDEX IF NOT EXISTS import_jobs_active_submitted_by_idx ON "Import Jobs"(submitted_by)
+WHERE status IN ('queued', 'running');
+
+CREATE TABLE IF NOT EXISTS "Import Job Items" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id TEXT NOT NULL REFERENCES "Import Jobs"(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	cats TEXT NOT NULL DEFAULT '',
+	summary TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending',
+	link_id TEXT,
+	starred INTEGER NOT NULL DEFAULT 0,
+	tagged INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS import_job_items_job_id_idx ON "Import Job Items"(job_id, position);
+CREATE INDEX IF NOT EXISTS import_job_items_link_id_idx ON "Import Job Items"(link_id, status);
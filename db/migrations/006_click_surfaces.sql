@@ -0,0 +1,4 @@
+-- Where a link was clicked from (see model.ClickSurface).
+-- '' for clicks recorded before surfaces were tracked or sent without one.
+ALTER TABLE Clicks ADD COLUMN surface TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS clicks_link_id_idx ON Clicks(link_id, timestamp);
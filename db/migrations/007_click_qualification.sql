@@ -0,0 +1,6 @@
+-- Clicks are always recorded, but only those with no disqualified_reason
+-- ('duplicate', 'bot_user_agent' or 'bot_rate') count toward click_count.
+-- See GetClickDisqualification.
+ALTER TABLE Clicks ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE Clicks ADD COLUMN disqualified_reason TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS clicks_user_id_idx ON Clicks(user_id, ip_addr, timestamp);
@@ -0,0 +1,5 @@
+-- Registrable domain (eTLD+1) of each link's URL (see GetLinkDomain),
+-- for the domain filter and /domains counts.
+-- After applying, run cmd/backfill_link_domains to populate existing links.
+ALTER TABLE Links ADD COLUMN domain TEXT;
+CREATE INDEX IF NOT EXISTS links_domain_idx ON Links(domain);
@@ -0,0 +1,25 @@
+-- Curated, ordered link collections (e.g., reading lists).
+-- visibility is 'public' (listed on the owner's Treasure Map), 'unlisted'
+-- (viewable by anyone with the ID) or 'private' (owner only).
+CREATE TABLE IF NOT EXISTS Collections (
+	id TEXT PRIMARY KEY,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	visibility TEXT NOT NULL DEFAULT 'public',
+	created_by TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	last_updated TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS collections_created_by_idx ON Collections(created_by, visibility);
+
+-- position orders items within a collection, starting from 1
+CREATE TABLE IF NOT EXISTS "Collection Items" (
+	collection_id TEXT NOT NULL REFERENCES Collections(id) ON DELETE CASCADE,
+	link_id TEXT NOT NULL REFERENCES Links(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	added_at TEXT NOT NULL,
+	PRIMARY KEY (collection_id, link_id)
+);
+CREATE INDEX IF NOT EXISTS collection_items_position_idx ON "Collection Items"(collection_id, position);
+CREATE INDEX IF NOT EXISTS collection_items_link_id_idx ON "Collection Items"(link_id);
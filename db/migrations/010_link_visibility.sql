@@ -0,0 +1,9 @@
+-- Link visibility: 'public' or 'private' (submitter only, on their own
+-- Treasure Map). Private links are excluded from top links, cat counts,
+-- contributors, totals, spellfix ranks and everyone else's Treasure Maps.
+ALTER TABLE Links ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+CREATE INDEX IF NOT EXISTS links_visibility_idx ON Links(visibility);
+
+-- Selected from instead of Links by queries that must not see private links
+CREATE VIEW IF NOT EXISTS "Public Links" AS
+SELECT * FROM Links WHERE visibility = 'public';
@@ -0,0 +1,16 @@
+-- Discussion of links, separate from summaries (which describe them).
+-- parent_id is NULL for top-level comments; replies can't be replied to.
+-- Deleted comments keep their row (with text cleared) so replies to them
+-- still have a parent and are shown under a "[deleted]" placeholder.
+CREATE TABLE IF NOT EXISTS Comments (
+	id TEXT PRIMARY KEY,
+	link_id TEXT NOT NULL REFERENCES Links(id) ON DELETE CASCADE,
+	parent_id TEXT REFERENCES Comments(id),
+	submitted_by TEXT NOT NULL,
+	text TEXT NOT NULL,
+	submit_date TEXT NOT NULL,
+	last_updated TEXT NOT NULL,
+	deleted INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS comments_link_id_idx ON Comments(link_id, parent_id, submit_date);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON Comments(parent_id);
@@ -0,0 +1,47 @@
+-- User reports of links, summaries and tags, handled by admins.
+-- content_type is 'link', 'summary' or 'tag'; reason is 'spam', 'broken',
+-- 'nsfw' (mislabeled NSFW) or 'abusive'. status starts 'open' and becomes
+-- 'resolved', 'hidden' or 'dismissed' for every open report on the same
+-- content at once.
+CREATE TABLE IF NOT EXISTS Reports (
+	id TEXT PRIMARY KEY,
+	content_type TEXT NOT NULL,
+	content_id TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	details TEXT NOT NULL DEFAULT '',
+	reported_by TEXT NOT NULL,
+	reported_at TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'open',
+	resolved_by TEXT NOT NULL DEFAULT '',
+	resolved_at TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS reports_status_idx ON Reports(status, reported_at);
+-- 1 open report per user per piece of content
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_content_idx
+ON Reports(content_type, content_id, reported_by)
+WHERE status = 'open';
+
+-- Content hidden by an admin after a report. Hidden links are only
+-- visible to their submitter, like private links.
+CREATE TABLE IF NOT EXISTS "Hidden Content" (
+	content_type TEXT NOT NULL,
+	content_id TEXT NOT NULL,
+	hidden_by TEXT NOT NULL,
+	hidden_at TEXT NOT NULL,
+	PRIMARY KEY (content_type, content_id)
+);
+
+DROP VIEW IF EXISTS "Public Links";
+CREATE VIEW "Public Links" AS
+SELECT * FROM Links
+WHERE visibility = 'public'
+AND id NOT IN (SELECT content_id FROM "Hidden Content" WHERE content_type = 'link');
+
+-- Selected from instead of Summaries and Tags by queries that must not
+-- see hidden ones
+CREATE VIEW IF NOT EXISTS "Visible Summaries" AS
+SELECT * FROM Summaries
+WHERE id NOT IN (SELECT content_id FROM "Hidden Content" WHERE content_type = 'summary');
+CREATE VIEW IF NOT EXISTS "Visible Tags" AS
+SELECT * FROM Tags
+WHERE id NOT IN (SELECT content_id FROM "Hidden Content" WHERE content_type = 'tag');
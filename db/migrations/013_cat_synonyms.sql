@@ -0,0 +1,42 @@
+-- Cats that mean the same thing as another (e.g., js => javascript),
+-- proposed by users and approved or rejected by admins.
+-- cat and synonym_of are lowercase. status is 'pending', 'approved' or
+-- 'rejected'.
+CREATE TABLE IF NOT EXISTS "Cat Synonyms" (
+	id TEXT PRIMARY KEY,
+	cat TEXT NOT NULL,
+	synonym_of TEXT NOT NULL,
+	proposed_by TEXT NOT NULL,
+	proposed_at TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	reviewed_by TEXT NOT NULL DEFAULT '',
+	reviewed_at TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS cat_synonyms_status_idx ON "Cat Synonyms"(status, proposed_at);
+-- A cat can only be an approved synonym of 1 other
+CREATE UNIQUE INDEX IF NOT EXISTS cat_synonyms_approved_cat_idx
+ON "Cat Synonyms"(cat)
+WHERE status = 'approved';
+
+-- Each cat in an approved synonym group (including the cat the others
+-- are synonyms of) and the group's canonical form: the singular of
+-- synonym_of, so that its plural/singular variants share the group
+CREATE VIEW IF NOT EXISTS "Approved Cat Synonyms" AS
+WITH Approved AS (
+	SELECT cat, synonym_of
+	FROM "Cat Synonyms"
+	WHERE status = 'approved'
+),
+Members AS (
+	SELECT cat, synonym_of FROM Approved
+	UNION
+	SELECT synonym_of, synonym_of FROM Approved
+)
+SELECT
+	cat,
+	CASE
+		WHEN synonym_of LIKE '%sses' THEN substr(synonym_of, 1, length(synonym_of) - 2)
+		WHEN synonym_of LIKE '%s' AND NOT synonym_of LIKE '%ss' THEN substr(synonym_of, 1, length(synonym_of) - 1)
+		ELSE synonym_of
+	END AS canonical
+FROM Members;
@@ -0,0 +1,10 @@
+-- Cat hierarchy, e.g., sqlite => databases => programming, set by admins.
+-- Each cat has at most 1 parent. cat is lowercase and singular (so that
+-- its plural/singular variants share the parent); parent is lowercase.
+CREATE TABLE IF NOT EXISTS "Cat Parents" (
+	cat TEXT PRIMARY KEY,
+	parent TEXT NOT NULL,
+	set_by TEXT NOT NULL,
+	set_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cat_parents_parent_idx ON "Cat Parents"(parent);
@@ -0,0 +1,12 @@
+-- Wiki-style cat descriptions: anyone signed in can edit, and every edit
+-- is kept. The current description is a cat's latest revision.
+-- cat is lowercase and singular (so that its plural/singular variants
+-- share the description).
+CREATE TABLE IF NOT EXISTS "Cat Description Revisions" (
+	id TEXT PRIMARY KEY,
+	cat TEXT NOT NULL,
+	text TEXT NOT NULL,
+	edited_by TEXT NOT NULL,
+	edited_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cat_description_revisions_cat_idx ON "Cat Description Revisions"(cat, edited_at);
@@ -0,0 +1,56 @@
+-- Each link's global cats, 1 row per cat, so that cats and cat
+-- co-occurrences can be counted without splitting every link's
+-- global_cats. Kept in sync with Links.global_cats as it is set.
+-- normalized_cat is the lowercase singular form of cat (so that
+-- plural/singular variants are counted together).
+CREATE TABLE IF NOT EXISTS "Link Global Cats" (
+	link_id TEXT NOT NULL,
+	cat TEXT NOT NULL,
+	normalized_cat TEXT NOT NULL,
+	PRIMARY KEY (link_id, normalized_cat)
+);
+CREATE INDEX IF NOT EXISTS link_global_cats_normalized_cat_idx ON "Link Global Cats"(normalized_cat);
+
+-- Backfill
+WITH RECURSIVE GlobalCatsSplit(link_id, global_cat, str) AS (
+	SELECT id, '', global_cats||','
+	FROM Links
+	WHERE global_cats IS NOT NULL
+	UNION ALL SELECT
+		link_id,
+		substr(str, 0, instr(str, ',')),
+		substr(str, instr(str, ',') + 1)
+	FROM GlobalCatsSplit
+	WHERE str != ''
+)
+INSERT OR IGNORE INTO "Link Global Cats" (link_id, cat, normalized_cat)
+SELECT
+	link_id,
+	global_cat,
+	CASE
+		WHEN LOWER(global_cat) LIKE '%sses' THEN substr(LOWER(global_cat), 1, length(LOWER(global_cat)) - 2)
+		WHEN LOWER(global_cat) LIKE '%s' AND NOT LOWER(global_cat) LIKE '%ss' THEN substr(LOWER(global_cat), 1, length(LOWER(global_cat)) - 1)
+		ELSE LOWER(global_cat)
+	END
+FROM GlobalCatsSplit
+WHERE global_cat != '';
+
+-- Each pair of a link's normalized global cats (normalized_cat_a <
+-- normalized_cat_b), so that cat co-occurrences are counted by grouping
+-- pairs rather than by self-joining "Link Global Cats" on every request.
+-- Kept in sync along with "Link Global Cats".
+CREATE TABLE IF NOT EXISTS "Link Global Cat Pairs" (
+	link_id TEXT NOT NULL,
+	normalized_cat_a TEXT NOT NULL,
+	normalized_cat_b TEXT NOT NULL,
+	PRIMARY KEY (link_id, normalized_cat_a, normalized_cat_b)
+);
+CREATE INDEX IF NOT EXISTS link_global_cat_pairs_cats_idx ON "Link Global Cat Pairs"(normalized_cat_a, normalized_cat_b);
+
+-- Backfill
+INSERT OR IGNORE INTO "Link Global Cat Pairs" (link_id, normalized_cat_a, normalized_cat_b)
+SELECT a.link_id, a.normalized_cat, b.normalized_cat
+FROM "Link Global Cats" a
+INNER JOIN "Link Global Cats" b
+	ON b.link_id = a.link_id
+	AND a.normalized_cat < b.normalized_cat;
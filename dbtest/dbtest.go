@@ -48,6 +48,11 @@ func SetupTestDB() error {
 	}
 	log.Printf("verified test DB dump data loaded")
 
+	// dump predates db/migrations
+	if err = db.Migrate(TestClient); err != nil {
+		return err
+	}
+
 	// verify in-memory DB has spellfix1
 	if _, err = TestClient.Exec(`SELECT word, rank FROM global_cats_spellfix;`); err != nil {
 		return err
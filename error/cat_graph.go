@@ -0,0 +1,7 @@
+package error
+
+import "errors"
+
+var (
+	ErrInvalidMinWeightParams error = errors.New("invalid min_weight params provided (must be a positive integer)")
+)
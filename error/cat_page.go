@@ -0,0 +1,17 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoCat                   error = errors.New("no cat provided")
+	ErrCatPageHasMultipleCats  error = errors.New("cat pages are for a single cat")
+	ErrNoCatDescriptionText    error = errors.New("no description text provided")
+	ErrCatDescriptionUnchanged error = errors.New("description is unchanged")
+)
+
+func CatDescriptionLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("description too long (max %d chars)", limit)
+}
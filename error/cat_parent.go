@@ -0,0 +1,13 @@
+package error
+
+import "errors"
+
+var (
+	ErrNoCatParentCats          error = errors.New("no cat or parent provided")
+	ErrCatParentHasMultipleCats error = errors.New("cat and parent must each be a single cat")
+	ErrCatIsOwnParent           error = errors.New("cat can't be its own parent")
+	ErrCatParentIsDescendant    error = errors.New("parent is a descendant of cat")
+	ErrNoCatParent              error = errors.New("cat has no parent")
+	ErrInvalidDescendantsParams error = errors.New("invalid descendants params provided")
+	ErrInvalidTreeParams        error = errors.New("invalid tree params provided")
+)
@@ -0,0 +1,18 @@
+package error
+
+import "errors"
+
+var (
+	ErrNoCatSynonymID            error = errors.New("no cat synonym ID provided")
+	ErrNoCatSynonymWithID        error = errors.New("no cat synonym found with given ID")
+	ErrNoCatSynonymCats          error = errors.New("no cat or synonym_of provided")
+	ErrCatSynonymHasMultipleCats error = errors.New("cat and synonym_of must each be a single cat")
+	ErrInvalidCatSynonymStatus   error = errors.New("invalid cat synonym status provided (valid: pending, approved, rejected)")
+	ErrInvalidCatSynonymAction   error = errors.New("invalid cat synonym action provided (valid: approve, reject)")
+	ErrCatsAlreadyMerged         error = errors.New("cats are already merged as spelling variants or synonyms")
+	ErrCatAlreadyHasSynonym      error = errors.New("cat is already a synonym of another cat")
+	ErrSynonymOfIsAlreadySynonym error = errors.New("synonym_of is itself a synonym of another cat; propose a synonym of that cat instead")
+	ErrCatHasSynonyms            error = errors.New("other cats are synonyms of this cat; propose it as synonym_of instead")
+	ErrCatSynonymAlreadyProposed error = errors.New("this synonym has already been proposed")
+	ErrCatSynonymAlreadyReviewed error = errors.New("cat synonym has already been approved or rejected")
+)
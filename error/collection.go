@@ -0,0 +1,34 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoCollectionID              error = errors.New("no collection ID provided")
+	ErrNoCollectionWithID          error = errors.New("no collection found with given ID")
+	ErrNoCollectionTitle           error = errors.New("no collection title provided")
+	ErrInvalidCollectionVisibility error = errors.New("invalid collection visibility provided (valid: public, unlisted, private)")
+	ErrDoesntOwnCollection         error = errors.New("not your collection")
+	ErrLinkAlreadyInCollection     error = errors.New("link already in collection")
+	ErrLinkNotInCollection         error = errors.New("link is not in collection")
+	ErrCannotCollectPrivateLink    error = errors.New("private links cannot be added to collections")
+	ErrInvalidCollectionOrder      error = errors.New("invalid collection order provided: must include each of the collection's link IDs exactly once")
+)
+
+func CollectionTitleLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("collection title too long (max %d chars)", limit)
+}
+
+func CollectionDescriptionLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("collection description too long (max %d chars)", limit)
+}
+
+func CollectionNoteLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("collection note too long (max %d chars)", limit)
+}
+
+func ErrTooManyCollectionLinks(limit int) error {
+	return fmt.Errorf("collection is full (max %d links)", limit)
+}
@@ -0,0 +1,19 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoCommentID           error = errors.New("no comment ID provided")
+	ErrNoCommentWithID       error = errors.New("no comment found with given ID")
+	ErrNoCommentText         error = errors.New("no comment text provided")
+	ErrDoesntOwnComment      error = errors.New("not your comment")
+	ErrCannotReplyToReply    error = errors.New("cannot reply to a reply")
+	ErrParentCommentNotFound error = errors.New("parent comment not found on this link")
+)
+
+func CommentLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("comment too long (max %d chars)", limit)
+}
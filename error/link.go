@@ -7,20 +7,25 @@ import (
 
 var (
 	// Query links
-	ErrInvalidLinkID       error = errors.New("invalid link ID provided")
-	ErrInvalidPeriod       error = errors.New("invalid period provided")
-	ErrInvalidPageParams   error = errors.New("invalid page provided")
-	ErrInvalidNSFWParams   error = errors.New("invalid NSFW params provided")
-	ErrInvalidSortByParams error = errors.New("invalid sort_by params provided")
-	ErrInvalidStars        error = errors.New("invalid number of stars provided")
-	ErrSameNumberOfStars   error = errors.New("invalid number of stars provided: same as before")
-	ErrNoLinkID            error = errors.New("no link ID provided")
-	ErrNoLinkWithID        error = errors.New("no link found with given ID")
-	ErrNoCats              error = errors.New("no cats provided")
-	ErrNoNeuteredCats      error = errors.New("no neutered cat filters provided")
-	ErrNoPeriod            error = errors.New("no period provided")
+	ErrInvalidLinkID            error = errors.New("invalid link ID provided")
+	ErrInvalidPeriod            error = errors.New("invalid period provided")
+	ErrInvalidPageParams        error = errors.New("invalid page provided")
+	ErrInvalidCursor            error = errors.New("invalid cursor provided")
+	ErrInvalidNSFWParams        error = errors.New("invalid NSFW params provided")
+	ErrInvalidExcludeDeadParams error = errors.New("invalid exclude_dead params provided")
+	ErrInvalidSortByParams      error = errors.New("invalid sort_by params provided")
+	ErrInvalidDomain            error = errors.New("invalid domain provided")
+	ErrInvalidStars             error = errors.New("invalid number of stars provided")
+	ErrSameNumberOfStars        error = errors.New("invalid number of stars provided: same as before")
+	ErrNoLinkID                 error = errors.New("no link ID provided")
+	ErrNoLinkWithID             error = errors.New("no link found with given ID")
+	ErrNoCats                   error = errors.New("no cats provided")
+	ErrNoNeuteredCats           error = errors.New("no neutered cat filters provided")
+	ErrNoPeriod                 error = errors.New("no period provided")
 	// Preview Img
 	ErrPreviewImgNotFound error = errors.New("preview image not found at specified path")
+	// Archive
+	ErrNoArchiveSnapshot error = errors.New("no archived snapshot found for link")
 	// Add link
 	ErrNoURL                 error = errors.New("no URL provided")
 	ErrInvalidURL            error = errors.New("invalid URL provided")
@@ -29,16 +34,45 @@ var (
 	ErrCannotStarOwnLink     error = errors.New("cannot star your own link")
 	ErrLinkAlreadyStarred    error = errors.New("link already starred")
 	ErrLinkNotStarred        error = errors.New("link is not starred")
+	ErrInvalidLinkVisibility error = errors.New("invalid link visibility provided (valid: public, private)")
 	// Delete link
 	ErrDoesntOwnLink error = errors.New("not your link; cannot delete")
+	// Refresh link metadata
+	ErrCannotRefreshUnownedLink error = errors.New("not your link; cannot refresh metadata")
+	// Edit link visibility
+	ErrCannotEditUnownedLinkVisibility error = errors.New("not your link; cannot change visibility")
+	// Import links
+	ErrNoImportFile               error = errors.New("no bookmarks file provided")
+	ErrInvalidImportFormat        error = errors.New("invalid import format provided (valid: netscape, pinboard, pocket)")
+	ErrUndetectedImportFormat     error = errors.New("could not detect bookmarks file format; specify one with format")
+	ErrNoBookmarksFound           error = errors.New("no bookmarks found in file")
+	ErrImportAlreadyInProgress    error = errors.New("you already have an import in progress")
+	ErrNoImportJobID              error = errors.New("no import job ID provided")
+	ErrNoImportJobWithID          error = errors.New("no import job found with given ID")
+	ErrCannotViewUnownedImportJob error = errors.New("not your import job; cannot view")
+	// Feeds
+	ErrInvalidFeedFormat error = errors.New("invalid feed format (valid: atom, rss)")
 	// Click link
-	ErrNoUserOrIP error = errors.New("click cannot be recorded without either authorized user ID or IP (neither found)")
+	ErrNoUserOrIP          error = errors.New("click cannot be recorded without either authorized user ID or IP (neither found)")
+	ErrInvalidClickSurface error = errors.New("invalid click surface provided (valid: top_links, tmap, cat_page)")
+	// Link clicks
+	ErrInvalidClickBucket          error = errors.New("invalid bucket provided (valid: day, week)")
+	ErrInvalidDisqualifiedParams   error = errors.New("invalid disqualified params provided")
+	ErrCannotViewUnownedLinkClicks error = errors.New("not your link; cannot view clicks")
 )
 
 func ErrMaxDailyLinkSubmissionsReached(limit int) error {
 	return fmt.Errorf("you have submitted the max amount of links for today (%d)", limit)
 }
 
+func ErrImportFileTooLarge(limit int) error {
+	return fmt.Errorf("bookmarks file too large (max %d bytes)", limit)
+}
+
+func ErrTooManyImportItems(limit int) error {
+	return fmt.Errorf("too many bookmarks in file (max %d per import)", limit)
+}
+
 func ErrLinkURLCharsExceedLimit(limit int) error {
 	return fmt.Errorf("URL too long (max %d chars)", limit)
 }
@@ -62,3 +96,8 @@ func ErrDuplicateLink(url string, duplicate_link_id string) error {
 		duplicate_link_id,
 	)
 }
+
+// (for duplicates the requester can't see)
+func ErrDuplicateLinkNotVisible(url string) error {
+	return fmt.Errorf("URL %s already submitted", url)
+}
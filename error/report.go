@@ -0,0 +1,23 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoReportID               error = errors.New("no report ID provided")
+	ErrNoReportWithID           error = errors.New("no report found with given ID")
+	ErrNoReportContentID        error = errors.New("no reported content ID provided")
+	ErrInvalidReportContentType error = errors.New("invalid reported content type provided (valid: link, summary, tag)")
+	ErrInvalidReportReason      error = errors.New("invalid report reason provided (valid: spam, broken, nsfw, abusive)")
+	ErrInvalidReportStatus      error = errors.New("invalid report status provided (valid: open, resolved, hidden, dismissed)")
+	ErrInvalidReportAction      error = errors.New("invalid report action provided (valid: resolve, hide, dismiss)")
+	ErrNoReportedContentWithID  error = errors.New("no content of given type found with given ID")
+	ErrAlreadyReported          error = errors.New("you have already reported this")
+	ErrReportAlreadyClosed      error = errors.New("report has already been resolved, hidden or dismissed")
+)
+
+func ReportDetailsLengthExceedsLimit(limit int) error {
+	return fmt.Errorf("report details too long (max %d chars)", limit)
+}
@@ -26,3 +26,8 @@ func CatCharsExceedLimit(limit int) error {
 func NumCatsExceedsLimit(limit int) error {
 	return fmt.Errorf("too many tag cats (%d max)", limit)
 }
+
+// pos is the 1-based character position in the cats query
+func InvalidCatQuery(pos int, reason string) error {
+	return fmt.Errorf("invalid cats query at position %d: %s", pos, reason)
+}
@@ -21,6 +21,9 @@ var (
 	ErrNoTmapOwnerLoginName     error = errors.New("no login name provided for Treasure Map owner")
 	ErrInvalidSectionParams     error = errors.New("invalid section params provided")
 	ErrInvalidOnlySectionParams error = errors.New("invalid params provided for single Treasure Map section")
+
+	// Export
+	ErrInvalidTmapExportFormat error = errors.New("invalid export format provided (valid: netscape, json, csv)")
 )
 
 func ProfileAboutLengthExceedsLimit(limit int) error {
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+)
+
+func GetCatGraph(w http.ResponseWriter, r *http.Request) {
+	opts, err := util.GetCatGraphOptionsFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	graph, err := util.GetCatGraph(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, graph)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+func GetCatPage(w http.ResponseWriter, r *http.Request) {
+	cat := strings.ToLower(strings.TrimSpace(chi.URLParam(r, "cat")))
+	if err := util.ValidateCatPageCat(cat); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	sort_by, err := util.GetCatPageSortByFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+
+	var resp any
+	if req_user_id != "" {
+		resp, err = util.GetCatPage[model.LinkSignedIn](cat, req_user_id, sort_by)
+	} else {
+		resp, err = util.GetCatPage[model.Link](cat, req_user_id, sort_by)
+	}
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+func GetCatDescriptionRevisions(w http.ResponseWriter, r *http.Request) {
+	cat := strings.ToLower(strings.TrimSpace(chi.URLParam(r, "cat")))
+	if err := util.ValidateCatPageCat(cat); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	page, err := util.GetCatDescriptionRevisionsPage(cat, &model.CatDescriptionRevisionsOptions{
+		Page: r.Context().Value(m.PageKey).(uint),
+	})
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
+
+func EditCatDescription(w http.ResponseWriter, r *http.Request) {
+	cat := strings.ToLower(strings.TrimSpace(chi.URLParam(r, "cat")))
+	if err := util.ValidateCatPageCat(cat); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	request := &model.EditCatDescriptionRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if err := util.EditCatDescription(cat, request, req_login_name); err == e.ErrCatDescriptionUnchanged {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, request)
+}
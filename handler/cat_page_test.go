@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	m "github.com/julianlk522/modeep/middleware"
+)
+
+func TestGetCatPage(t *testing.T) {
+	var test_requests = []struct {
+		Cat                string
+		UserID             string
+		ExpectedStatusCode int
+	}{
+		{"", "", http.StatusBadRequest},
+		{"umvc3,flowers", "", http.StatusBadRequest},
+		{strings.Repeat("a", 31), "", http.StatusBadRequest},
+		{"umvc3", "", http.StatusOK},
+		{"Flowers", TEST_USER_ID, http.StatusOK},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(http.MethodGet, "/cat/"+tr.Cat, nil)
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    tr.UserID,
+			"login_name": "",
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("cat", tr.Cat)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		GetCatPage(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
+
+func TestEditCatDescription(t *testing.T) {
+	defer TestClient.Exec(`DELETE FROM "Cat Description Revisions" WHERE cat = 'catpagehandlertest';`)
+
+	var test_requests = []struct {
+		Cat                string
+		Payload            map[string]string
+		ExpectedStatusCode int
+	}{
+		{"catpagehandlertests", map[string]string{"text": ""}, http.StatusBadRequest},
+		{"catpagehandlertests", map[string]string{"text": strings.Repeat("a", 2001)}, http.StatusBadRequest},
+		{"catpagehandlertests,umvc3", map[string]string{"text": "hello"}, http.StatusBadRequest},
+		{"catpagehandlertests", map[string]string{"text": "hello"}, http.StatusOK},
+		// unchanged, including via singular variant
+		{"catpagehandlertest", map[string]string{"text": "hello "}, http.StatusBadRequest},
+		{"catpagehandlertest", map[string]string{"text": "hello again"}, http.StatusOK},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(tr.Payload)
+		r := httptest.NewRequest(http.MethodPut, "/cat/"+tr.Cat+"/description", bytes.NewReader(pl))
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("cat", tr.Cat)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		EditCatDescription(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
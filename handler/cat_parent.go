@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+// Admin only: sets or replaces a cat's parent
+func SetCatParent(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	request := &model.SetCatParentRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	if err := util.ValidateCatParent(request.Cat, request.Parent); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := util.SetCatParent(request, req_login_name); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Admin only: makes a cat a root
+func RemoveCatParent(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	if err := util.RemoveCatParent(chi.URLParam(r, "cat")); err == e.ErrNoCatParent {
+		render.Render(w, r, e.ErrNotFound(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusResetContent)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+// Approved synonyms only
+func GetCatSynonyms(w http.ResponseWriter, r *http.Request) {
+	page, err := util.GetCatSynonymsPage(&model.CatSynonymsOptions{
+		Status: model.CatSynonymApproved,
+		Page:   r.Context().Value(m.PageKey).(uint),
+	})
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
+
+func AddCatSynonym(w http.ResponseWriter, r *http.Request) {
+	request := &model.NewCatSynonymRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	if err := util.ValidateCatSynonym(request.Cat, request.SynonymOf); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if err := util.CreateCatSynonym(request, req_login_name); err == e.ErrCatSynonymAlreadyProposed {
+		render.Render(w, r, e.ErrConflict(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Admin only
+func GetProposedCatSynonyms(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	opts := &model.CatSynonymsOptions{
+		Status: model.CatSynonymStatus(r.URL.Query().Get("status")),
+		Page:   r.Context().Value(m.PageKey).(uint),
+	}
+	if opts.Status != "" && !slices.Contains(model.ValidCatSynonymStatuses[:], opts.Status) {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrInvalidCatSynonymStatus))
+		return
+	}
+
+	page, err := util.GetCatSynonymsPage(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
+
+// Admin only: approves or rejects a pending synonym
+func ReviewCatSynonym(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	request := &model.ReviewCatSynonymRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	synonym_id := chi.URLParam(r, "synonym_id")
+	if synonym_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoCatSynonymID))
+		return
+	}
+	synonym, err := util.GetCatSynonym(synonym_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCatSynonymWithID))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	// (synonyms approved since this one was proposed may conflict with it)
+	switch err := util.ReviewCatSynonym(synonym, request.Action, req_login_name); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case e.ErrCatSynonymAlreadyReviewed,
+		e.ErrCatsAlreadyMerged,
+		e.ErrCatAlreadyHasSynonym,
+		e.ErrCatHasSynonyms,
+		e.ErrSynonymOfIsAlreadySynonym:
+		render.Render(w, r, e.ErrConflict(err))
+	default:
+		render.Render(w, r, e.ErrInternalServerError(err))
+	}
+}
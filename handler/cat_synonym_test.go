@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+)
+
+func TestAddCatSynonym(t *testing.T) {
+	defer TestClient.Exec(`DELETE FROM "Cat Synonyms" WHERE proposed_by = ?;`, TEST_LOGIN_NAME)
+
+	var test_requests = []struct {
+		Payload            map[string]string
+		ExpectedStatusCode int
+	}{
+		{map[string]string{"cat": "", "synonym_of": "javascript"}, http.StatusBadRequest},
+		{map[string]string{"cat": "js,es6", "synonym_of": "javascript"}, http.StatusBadRequest},
+		{map[string]string{"cat": "Flower", "synonym_of": "flowers"}, http.StatusBadRequest},
+		{map[string]string{"cat": "JS", "synonym_of": "javascript"}, http.StatusCreated},
+		// already proposed
+		{map[string]string{"cat": "js", "synonym_of": "javascript"}, http.StatusConflict},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(tr.Payload)
+		r := httptest.NewRequest(http.MethodPost, "/synonyms", bytes.NewReader(pl))
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		r = r.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		AddCatSynonym(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			text, _ := io.ReadAll(res.Body)
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)\n%s",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr.Payload,
+				text,
+			)
+		}
+	}
+}
+
+func TestGetProposedCatSynonyms(t *testing.T) {
+	original_admin_login_names := util.Admin_login_names
+	defer func() { util.Admin_login_names = original_admin_login_names }()
+	util.Admin_login_names = []string{TEST_LOGIN_NAME}
+
+	var test_requests = []struct {
+		LoginName          string
+		Params             string
+		ExpectedStatusCode int
+	}{
+		{TEST_LOGIN_NAME, "", http.StatusOK},
+		{TEST_LOGIN_NAME, "?status=rejected", http.StatusOK},
+		{TEST_LOGIN_NAME, "?status=open", http.StatusBadRequest},
+		{"not_an_admin", "", http.StatusForbidden},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(http.MethodGet, "/admin/synonyms"+tr.Params, nil)
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"login_name": tr.LoginName,
+		})
+		ctx = context.WithValue(ctx, m.PageKey, uint(1))
+		r = r.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		GetProposedCatSynonyms(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			text, _ := io.ReadAll(res.Body)
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)\n%s",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+				text,
+			)
+		}
+	}
+}
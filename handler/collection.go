@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func GetCollection(w http.ResponseWriter, r *http.Request) {
+	collection_id := chi.URLParam(r, "collection_id")
+	if collection_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoCollectionID))
+		return
+	}
+
+	collection, err := util.GetCollection(collection_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCollectionWithID))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	// Private collections are indistinguishable from nonexistent ones
+	// to anyone but their owner
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserCanViewCollection(req_login_name, collection) {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCollectionWithID))
+		return
+	}
+
+	links_sql := query.NewCollectionLinks(collection_id)
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+	if req_user_id != "" {
+		links, err := util.ScanCollectionLinks[model.CollectionLinkSignedIn](
+			links_sql.AsSignedInUser(req_user_id),
+		)
+		if err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+
+		render.JSON(w, r, model.CollectionPage[model.CollectionLinkSignedIn]{
+			Collection: collection,
+			Links:      links,
+		})
+	} else {
+		links, err := util.ScanCollectionLinks[model.CollectionLink](links_sql)
+		if err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+
+		render.JSON(w, r, model.CollectionPage[model.CollectionLink]{
+			Collection: collection,
+			Links:      links,
+		})
+	}
+}
+
+// Including unlisted and private collections
+func GetMyCollections(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	collections, err := util.GetCollectionsCreatedByUser(req_login_name, true)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, collections)
+}
+
+func AddCollection(w http.ResponseWriter, r *http.Request) {
+	request := &model.NewCollectionRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if err := util.CreateCollection(request, req_login_name); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	collection, err := util.GetCollection(request.ID)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, collection)
+}
+
+func EditCollection(w http.ResponseWriter, r *http.Request) {
+	request := &model.EditCollectionRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	if err := util.EditCollection(collection.ID, request); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	collection, err := util.GetCollection(collection.ID)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, collection)
+}
+
+func DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	if err := util.DeleteCollection(collection.ID); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusResetContent)
+}
+
+func AddCollectionLink(w http.ResponseWriter, r *http.Request) {
+	request := &model.AddCollectionLinkRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	if collection.LinkCount >= util.MAX_COLLECTION_LINKS {
+		render.Render(w, r, e.ErrUnprocessable(e.ErrTooManyCollectionLinks(util.MAX_COLLECTION_LINKS)))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(request.LinkID, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
+		return
+	}
+	// (collections only show public links, since they can be shared)
+	link_is_public, err := util.LinkIsPublic(request.LinkID)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_public {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrCannotCollectPrivateLink))
+		return
+	}
+
+	if err := util.AddLinkToCollection(collection.ID, request); err == e.ErrLinkAlreadyInCollection {
+		render.Render(w, r, e.ErrConflict(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func EditCollectionLink(w http.ResponseWriter, r *http.Request) {
+	request := &model.EditCollectionLinkRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	link_id := chi.URLParam(r, "link_id")
+	if err := util.EditCollectionLinkNote(collection.ID, link_id, request.Note); err == e.ErrLinkNotInCollection {
+		render.Render(w, r, e.ErrNotFound(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func DeleteCollectionLink(w http.ResponseWriter, r *http.Request) {
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	link_id := chi.URLParam(r, "link_id")
+	if err := util.RemoveLinkFromCollection(collection.ID, link_id); err == e.ErrLinkNotInCollection {
+		render.Render(w, r, e.ErrNotFound(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusResetContent)
+}
+
+func ReorderCollection(w http.ResponseWriter, r *http.Request) {
+	request := &model.ReorderCollectionRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	collection := getOwnCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	if err := util.ReorderCollectionLinks(collection.ID, request.LinkIDs); err == e.ErrInvalidCollectionOrder {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Renders an error and returns nil unless the requesting user owns
+// the collection in the URL
+func getOwnCollection(w http.ResponseWriter, r *http.Request) *model.Collection {
+	collection_id := chi.URLParam(r, "collection_id")
+	if collection_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoCollectionID))
+		return nil
+	}
+
+	collection, err := util.GetCollection(collection_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCollectionWithID))
+		return nil
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return nil
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserCanViewCollection(req_login_name, collection) {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCollectionWithID))
+		return nil
+	} else if collection.CreatedBy != req_login_name {
+		render.Render(w, r, e.ErrForbidden(e.ErrDoesntOwnCollection))
+		return nil
+	}
+
+	return collection
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/julianlk522/modeep/db"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestAddCollection(t *testing.T) {
+	var test_requests = []struct {
+		Payload            map[string]string
+		ExpectedStatusCode int
+	}{
+		{map[string]string{"title": ""}, http.StatusBadRequest},
+		{map[string]string{"title": "  "}, http.StatusBadRequest},
+		{map[string]string{"title": "Reading list", "visibility": "friends"}, http.StatusBadRequest},
+		{map[string]string{"title": "Reading list"}, http.StatusCreated},
+		{map[string]string{"title": "Reading list", "visibility": "private"}, http.StatusCreated},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(tr.Payload)
+		r := httptest.NewRequest(http.MethodPost, "/collections", bytes.NewReader(pl))
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		AddCollection(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr.Payload,
+			)
+		}
+		if res.StatusCode == http.StatusCreated {
+			var c model.Collection
+			if err := json.NewDecoder(res.Body).Decode(&c); err != nil {
+				t.Fatal(err)
+			}
+			defer util.DeleteCollection(c.ID)
+		}
+	}
+}
+
+func TestGetCollection(t *testing.T) {
+	for _, visibility := range model.ValidCollectionVisibilities {
+		if err := util.CreateCollection(&model.NewCollectionRequest{
+			ID:         "collection-test-" + string(visibility),
+			Title:      string(visibility),
+			Visibility: visibility,
+			CreatedAt:  "2025-01-01 00:00:00",
+		}, TEST_LOGIN_NAME); err != nil {
+			t.Fatal(err)
+		}
+		defer util.DeleteCollection("collection-test-" + string(visibility))
+	}
+
+	var test_requests = []struct {
+		CollectionID       string
+		ReqLoginName       string
+		ExpectedStatusCode int
+	}{
+		{"collection-test-public", "", http.StatusOK},
+		{"collection-test-unlisted", "", http.StatusOK},
+		{"collection-test-private", "", http.StatusNotFound},
+		{"collection-test-private", "bradley", http.StatusNotFound},
+		{"collection-test-private", TEST_LOGIN_NAME, http.StatusOK},
+		{"collection-test-nonexistent", TEST_LOGIN_NAME, http.StatusNotFound},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(http.MethodGet, "/collections/"+tr.CollectionID, nil)
+		var req_user_id string
+		if tr.ReqLoginName == TEST_LOGIN_NAME {
+			req_user_id = TEST_USER_ID
+		}
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    req_user_id,
+			"login_name": tr.ReqLoginName,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("collection_id", tr.CollectionID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		GetCollection(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
+
+func TestAddCollectionLink(t *testing.T) {
+	const test_collection_id = "collection-link-test"
+	if err := util.CreateCollection(&model.NewCollectionRequest{
+		ID:         test_collection_id,
+		Title:      "Collection link test",
+		Visibility: model.CollectionPublic,
+		CreatedAt:  "2025-01-01 00:00:00",
+	}, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	defer util.DeleteCollection(test_collection_id)
+
+	for _, visibility := range []model.LinkVisibility{model.LinkPublic, model.LinkPrivate} {
+		if _, err := db.Client.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary, visibility)
+			VALUES (?, ?, ?, '2025-01-01', 'test', '', ?);`,
+			"collection-link-test-"+string(visibility),
+			"https://collection-link-test-"+string(visibility)+".com",
+			TEST_LOGIN_NAME,
+			visibility,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer db.Client.Exec("DELETE FROM Links WHERE id = ?;", "collection-link-test-"+string(visibility))
+	}
+
+	// Private links aren't shown in collections, even the owner's
+	var test_requests = []struct {
+		LinkID             string
+		ExpectedStatusCode int
+	}{
+		{"collection-link-test-private", http.StatusBadRequest},
+		{"collection-link-test-public", http.StatusCreated},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(map[string]string{"link_id": tr.LinkID})
+		r := httptest.NewRequest(
+			http.MethodPost,
+			"/collections/"+test_collection_id+"/links",
+			bytes.NewReader(pl),
+		)
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("collection_id", test_collection_id)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		AddCollectionLink(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+
+	collection, err := util.GetCollection(test_collection_id)
+	if err != nil {
+		t.Fatal(err)
+	} else if collection.LinkCount != 1 {
+		t.Fatalf("expected link count 1, got %d", collection.LinkCount)
+	}
+}
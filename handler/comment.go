@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+func GetComments(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
+		return
+	}
+
+	page, err := util.GetCommentsPage(&model.CommentsOptions{
+		LinkID: link_id,
+		Page:   r.Context().Value(m.PageKey).(uint),
+	})
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
+
+func AddComment(w http.ResponseWriter, r *http.Request) {
+	request := &model.NewCommentRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	request.LinkID = chi.URLParam(r, "link_id")
+	if request.LinkID == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(request.LinkID, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
+		return
+	}
+
+	if request.ParentID != "" {
+		if err := util.ValidateCommentParent(request.LinkID, request.ParentID); err == e.ErrParentCommentNotFound || err == e.ErrCannotReplyToReply {
+			render.Render(w, r, e.ErrInvalidRequest(err))
+			return
+		} else if err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+	}
+
+	if err := util.CreateComment(request, req_login_name); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, model.Comment{
+		ID:          request.ID,
+		SubmittedBy: req_login_name,
+		Text:        request.Text,
+		SubmitDate:  request.SubmitDate,
+		LastUpdated: request.SubmitDate,
+	})
+}
+
+func EditComment(w http.ResponseWriter, r *http.Request) {
+	request := &model.EditCommentRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	comment_id := getOwnCommentID(w, r)
+	if comment_id == "" {
+		return
+	}
+
+	if err := util.EditComment(comment_id, request.Text); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func DeleteComment(w http.ResponseWriter, r *http.Request) {
+	comment_id := getOwnCommentID(w, r)
+	if comment_id == "" {
+		return
+	}
+
+	if err := util.DeleteComment(comment_id); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusResetContent)
+}
+
+// Renders an error and returns "" unless the requesting user submitted
+// the (undeleted) comment in the URL
+func getOwnCommentID(w http.ResponseWriter, r *http.Request) string {
+	comment_id := chi.URLParam(r, "comment_id")
+	if comment_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoCommentID))
+		return ""
+	}
+
+	submitted_by, err := util.GetCommentSubmitter(comment_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoCommentWithID))
+		return ""
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return ""
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if submitted_by != req_login_name {
+		render.Render(w, r, e.ErrForbidden(e.ErrDoesntOwnComment))
+		return ""
+	}
+
+	return comment_id
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestAddComment(t *testing.T) {
+	if err := util.CreateComment(&model.NewCommentRequest{
+		ID:         "comment-handler-test",
+		LinkID:     "1",
+		Text:       "top-level",
+		SubmitDate: "2025-01-01 00:00:00",
+	}, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Comments WHERE link_id IN ('1', '2');")
+
+	var test_requests = []struct {
+		LinkID             string
+		Payload            map[string]string
+		ExpectedStatusCode int
+	}{
+		{"1", map[string]string{"text": ""}, http.StatusBadRequest},
+		{"1", map[string]string{"text": "  "}, http.StatusBadRequest},
+		{"1", map[string]string{"text": strings.Repeat("a", 2001)}, http.StatusBadRequest},
+		{"-1", map[string]string{"text": "hello"}, http.StatusBadRequest},
+		// parent on another link
+		{"2", map[string]string{"text": "hello", "parent_id": "comment-handler-test"}, http.StatusBadRequest},
+		{"1", map[string]string{"text": "hello"}, http.StatusCreated},
+		{"1", map[string]string{"text": "hello", "parent_id": "comment-handler-test"}, http.StatusCreated},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(tr.Payload)
+		r := httptest.NewRequest(http.MethodPost, "/links/"+tr.LinkID+"/comments", bytes.NewReader(pl))
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("link_id", tr.LinkID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		AddComment(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
+
+func TestEditComment(t *testing.T) {
+	if err := util.CreateComment(&model.NewCommentRequest{
+		ID:         "comment-edit-test",
+		LinkID:     "1",
+		Text:       "original",
+		SubmitDate: "2025-01-01 00:00:00",
+	}, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Comments WHERE id = 'comment-edit-test';")
+
+	var test_requests = []struct {
+		CommentID          string
+		ReqLoginName       string
+		ExpectedStatusCode int
+	}{
+		{"comment-nonexistent", TEST_LOGIN_NAME, http.StatusNotFound},
+		{"comment-edit-test", "bradley", http.StatusForbidden},
+		{"comment-edit-test", TEST_LOGIN_NAME, http.StatusOK},
+	}
+
+	for _, tr := range test_requests {
+		pl, _ := json.Marshal(map[string]string{"text": "edited"})
+		r := httptest.NewRequest(http.MethodPut, "/comments/"+tr.CommentID, bytes.NewReader(pl))
+		r.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    "",
+			"login_name": tr.ReqLoginName,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("comment_id", tr.CommentID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		EditComment(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+func GetTopDomains(w http.ResponseWriter, r *http.Request) {
+	opts, err := util.GetTopDomainsOptionsFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	domains_sql, err := query.
+		NewTopDomains().
+		FromOptions(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+	domains, err := util.ScanDomainCounts(domains_sql)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, domains)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	"github.com/julianlk522/modeep/model"
+)
+
+func GetTopLinksAtomFeed(w http.ResponseWriter, r *http.Request) {
+	getTopLinksFeed(w, r, model.FeedFormatAtom)
+}
+
+func GetTopLinksRSSFeed(w http.ResponseWriter, r *http.Request) {
+	getTopLinksFeed(w, r, model.FeedFormatRSS)
+}
+
+func GetTreasureMapAtomFeed(w http.ResponseWriter, r *http.Request) {
+	getTreasureMapFeed(w, r, model.FeedFormatAtom)
+}
+
+func GetTreasureMapRSSFeed(w http.ResponseWriter, r *http.Request) {
+	getTreasureMapFeed(w, r, model.FeedFormatRSS)
+}
+
+func getTopLinksFeed(w http.ResponseWriter, r *http.Request, format model.FeedFormat) {
+	feed, err := util.GetTopLinksFeed(r.URL.Query(), getFeedSelfLink(r))
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	writeFeed(w, feed, format)
+}
+
+func getTreasureMapFeed(w http.ResponseWriter, r *http.Request, format model.FeedFormat) {
+	var login_name string = chi.URLParam(r, "login_name")
+	if login_name == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLoginName))
+		return
+	}
+
+	user_exists, err := util.UserExists(login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	} else if !user_exists {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoUserWithLoginName))
+		return
+	}
+
+	feed, err := util.GetTmapFeed(login_name, r.URL.Query(), getFeedSelfLink(r))
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	writeFeed(w, feed, format)
+}
+
+func getFeedSelfLink(r *http.Request) string {
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+func writeFeed(w http.ResponseWriter, feed *model.Feed, format model.FeedFormat) {
+	switch format {
+	case model.FeedFormatAtom:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	case model.FeedFormatRSS:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	}
+
+	// Headers already sent, so can only log
+	if err := util.WriteFeed(w, feed, format); err != nil {
+		log.Printf("Could not write %s feed: %s", format, err)
+	}
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 
 	"strings"
 
@@ -47,8 +51,7 @@ func GetTopLinks(w http.ResponseWriter, r *http.Request) {
 
 	// Cat filters also go directly to PrepareLinksPage() so it can determine if any
 	// results were merged due to close-spellings of them.
-	cat_filters_params := url_params.Get("cats")
-	cat_filters := strings.Split(cat_filters_params, ",")
+	cat_filters := util.GetCatsFromCatQueryParams(url_params.Get("cats"))
 	page_opts := &model.LinksPageOptions{CatFilters: cat_filters}
 
 	var resp any
@@ -64,6 +67,89 @@ func GetTopLinks(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, resp)
 }
 
+func GetRelatedLinks(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+	opts, err := util.GetRelatedLinksOptionsFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
+		return
+	}
+	// for its cats and times starred
+	link, err := util.ScanSingleLink[model.Link](query.NewSingleLink(link_id))
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+	if req_user_id != "" {
+		opts.AsSignedInUser = req_user_id
+	}
+	related_sql, err := query.
+		NewRelatedLinks(link_id, strings.Split(link.Cats, ",")).
+		FromOptions(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	var resp any
+	if req_user_id != "" {
+		resp, err = util.ScanRelatedLinks[model.LinkSignedIn](related_sql, link)
+	} else {
+		resp, err = util.ScanRelatedLinks[model.Link](related_sql, link)
+	}
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
+func GetRecommendedLinks(w http.ResponseWriter, r *http.Request) {
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+
+	profile, err := util.GetCatAffinityProfile(query.NewCatAffinitySources(req_user_id, req_login_name))
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+	// nothing starred, tagged or clicked yet
+	if len(profile) == 0 {
+		render.JSON(w, r, []model.RecommendedLink{})
+		return
+	}
+
+	recommended_sql := query.NewRecommendedLinks(
+		req_user_id,
+		req_login_name,
+		util.GetTopAffinityCats(profile),
+	)
+	recommended_links, err := util.ScanRecommendedLinks(recommended_sql, profile)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, recommended_links)
+}
+
 func GetPreviewImg(w http.ResponseWriter, r *http.Request) {
 	var file_name string = chi.URLParam(r, "file_name")
 	path := util.Preview_img_dir + "/" + file_name
@@ -76,6 +162,51 @@ func GetPreviewImg(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, path)
 }
 
+func GetArchiveSnapshot(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoArchiveSnapshot))
+		return
+	}
+
+	snapshot, err := util.GetLatestArchiveSnapshot(link_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoArchiveSnapshot))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	path := util.Archive_dir + "/" + snapshot.Hash
+	if _, err := os.Stat(path); err != nil {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoArchiveSnapshot))
+		return
+	}
+
+	// Snapshots are untrusted third-party HTML served from our origin:
+	// sandbox them so any scripts inside can't act as us
+	w.Header().Set("Content-Type", snapshot.ContentType)
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Archived-At", snapshot.ArchivedAt)
+	if snapshot.Truncated {
+		w.Header().Set("X-Archive-Truncated", "true")
+	}
+
+	http.ServeFile(w, r, path)
+}
+
 func AddLink(w http.ResponseWriter, r *http.Request) {
 	request := &model.NewLinkRequest{}
 	if err := render.Bind(r, request); err != nil {
@@ -96,167 +227,165 @@ func AddLink(w http.ResponseWriter, r *http.Request) {
 		SubmittedBy:    req_login_name,
 		NewLinkRequest: &model.NewLinkRequest{},
 	}
-	var final_url string
-
-	// Check for YT video:
-	// In that case test request needs to use API key
-	if util.IsYTVideo(request.URL) {
-		if yt_md, err := util.GetYTVideoMetadata(request.URL); err == nil {
-			final_url = "https://www.youtube.com/watch?v=" + yt_md.ID
-			new_link.AutoSummary = yt_md.Items[0].Snippet.Title
-			new_link.PreviewImgURL = yt_md.Items[0].Snippet.Thumbnails.Default.URL
-		}
-	} else {
-		// Test URL response
-		var resp *http.Response
-		resp, err := util.GetResolvedURLResponse(request.URL)
-		if err != nil {
-			render.Render(w, r, e.ErrUnprocessable(err))
-			return
-		}
-		defer resp.Body.Close()
-		// Save adjusted URL (after any redirects e.g., to wwww.)...
-		url_after_redirects := resp.Request.URL.String()
-
-		// ...unless modified due to 302/401/403/429 etc. redirect
-		is_unauthorized := resp.StatusCode == http.StatusUnauthorized
-		is_forbidden := resp.StatusCode == http.StatusForbidden
-		is_too_many_requests := resp.StatusCode == http.StatusTooManyRequests
-		is_302_redirect := resp.StatusCode == http.StatusFound
-		is_google_sorry_page := strings.Contains(url_after_redirects, "google.com/sorry")
-
-		if is_unauthorized || is_forbidden || is_too_many_requests || is_302_redirect || is_google_sorry_page {
-			final_url = strings.TrimSuffix(request.URL, "/")
-		} else {
-			final_url = strings.TrimSuffix(url_after_redirects, "/")
-		}
-
-		// Get metadata for non-YT links
-		if x_md := util.GetLinkExtraMetadataFromResponse(resp); x_md != nil {
-			if x_md.AutoSummary != "" {
-				new_link.AutoSummary = x_md.AutoSummary
-			}
-			if x_md.PreviewImgURL != "" {
-				new_link.PreviewImgURL = x_md.PreviewImgURL
-			}
-		}
+	final_url, x_md, err := util.GetFinalURLAndExtraMetadata(request.URL)
+	if err != nil {
+		render.Render(w, r, e.ErrUnprocessable(err))
+		return
 	}
 
-	if is_duplicate, link_id := util.LinkAlreadyAdded(final_url); is_duplicate {
+	link_id, is_duplicate, err := util.GetDuplicateLinkID(final_url, x_md, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if is_duplicate {
+		duplicate_err := e.ErrDuplicateLink(final_url, link_id)
+		if link_id == "" {
+			duplicate_err = e.ErrDuplicateLinkNotVisible(final_url)
+		}
 		render.Status(r, http.StatusConflict)
-		render.Render(w, r, e.ErrConflict(
-			e.ErrDuplicateLink(final_url, link_id),
-		))
+		render.Render(w, r, e.ErrConflict(duplicate_err))
 		return
 	}
-
-	// Verified: add link
-	tx, err := db.Client.Begin()
+	canonical_key, err := util.GetNewLinkCanonicalKey(final_url, x_md)
 	if err != nil {
-		render.Render(w, r, e.ErrInternalServerError(err))
+		render.Render(w, r, e.ErrUnprocessable(err))
 		return
 	}
-	defer tx.Rollback()
 
+	// Verified: add link
 	new_link.LinkID = request.LinkID
 	new_link.SubmitDate = request.SubmitDate
+	new_link.URL = final_url
+	new_link.Cats = request.Cats
+	new_link.Summary = request.Summary
+	new_link.Visibility = request.Visibility
 
-	// Insert auto summary
-	if new_link.AutoSummary != "" {
-		if _, err := tx.Exec(
-			"INSERT INTO Summaries VALUES(?,?,?,?,?);",
-			uuid.New().String(),
-			new_link.AutoSummary,
-			new_link.LinkID,
-			db.AUTO_SUMMARY_USER_ID,
-			new_link.SubmitDate,
-		); err != nil {
-			log.Print("Error adding auto summary: ", err)
-		} else {
-			new_link.SummaryCount = 1
-		}
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+	if err = util.SaveNewLink(new_link, x_md, canonical_key, req_user_id); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
 	}
 
-	// Insert summary
-	new_link.Summary = request.Summary
-	if new_link.Summary != "" {
-		req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
-		if _, err := tx.Exec(
-			"INSERT INTO Summaries VALUES(?,?,?,?,?);",
-			uuid.New().String(),
-			new_link.Summary,
-			new_link.LinkID,
-			req_user_id,
-			new_link.SubmitDate,
-		); err != nil {
-			render.Render(w, r, e.ErrInternalServerError(err))
-			return
-		} else {
-			new_link.SummaryCount += 1
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, new_link)
+}
+
+// Accepts a multipart form with a bookmarks export "file" plus optional
+// "format" (detected from content if omitted) and "cats" (used for
+// bookmarks without any folders/tags; default IMPORT_FALLBACK_CAT).
+// Responds 202 with the queued job; poll GetImportJob for results.
+func ImportLinks(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if has_active_job, err := util.UserHasActiveImportJob(req_login_name); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if has_active_job {
+		render.Status(r, http.StatusConflict)
+		render.Render(w, r, e.ErrConflict(e.ErrImportAlreadyInProgress))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, util.MAX_IMPORT_FILE_BYTES+(1<<20))
+	if err := r.ParseMultipartForm(util.MAX_IMPORT_FILE_BYTES); err != nil {
+		var max_bytes_err *http.MaxBytesError
+		if errors.As(err, &max_bytes_err) {
+			err = e.ErrImportFileTooLarge(util.MAX_IMPORT_FILE_BYTES)
 		}
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
 	}
 
-	// Insert tag
-	new_link.Cats = util.TidyCats(request.Cats)
-	if _, err = tx.Exec(
-		"INSERT INTO Tags VALUES(?,?,?,?,?);",
-		uuid.New().String(),
-		new_link.LinkID,
-		new_link.Cats,
-		new_link.SubmittedBy,
-		new_link.SubmitDate,
-	); err != nil {
-		render.Render(w, r, e.ErrInternalServerError(err))
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoImportFile))
 		return
 	}
+	defer file.Close()
 
-	// Insert link
-	new_link.URL = final_url
+	content, err := io.ReadAll(file)
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
 
-	if new_link.Summary == "" && new_link.AutoSummary != "" {
-		new_link.Summary = new_link.AutoSummary
+	format := model.ImportFormat(r.FormValue("format"))
+	if format == "" {
+		if format, err = util.DetectImportFormat(content); err != nil {
+			render.Render(w, r, e.ErrInvalidRequest(err))
+			return
+		}
+	} else if !slices.Contains(model.ValidImportFormats[:], format) {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrInvalidImportFormat))
+		return
 	}
 
-	if new_link.PreviewImgURL != "" {
-		new_link.PreviewImgFilename, err = util.SavePreviewImgAndGetFileName(
-			new_link.PreviewImgURL,
-			new_link.LinkID,
-		)
-		if err != nil {
-			// skip - link won't have a preview image
+	bookmarks, err := util.ParseBookmarks(content, format)
+	if err != nil {
+		render.Render(w, r, e.ErrUnprocessable(err))
+		return
+	} else if len(bookmarks) == 0 {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoBookmarksFound))
+		return
+	} else if len(bookmarks) > util.MAX_IMPORT_ITEMS {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrTooManyImportItems(util.MAX_IMPORT_ITEMS)))
+		return
+	}
+
+	fallback_cats := r.FormValue("cats")
+	if fallback_cats == "" {
+		fallback_cats = util.IMPORT_FALLBACK_CAT
+	}
+	for i := range bookmarks {
+		if bookmarks[i].Cats == "" {
+			bookmarks[i].Cats = fallback_cats
 		}
 	}
 
-	if _, err = tx.Exec(
-		"INSERT INTO Links VALUES(?,?,?,?,?,?,?);",
-		new_link.LinkID,
-		new_link.URL,
-		new_link.SubmittedBy,
-		new_link.SubmitDate,
-		new_link.Cats,
-		new_link.Summary,
-		new_link.PreviewImgFilename,
-	); err != nil {
+	// (checked again when creating the job, in case of concurrent uploads)
+	job_id, err := util.CreateImportJob(req_login_name, format, bookmarks)
+	if err == e.ErrImportAlreadyInProgress {
+		render.Status(r, http.StatusConflict)
+		render.Render(w, r, e.ErrConflict(err))
+		return
+	} else if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
+	go util.RunImportJob(job_id)
 
-	// Increment spellfix ranks
-	if err = util.IncrementSpellfixRanksForCats(
-		tx,
-		strings.Split(request.Cats, ","),
-	); err != nil {
+	job, err := util.GetImportJob(job_id)
+	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
-	if err = tx.Commit(); err != nil {
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, job)
+}
+
+func GetImportJob(w http.ResponseWriter, r *http.Request) {
+	job_id := chi.URLParam(r, "job_id")
+	if job_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoImportJobID))
+		return
+	}
+
+	job, err := util.GetImportJob(job_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoImportJobWithID))
+		return
+	} else if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
-	render.Status(r, http.StatusCreated)
-	render.JSON(w, r, new_link)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if job.SubmittedBy != req_login_name {
+		render.Render(w, r, e.ErrForbidden(e.ErrCannotViewUnownedImportJob))
+		return
+	}
+
+	render.JSON(w, r, job)
 }
 
 func DeleteLink(w http.ResponseWriter, r *http.Request) {
@@ -281,20 +410,33 @@ func DeleteLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch global cats and preview image file before deleting
+	// Fetch global cats, visibility and preview image file before deleting
 	// so spellfix ranks can be updated and preview image can be deleted
 	var gc, pi string
+	var is_public bool
 	if err = db.Client.QueryRow(
-		"SELECT global_cats, COALESCE(img_file, '') FROM Links WHERE id = ?;",
+		`SELECT
+			global_cats,
+			COALESCE(img_file, ''),
+			id IN (SELECT id FROM "Public Links")
+		FROM Links
+		WHERE id = ?;`,
 		request.LinkID,
 	).Scan(
 		&gc,
 		&pi,
+		&is_public,
 	); err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
+	archive_hashes, err := util.GetArchiveSnapshotHashesForLink(request.LinkID)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
 	tx, err := db.Client.Begin()
 	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
@@ -303,26 +445,120 @@ func DeleteLink(w http.ResponseWriter, r *http.Request) {
 	defer tx.Rollback()
 
 	if _, err = tx.Exec(
-		"DELETE FROM Links WHERE id = ?;",
+		`DELETE FROM "Archive Snapshots" WHERE link_id = ?;`,
 		request.LinkID,
 	); err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
-	if err = util.DecrementSpellfixRanksForCats(
-		tx,
-		strings.Split(gc, ","),
+	if _, err = tx.Exec(
+		"DELETE FROM page_content_fts WHERE link_id = ?;",
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		`DELETE FROM "Collection Items" WHERE link_id = ?;`,
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		"DELETE FROM Comments WHERE link_id = ?;",
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		`DELETE FROM "Link Global Cats" WHERE link_id = ?;`,
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		`DELETE FROM "Link Global Cat Pairs" WHERE link_id = ?;`,
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		`DELETE FROM "Link Checks" WHERE link_id = ?;`,
+		request.LinkID,
 	); err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
+	if _, err = tx.Exec(
+		"DELETE FROM Clicks WHERE link_id = ?;",
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	// (reports and hidden state for the link and its summaries and tags)
+	for _, table := range []string{"Reports", `"Hidden Content"`} {
+		if _, err = tx.Exec(
+			`DELETE FROM `+table+`
+			WHERE (content_type = 'link' AND content_id = ?)
+			OR (content_type = 'summary' AND content_id IN (SELECT id FROM Summaries WHERE link_id = ?))
+			OR (content_type = 'tag' AND content_id IN (SELECT id FROM Tags WHERE link_id = ?));`,
+			request.LinkID,
+			request.LinkID,
+			request.LinkID,
+		); err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+	}
+
+	// (import results keep their item, just no longer point to the link)
+	if _, err = tx.Exec(
+		`UPDATE "Import Job Items" SET link_id = NULL WHERE link_id = ?;`,
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if _, err = tx.Exec(
+		"DELETE FROM Links WHERE id = ?;",
+		request.LinkID,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	// (private and hidden links' cats never counted toward spellfix ranks)
+	if is_public {
+		if err = util.DecrementSpellfixRanksForCats(
+			tx,
+			strings.Split(gc, ","),
+		); err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
 
+	util.DeleteUnreferencedArchiveFiles(archive_hashes)
+
 	// Delete preview image
 	if pi != "" {
 		preview_img_path := util.Preview_img_dir + "/" + pi
@@ -339,6 +575,198 @@ func DeleteLink(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusResetContent)
 }
 
+func EditLinkVisibility(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	request := &model.EditLinkVisibilityRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoLinkWithID))
+		return
+	} else if !util.UserSubmittedLink(req_login_name, link_id) {
+		render.Render(w, r, e.ErrForbidden(e.ErrCannotEditUnownedLinkVisibility))
+		return
+	}
+
+	if err = util.SetLinkVisibility(link_id, request.Visibility); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func RefreshLinkMetadata(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	link_exists, err := util.LinkExists(link_id)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_exists {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoLinkWithID))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserSubmittedLink(req_login_name, link_id) {
+		render.Render(w, r, e.ErrForbidden(e.ErrCannotRefreshUnownedLink))
+		return
+	}
+
+	var url, old_img_file string
+	if err = db.Client.QueryRow(
+		"SELECT url, COALESCE(img_file, '') FROM Links WHERE id = ?;",
+		link_id,
+	).Scan(
+		&url,
+		&old_img_file,
+	); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	_, x_md, err := util.GetFinalURLAndExtraMetadata(url)
+	if err != nil {
+		render.Render(w, r, e.ErrUnprocessable(err))
+		return
+	}
+
+	// Only replace existing metadata with newly-found metadata
+	// (a site that is down now shouldn't wipe out what was captured before)
+	var new_img_file string
+	if x_md.PreviewImgURL != "" {
+		new_img_file, err = util.SavePreviewImgAndGetFileName(
+			x_md.PreviewImgURL,
+			link_id,
+		)
+		if err != nil {
+			// skip - keep old preview image
+			log.Printf("Could not refresh preview image for link %s: %s", link_id, err)
+		}
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+	defer tx.Rollback()
+
+	if x_md.AutoSummary != "" {
+		// Update in place if auto summary exists so any likes are kept
+		res, err := tx.Exec(
+			`UPDATE Summaries
+			SET text = ?, last_updated = ?
+			WHERE link_id = ? AND submitted_by = ?;`,
+			x_md.AutoSummary,
+			mutil.NEW_LONG_TIMESTAMP(),
+			link_id,
+			db.AUTO_SUMMARY_USER_ID,
+		)
+		if err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+
+		if rows_affected, err := res.RowsAffected(); err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		} else if rows_affected == 0 {
+			if _, err = tx.Exec(
+				"INSERT INTO Summaries VALUES(?,?,?,?,?);",
+				uuid.New().String(),
+				x_md.AutoSummary,
+				link_id,
+				db.AUTO_SUMMARY_USER_ID,
+				mutil.NEW_LONG_TIMESTAMP(),
+			); err != nil {
+				render.Render(w, r, e.ErrInternalServerError(err))
+				return
+			}
+		}
+	}
+
+	if err = util.SaveArchiveSnapshot(
+		tx,
+		link_id,
+		x_md.PageContent,
+		x_md.PageContentType,
+		x_md.PageContentTruncated,
+	); err != nil {
+		log.Printf("Could not archive snapshot for link %s: %s", link_id, err)
+	}
+	if err = util.SetPageContentText(
+		tx,
+		link_id,
+		x_md.PageContent,
+		x_md.PageContentType,
+	); err != nil {
+		log.Printf("Could not index page content for link %s: %s", link_id, err)
+	}
+
+	if new_img_file != "" {
+		if _, err = tx.Exec(
+			"UPDATE Links SET img_file = ? WHERE id = ?;",
+			new_img_file,
+			link_id,
+		); err != nil {
+			render.Render(w, r, e.ErrInternalServerError(err))
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if err = util.WriteArchiveSnapshotFile(x_md.PageContent); err != nil {
+		log.Printf("Could not write archive snapshot for link %s: %s", link_id, err)
+	}
+
+	// Delete old preview image if replaced by one with a different file name
+	// (same name means it was overwritten already)
+	if new_img_file != "" && old_img_file != "" && new_img_file != old_img_file {
+		old_img_path := util.Preview_img_dir + "/" + old_img_file
+		if err = os.Remove(old_img_path); err != nil {
+			log.Printf("Could not delete old preview image: %s", err)
+		}
+	}
+
+	if err = util.CalculateAndSetGlobalSummary(link_id); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
+	link_sql := query.NewSingleLink(link_id).AsSignedInUser(req_user_id)
+	link, err := util.ScanSingleLink[model.LinkSignedIn](link_sql)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, link)
+}
+
 // unfortunate name :/
 func StarLink(w http.ResponseWriter, r *http.Request) {
 	request := &model.StarLinkRequest{}
@@ -353,11 +781,12 @@ func StarLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	link_exists, err := util.LinkExists(link_id)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
-	} else if !link_exists {
+	} else if !link_is_visible {
 		render.Render(w, r, e.ErrUnprocessable(e.ErrNoLinkWithID))
 		return
 	}
@@ -374,16 +803,19 @@ func StarLink(w http.ResponseWriter, r *http.Request) {
 		// (if thus unstarred, add a new row
 		// if already starred, update in place)
 		if !util.UserHasStarredLink(req_user_id, link_id) {
-			star_id := uuid.New().String()
-			if _, err := db.Client.Exec(
-				`INSERT INTO "Stars" VALUES(?,?,?,?,?);`,
-				star_id,
-				link_id,
-				req_user_id,
-				request.Stars,
-				mutil.NEW_LONG_TIMESTAMP(),
-			); err != nil {
+			tx, err := db.Client.Begin()
+			if err != nil {
 				render.Render(w, r, e.ErrInternalServerError(err))
+				return
+			}
+			defer tx.Rollback()
+
+			if err = util.SaveNewStar(tx, link_id, req_user_id, request.Stars); err != nil {
+				render.Render(w, r, e.ErrInternalServerError(err))
+				return
+			} else if err = tx.Commit(); err != nil {
+				render.Render(w, r, e.ErrInternalServerError(err))
+				return
 			}
 		} else {
 			if current_stars := util.GetUsersStarsForLink(req_user_id, link_id); current_stars == request.Stars {
@@ -447,24 +879,29 @@ func ClickLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	link_exists, err := util.LinkExists(request.LinkID)
+	// (private and hidden links are not found, except by their submitter)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(request.LinkID, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
-	} else if !link_exists {
-		render.Render(w, r, e.ErrUnprocessable(e.ErrNoLinkWithID))
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoLinkWithID))
 		return
 	}
 
 	result := struct {
-		ID        string `json:"id"`
-		LinkID    string `json:"link_id"`
-		UserID    string `json:"user_id"`
-		IPAddr    string `json:"ip_addr"`
-		Timestamp string `json:"timestamp"`
+		ID        string             `json:"id"`
+		LinkID    string             `json:"link_id"`
+		UserID    string             `json:"user_id"`
+		IPAddr    string             `json:"ip_addr"`
+		Timestamp string             `json:"timestamp"`
+		Surface   model.ClickSurface `json:"surface,omitempty"`
+		Qualified bool               `json:"qualified"`
 	}{
 		LinkID:    request.LinkID,
 		Timestamp: request.Timestamp,
+		Surface:   request.Surface,
 	}
 
 	// Get user ID, or IP address if not signed in
@@ -476,20 +913,38 @@ func ClickLink(w http.ResponseWriter, r *http.Request) {
 		}
 
 		result.UserID = "anonymous"
-		result.IPAddr = r.RemoteAddr
+		result.IPAddr = util.GetClickIPAddr(r.RemoteAddr)
 	} else {
 		result.UserID = req_user_id
 	}
 
+	// Recorded either way, but only counted if qualified
+	user_agent := r.UserAgent()
+	disqualified_reason, err := util.GetClickDisqualification(
+		result.LinkID,
+		result.UserID,
+		result.IPAddr,
+		user_agent,
+	)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+	result.Qualified = disqualified_reason == model.ClickQualified
+
 	result.ID = uuid.New().String()
 
 	if _, err = db.Client.Exec(
-		`INSERT INTO "Clicks" VALUES(?,?,?,?,?);`,
+		`INSERT INTO "Clicks" (id, link_id, user_id, ip_addr, timestamp, surface, user_agent, disqualified_reason)
+		VALUES(?,?,?,?,?,?,?,?);`,
 		result.ID,
 		result.LinkID,
 		result.UserID,
 		result.IPAddr,
 		result.Timestamp,
+		result.Surface,
+		user_agent,
+		disqualified_reason,
 	); err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
@@ -498,3 +953,69 @@ func ClickLink(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusCreated)
 	render.JSON(w, r, result)
 }
+
+// Submitter and admins only
+func GetLinkClicks(w http.ResponseWriter, r *http.Request) {
+	link_id := chi.URLParam(r, "link_id")
+	if link_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkID))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !link_is_visible {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoLinkWithID))
+		return
+	} else if !util.UserSubmittedLink(req_login_name, link_id) && !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrCannotViewUnownedLinkClicks))
+		return
+	}
+
+	opts, err := util.GetLinkClicksOptionsFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	opts.LinkID = link_id
+
+	stats, err := util.GetLinkClickStats(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, stats)
+}
+
+// Admin only: includes disqualified clicks (duplicates and bots)
+func GetRawClicks(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	opts := &model.RawClicksOptions{
+		LinkID: r.URL.Query().Get("link_id"),
+		Page:   r.Context().Value(m.PageKey).(uint),
+	}
+	disqualified_params := r.URL.Query().Get("disqualified")
+	if disqualified_params == "true" {
+		opts.DisqualifiedOnly = true
+	} else if disqualified_params != "false" && disqualified_params != "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrInvalidDisqualifiedParams))
+		return
+	}
+
+	page, err := util.GetRawClicksPage(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
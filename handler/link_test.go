@@ -5,12 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/julianlk522/modeep/db"
+	util "github.com/julianlk522/modeep/handler/util"
 	m "github.com/julianlk522/modeep/middleware"
 )
 
@@ -115,6 +120,12 @@ func TestGetTopLinks(t *testing.T) {
 			Page:   1,
 			Valid:  false,
 		},
+		// whitespace-only content_contains is ignored
+		{
+			Params: map[string]string{"content_contains": "   "},
+			Page:   1,
+			Valid:  true,
+		},
 	}
 
 	for i, tglr := range test_get_links_requests {
@@ -368,23 +379,253 @@ func TestDeleteLink(t *testing.T) {
 	}
 }
 
+func TestRefreshLinkMetadata(t *testing.T) {
+	var test_requests = []struct {
+		LinkID             string
+		ExpectedStatusCode int
+	}{
+		{
+			LinkID:             "",
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		// not a real link
+		{
+			LinkID:             "-1",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		// test user jlk did not submit link 0
+		{
+			LinkID:             "0",
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(
+			http.MethodPost,
+			"/links/"+tr.LinkID+"/refresh",
+			nil,
+		)
+
+		ctx := context.Background()
+		jwt_claims := map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		}
+		ctx = context.WithValue(ctx, m.JWTClaimsKey, jwt_claims)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("link_id", tr.LinkID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		RefreshLinkMetadata(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+
+	// Success: metadata is re-fetched from the link's URL
+	test_server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				io.WriteString(w, `<html><head>
+				<meta name="description" content="refreshed auto summary">
+				</head><body><p>refreshed page content</p></body></html>`)
+			},
+		))
+	defer test_server.Close()
+
+	const link_id = "refresh-test"
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary)
+		VALUES (?, ?, ?, ?, ?, ?);`,
+		link_id,
+		test_server.URL,
+		TEST_LOGIN_NAME,
+		"2025-01-01 00:00:00",
+		"refreshtest",
+		"",
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		TestClient.Exec("DELETE FROM Summaries WHERE link_id = ?;", link_id)
+		TestClient.Exec(`DELETE FROM "Archive Snapshots" WHERE link_id = ?;`, link_id)
+		TestClient.Exec("DELETE FROM page_content_fts WHERE link_id = ?;", link_id)
+		TestClient.Exec("DELETE FROM Links WHERE id = ?;", link_id)
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/links/"+link_id+"/refresh", nil)
+	ctx := context.Background()
+	jwt_claims := map[string]any{
+		"user_id":    TEST_USER_ID,
+		"login_name": TEST_LOGIN_NAME,
+	}
+	ctx = context.WithValue(ctx, m.JWTClaimsKey, jwt_claims)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("link_id", link_id)
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	r = r.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+	RefreshLinkMetadata(rr, r)
+	res := rr.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected status code 200, got %d: %s", res.StatusCode, body)
+	}
+
+	var global_summary, auto_summary, page_content string
+	var snapshot_count int
+	if err := TestClient.QueryRow(
+		"SELECT COALESCE(global_summary, '') FROM Links WHERE id = ?;",
+		link_id,
+	).Scan(&global_summary); err != nil {
+		t.Fatal(err)
+	} else if global_summary != "refreshed auto summary" {
+		t.Fatalf("expected refreshed global summary, got %q", global_summary)
+	}
+	if err := TestClient.QueryRow(
+		"SELECT text FROM Summaries WHERE link_id = ? AND submitted_by = ?;",
+		link_id,
+		db.AUTO_SUMMARY_USER_ID,
+	).Scan(&auto_summary); err != nil {
+		t.Fatal(err)
+	} else if auto_summary != "refreshed auto summary" {
+		t.Fatalf("expected refreshed auto summary, got %q", auto_summary)
+	}
+	if err := TestClient.QueryRow(
+		"SELECT content FROM page_content_fts WHERE link_id = ?;",
+		link_id,
+	).Scan(&page_content); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(page_content, "refreshed page content") {
+		t.Fatalf("expected refreshed page content, got %q", page_content)
+	}
+	if err := TestClient.QueryRow(
+		`SELECT count(*) FROM "Archive Snapshots" WHERE link_id = ?;`,
+		link_id,
+	).Scan(&snapshot_count); err != nil {
+		t.Fatal(err)
+	} else if snapshot_count != 1 {
+		t.Fatalf("expected 1 archive snapshot, got %d", snapshot_count)
+	}
+}
+
+func TestImportLinks(t *testing.T) {
+	var test_requests = []struct {
+		FileContent        string
+		Format             string
+		ExpectedStatusCode int
+	}{
+		// no file
+		{"", "", http.StatusBadRequest},
+		{"<DL><p><DT><A HREF=\"https://go.dev\">Go</A></DL>", "delicious", http.StatusBadRequest},
+		{"not a bookmarks file", "", http.StatusBadRequest},
+		// no importable bookmarks
+		{"<DL><p><DT><A HREF=\"javascript:void(0)\">JS</A></DL>", "", http.StatusBadRequest},
+		{"[{", "pinboard", http.StatusUnprocessableEntity},
+	}
+
+	for _, tr := range test_requests {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if tr.FileContent != "" {
+			fw, err := mw.CreateFormFile("file", "bookmarks")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte(tr.FileContent))
+		}
+		if tr.Format != "" {
+			mw.WriteField("format", tr.Format)
+		}
+		mw.Close()
+
+		r := httptest.NewRequest(http.MethodPost, "/links/import", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    TEST_USER_ID,
+			"login_name": TEST_LOGIN_NAME,
+		})
+		r = r.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		ImportLinks(rr, r)
+		res := rr.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+			)
+		}
+	}
+}
+
 func TestClickLink(t *testing.T) {
+	const private_link_id = "click-private-test"
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary, visibility)
+		VALUES (?, 'https://click-private-test.com', 'bradley', '2025-01-01', 'test', '', 'private');`,
+		private_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", private_link_id)
+	defer TestClient.Exec("DELETE FROM Clicks WHERE link_id = ?;", private_link_id)
+
 	var test_requests = []struct {
-		LinkID string
-		UserID string
-		Valid  bool
+		LinkID  string
+		UserID  string
+		Surface string
+		Valid   bool
 	}{
 		{
 			LinkID: "0",
 			UserID: TEST_USER_ID,
 			Valid:  true,
 		},
+		{
+			LinkID:  "0",
+			UserID:  TEST_USER_ID,
+			Surface: "tmap",
+			Valid:   true,
+		},
+		// invalid surface
+		{
+			LinkID:  "0",
+			UserID:  TEST_USER_ID,
+			Surface: "newsletter",
+			Valid:   false,
+		},
 		// not a real link
 		{
 			LinkID: "-1",
 			UserID: TEST_USER_ID,
 			Valid:  false,
 		},
+		// someone else's private link
+		{
+			LinkID: private_link_id,
+			UserID: TEST_USER_ID,
+			Valid:  false,
+		},
 		// not a real user
 		{
 			LinkID: "99",
@@ -403,6 +644,9 @@ func TestClickLink(t *testing.T) {
 		pl, b := map[string]string{
 			"link_id": tr.LinkID,
 		}, new(bytes.Buffer)
+		if tr.Surface != "" {
+			pl["surface"] = tr.Surface
+		}
 		err := json.NewEncoder(b).Encode(pl)
 		if err != nil {
 			t.Fatal(err)
@@ -415,8 +659,13 @@ func TestClickLink(t *testing.T) {
 		r.Header.Set("Content-Type", "application/json")
 
 		ctx := context.Background()
+		var req_login_name string
+		if tr.UserID == TEST_USER_ID {
+			req_login_name = TEST_LOGIN_NAME
+		}
 		jwt_claims := map[string]any{
-			"user_id": tr.UserID,
+			"user_id":    tr.UserID,
+			"login_name": req_login_name,
 		}
 		ctx = context.WithValue(ctx, m.JWTClaimsKey, jwt_claims)
 		r = r.WithContext(ctx)
@@ -455,3 +704,108 @@ func TestClickLink(t *testing.T) {
 		}
 	}
 }
+
+func TestGetLinkClicks(t *testing.T) {
+	const link_id = "link-clicks-test"
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary)
+		VALUES (?, 'https://link-clicks-test.com', ?, '2025-01-01', 'test', '');`,
+		link_id,
+		TEST_LOGIN_NAME,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", link_id)
+
+	original_admin_login_names := util.Admin_login_names
+	defer func() { util.Admin_login_names = original_admin_login_names }()
+	util.Admin_login_names = []string{"link-clicks-test-admin"}
+
+	var test_requests = []struct {
+		LinkID             string
+		LoginName          string
+		ExpectedStatusCode int
+	}{
+		// submitter
+		{link_id, TEST_LOGIN_NAME, http.StatusOK},
+		{link_id, "link-clicks-test-admin", http.StatusOK},
+		{link_id, "bradley", http.StatusForbidden},
+		// signed out
+		{link_id, "", http.StatusForbidden},
+		{"-1", TEST_LOGIN_NAME, http.StatusNotFound},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(http.MethodGet, "/links/"+tr.LinkID+"/clicks", nil)
+
+		ctx := context.WithValue(context.Background(), m.JWTClaimsKey, map[string]any{
+			"user_id":    "",
+			"login_name": tr.LoginName,
+		})
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("link_id", tr.LinkID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		r = r.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		GetLinkClicks(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			text, _ := io.ReadAll(res.Body)
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)\n%s",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+				text,
+			)
+		}
+	}
+}
+
+func TestGetRawClicks(t *testing.T) {
+	original_admin_login_names := util.Admin_login_names
+	defer func() { util.Admin_login_names = original_admin_login_names }()
+	util.Admin_login_names = []string{TEST_LOGIN_NAME}
+
+	var test_requests = []struct {
+		LoginName          string
+		Params             string
+		ExpectedStatusCode int
+	}{
+		{TEST_LOGIN_NAME, "", http.StatusOK},
+		{TEST_LOGIN_NAME, "?link_id=1&disqualified=true", http.StatusOK},
+		{TEST_LOGIN_NAME, "?disqualified=maybe", http.StatusBadRequest},
+		{"not_an_admin", "", http.StatusForbidden},
+	}
+
+	for _, tr := range test_requests {
+		r := httptest.NewRequest(http.MethodGet, "/admin/clicks"+tr.Params, nil)
+
+		ctx := context.Background()
+		jwt_claims := map[string]any{
+			"login_name": tr.LoginName,
+		}
+		ctx = context.WithValue(ctx, m.JWTClaimsKey, jwt_claims)
+		ctx = context.WithValue(ctx, m.PageKey, uint(1))
+		r = r.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		GetRawClicks(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+
+		if res.StatusCode != tr.ExpectedStatusCode {
+			text, _ := io.ReadAll(res.Body)
+			t.Fatalf(
+				"expected status code %d, got %d (test request %+v)\n%s",
+				tr.ExpectedStatusCode,
+				res.StatusCode,
+				tr,
+				text,
+			)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/handler/util"
+	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/model"
+)
+
+func AddReport(w http.ResponseWriter, r *http.Request) {
+	request := &model.NewReportRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	content_is_visible, err := util.ReportedContentIsVisibleToUser(
+		request.ContentType,
+		request.ContentID,
+		req_login_name,
+	)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	} else if !content_is_visible {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoReportedContentWithID))
+		return
+	}
+
+	if err := util.CreateReport(request, req_login_name); err == e.ErrAlreadyReported {
+		render.Render(w, r, e.ErrConflict(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Admin only
+func GetReports(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	opts := &model.ReportsOptions{
+		Status: model.ReportStatus(r.URL.Query().Get("status")),
+		Page:   r.Context().Value(m.PageKey).(uint),
+	}
+	if opts.Status != "" && !slices.Contains(model.ValidReportStatuses[:], opts.Status) {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrInvalidReportStatus))
+		return
+	}
+
+	page, err := util.GetReportsPage(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	render.JSON(w, r, page)
+}
+
+// Admin only: resolves, hides the content of or dismisses a report,
+// along with any other open reports on the same content
+func ModerateReport(w http.ResponseWriter, r *http.Request) {
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	if !util.UserIsAdmin(req_login_name) {
+		render.Render(w, r, e.ErrForbidden(e.ErrNotAdmin))
+		return
+	}
+
+	request := &model.ModerateReportRequest{}
+	if err := render.Bind(r, request); err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	report_id := chi.URLParam(r, "report_id")
+	if report_id == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoReportID))
+		return
+	}
+	report, err := util.GetReport(report_id)
+	if err == sql.ErrNoRows {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoReportWithID))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	if err := util.ModerateReport(report, request.Action, req_login_name); err == e.ErrReportAlreadyClosed {
+		render.Render(w, r, e.ErrConflict(err))
+		return
+	} else if err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
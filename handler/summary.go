@@ -22,11 +22,12 @@ func GetSummaryPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	link_exists, err := util.LinkExists(link_id)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
-	} else if !link_exists {
+	} else if !link_is_visible {
 		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
 		return
 	}
@@ -48,11 +49,12 @@ func AddSummary(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
-	link_exists, err := util.LinkExists(summary_data.LinkID)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(summary_data.LinkID, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInternalServerError(err))
 		return
-	} else if !link_exists {
+	} else if !link_is_visible {
 		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
 		return
 	}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -22,11 +21,12 @@ func GetTagPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	link_exists, err := util.LinkExists(link_id)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(link_id, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInvalidRequest(err))
 		return
-	} else if !link_exists {
+	} else if !link_is_visible {
 		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
 		return
 	}
@@ -44,7 +44,6 @@ func GetTagPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
 	req_user_tag, err := util.GetUserTagForLink(req_login_name, link_id)
 	if err != nil {
 		render.Render(w, r, e.ErrInvalidRequest(err))
@@ -128,7 +127,7 @@ func GetTopGlobalCats(w http.ResponseWriter, r *http.Request) {
 	cats_params := query_params.Get("cats")
 
 	if more_params == "true" && cats_params != "" {
-		cat_filters := strings.Split(cats_params, ",")
+		cat_filters := util.GetCatsFromCatQueryParams(cats_params)
 		merged_cats := []string{}
 
 		for _, count := range *counts {
@@ -139,6 +138,18 @@ func GetTopGlobalCats(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if opts.Tree {
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, struct {
+				Counts     []model.CatCountTree
+				MergedCats []string
+			}{
+				Counts:     util.GetCatCountTree(*counts),
+				MergedCats: merged_cats,
+			})
+			return
+		}
+
 		counts_and_merges := struct {
 			Counts     []model.CatCount
 			MergedCats []string
@@ -153,6 +164,10 @@ func GetTopGlobalCats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	render.Status(r, http.StatusOK)
+	if opts.Tree {
+		render.JSON(w, r, util.GetCatCountTree(*counts))
+		return
+	}
 	render.JSON(w, r, counts)
 }
 
@@ -204,16 +219,16 @@ func AddTag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	link_exists, err := util.LinkExists(tag_data.LinkID)
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	link_is_visible, err := util.LinkIsVisibleToUser(tag_data.LinkID, req_login_name)
 	if err != nil {
 		render.Render(w, r, e.ErrInvalidRequest(err))
 		return
-	} else if !link_exists {
+	} else if !link_is_visible {
 		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLinkWithID))
 		return
 	}
 
-	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
 	duplicate, err := util.UserHasTaggedLink(req_login_name, tag_data.LinkID)
 	if err != nil {
 		render.Render(w, r, e.ErrInvalidRequest(err))
@@ -223,25 +238,21 @@ func AddTag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tag_data.Cats = util.TidyCats(tag_data.Cats)
-
-	_, err = db.Client.Exec(
-		"INSERT INTO Tags VALUES(?,?,?,?,?);",
-		tag_data.ID,
-		tag_data.LinkID,
-		tag_data.Cats,
-		req_login_name,
-		tag_data.LastUpdated,
-	)
+	tx, err := db.Client.Begin()
 	if err != nil {
-		render.Render(w, r, e.ErrInvalidRequest(err))
+		render.Render(w, r, e.ErrInternalServerError(err))
 		return
 	}
+	defer tx.Rollback()
 
-	if err = util.CalculateAndSetGlobalCats(tag_data.LinkID); err != nil {
+	if err = util.SaveNewTag(tx, tag_data, req_login_name); err != nil {
 		render.Render(w, r, e.ErrInvalidRequest(err))
 		return
 	}
+	if err = tx.Commit(); err != nil {
+		render.Render(w, r, e.ErrInternalServerError(err))
+		return
+	}
 
 	render.Status(r, http.StatusCreated)
 	render.JSON(w, r, tag_data)
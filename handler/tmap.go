@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -44,6 +45,8 @@ func GetTreasureMap(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	opts.OwnerLoginName = login_name
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	opts.IncludePrivate = req_login_name == login_name
 	req_user_id := r.Context().Value(m.JWTClaimsKey).(map[string]any)["user_id"].(string)
 	if req_user_id != "" {
 		opts.AsSignedInUser = req_user_id
@@ -60,6 +63,66 @@ func GetTreasureMap(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, tmap)
 }
 
+// Unpaginated: every link matching the same filters as GetTreasureMap
+func ExportTreasureMap(w http.ResponseWriter, r *http.Request) {
+	var login_name string = chi.URLParam(r, "login_name")
+	if login_name == "" {
+		render.Render(w, r, e.ErrInvalidRequest(e.ErrNoLoginName))
+		return
+	}
+
+	user_exists, err := util.UserExists(login_name)
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	} else if !user_exists {
+		render.Render(w, r, e.ErrNotFound(e.ErrNoUserWithLoginName))
+		return
+	}
+
+	format, err := util.GetTmapExportFormatFromRequestParams(r.URL.Query())
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	opts, err := util.GetTmapOptionsFromRequestParams(
+		r.URL.Query(),
+	)
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+	opts.OwnerLoginName = login_name
+	req_login_name := r.Context().Value(m.JWTClaimsKey).(map[string]any)["login_name"].(string)
+	opts.IncludePrivate = req_login_name == login_name
+
+	links, err := util.GetTmapExportLinks(opts)
+	if err != nil {
+		render.Render(w, r, e.ErrInvalidRequest(err))
+		return
+	}
+
+	var content_type, extension string
+	switch format {
+	case model.TmapExportFormatNetscape:
+		content_type, extension = "text/html; charset=utf-8", "html"
+	case model.TmapExportFormatJSON:
+		content_type, extension = "application/json", "json"
+	case model.TmapExportFormatCSV:
+		content_type, extension = "text/csv; charset=utf-8", "csv"
+	}
+	w.Header().Set("Content-Type", content_type)
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s_treasure_map.%s"`, login_name, extension),
+	)
+
+	// Headers already sent, so can only log
+	if err = util.WriteTmapExport(w, links, format, login_name); err != nil {
+		log.Printf("Could not write Treasure Map export for %s: %s", login_name, err)
+	}
+}
+
 func EditAbout(w http.ResponseWriter, r *http.Request) {
 	edit_about_data := &model.EditAboutRequest{}
 	if err := render.Bind(r, edit_about_data); err != nil {
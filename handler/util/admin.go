@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// From $MODEEP_ADMIN_LOGIN_NAMES (comma-separated).
+// Empty if unset: no one is an admin.
+var Admin_login_names []string
+
+func init() {
+	for login_name := range strings.SplitSeq(os.Getenv("MODEEP_ADMIN_LOGIN_NAMES"), ",") {
+		if login_name = strings.TrimSpace(login_name); login_name != "" {
+			Admin_login_names = append(Admin_login_names, login_name)
+		}
+	}
+}
+
+func UserIsAdmin(login_name string) bool {
+	return login_name != "" && slices.Contains(Admin_login_names, login_name)
+}
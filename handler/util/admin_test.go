@@ -0,0 +1,26 @@
+package handler
+
+import "testing"
+
+func TestUserIsAdmin(t *testing.T) {
+	original_admin_login_names := Admin_login_names
+	defer func() { Admin_login_names = original_admin_login_names }()
+	Admin_login_names = []string{"admin_a", "admin_b"}
+
+	var test_login_names = []struct {
+		LoginName string
+		IsAdmin   bool
+	}{
+		{"admin_a", true},
+		{"admin_b", true},
+		{"Admin_a", false},
+		{TEST_LOGIN_NAME, false},
+		{"", false},
+	}
+
+	for _, tl := range test_login_names {
+		if got := UserIsAdmin(tl.LoginName); got != tl.IsAdmin {
+			t.Fatalf("expected %t for %q, got %t", tl.IsAdmin, tl.LoginName, got)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/julianlk522/modeep/db"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+var Archive_dir string
+
+func init() {
+	backend_root_path := os.Getenv("MODEEP_BACKEND_ROOT")
+	if backend_root_path == "" {
+		log.Panic("$MODEEP_BACKEND_ROOT not set")
+	}
+	Archive_dir = backend_root_path + "/db/archive"
+}
+
+// Only successful responses with text content are worth keeping
+// (403 pages etc. are usually bot-blocking pages, not the real content)
+func isArchivable(resp *http.Response) bool {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false
+	}
+
+	media_type, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return media_type == "text/html" ||
+		media_type == "application/xhtml+xml" ||
+		media_type == "text/plain"
+}
+
+// Only records the snapshot: its file is written by WriteArchiveSnapshotFile
+// once tx commits, so a rolled-back snapshot never leaves a file behind.
+func SaveArchiveSnapshot(tx *sql.Tx, link_id string, content []byte, content_type string, truncated bool) error {
+	if len(content) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		`INSERT INTO "Archive Snapshots" (link_id, hash, content_type, size_bytes, truncated, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?);`,
+		link_id,
+		getArchiveHash(content),
+		content_type,
+		len(content),
+		truncated,
+		mutil.NEW_LONG_TIMESTAMP(),
+	)
+	return err
+}
+
+// Files are content-addressed (named by SHA-256 of content) so identical
+// pages are only stored once, however many snapshots reference them.
+func WriteArchiveSnapshotFile(content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+
+	hash := getArchiveHash(content)
+	path := Archive_dir + "/" + hash
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(Archive_dir, 0755); err != nil {
+		return err
+	}
+
+	// Write to a uniquely-named temp file then rename so a partial file is
+	// never served (and concurrent writers of the same page don't collide)
+	tmp_file, err := os.CreateTemp(Archive_dir, hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp_path := tmp_file.Name()
+	if _, err = tmp_file.Write(content); err != nil {
+		tmp_file.Close()
+		os.Remove(tmp_path)
+		return err
+	}
+	if err = tmp_file.Close(); err != nil {
+		os.Remove(tmp_path)
+		return err
+	}
+	if err = os.Chmod(tmp_path, 0644); err != nil {
+		os.Remove(tmp_path)
+		return err
+	}
+	if err = os.Rename(tmp_path, path); err != nil {
+		os.Remove(tmp_path)
+		return err
+	}
+
+	return nil
+}
+
+func getArchiveHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns sql.ErrNoRows if link has no snapshots
+func GetLatestArchiveSnapshot(link_id string) (*model.ArchiveSnapshot, error) {
+	var snapshot = &model.ArchiveSnapshot{}
+	if err := db.Client.QueryRow(
+		`SELECT link_id, hash, content_type, size_bytes, truncated, archived_at
+		FROM "Archive Snapshots"
+		WHERE link_id = ?
+		ORDER BY archived_at DESC, id DESC
+		LIMIT 1;`,
+		link_id,
+	).Scan(
+		&snapshot.LinkID,
+		&snapshot.Hash,
+		&snapshot.ContentType,
+		&snapshot.SizeBytes,
+		&snapshot.Truncated,
+		&snapshot.ArchivedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func GetArchiveSnapshotHashesForLink(link_id string) ([]string, error) {
+	rows, err := db.Client.Query(
+		`SELECT DISTINCT hash FROM "Archive Snapshots" WHERE link_id = ?;`,
+		link_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// Call after snapshot rows are deleted: removes any of the given files
+// that no remaining snapshot points to
+func DeleteUnreferencedArchiveFiles(hashes []string) {
+	for _, hash := range hashes {
+		var ref_count int
+		if err := db.Client.QueryRow(
+			`SELECT COUNT(*) FROM "Archive Snapshots" WHERE hash = ?;`,
+			hash,
+		).Scan(&ref_count); err != nil {
+			log.Printf("Could not count references to archive file %s: %s", hash, err)
+			continue
+		} else if ref_count > 0 {
+			continue
+		}
+
+		if err := os.Remove(Archive_dir + "/" + hash); err != nil && !os.IsNotExist(err) {
+			log.Printf("Could not delete archive file %s: %s", hash, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestIsArchivable(t *testing.T) {
+	var test_responses = []struct {
+		StatusCode  int
+		ContentType string
+		Archivable  bool
+	}{
+		{200, "text/html; charset=utf-8", true},
+		{200, "text/plain", true},
+		{200, "application/xhtml+xml", true},
+		{200, "application/pdf", false},
+		{200, "image/png", false},
+		{200, "", false},
+		{403, "text/html", false},
+		{500, "text/html", false},
+	}
+
+	for _, tr := range test_responses {
+		resp := &http.Response{
+			StatusCode: tr.StatusCode,
+			Header:     http.Header{"Content-Type": []string{tr.ContentType}},
+		}
+		if got := isArchivable(resp); got != tr.Archivable {
+			t.Fatalf(
+				"expected archivable %t for %d %q, got %t",
+				tr.Archivable,
+				tr.StatusCode,
+				tr.ContentType,
+				got,
+			)
+		}
+	}
+}
+
+func TestSaveArchiveSnapshot(t *testing.T) {
+	test_link_id := "1"
+	content := []byte("<html><body>archived for TestSaveArchiveSnapshot</body></html>")
+
+	tx, err := TestClient.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SaveArchiveSnapshot(tx, test_link_id, content, "text/html", true); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := GetLatestArchiveSnapshot(test_link_id)
+	if err != nil {
+		t.Fatal(err)
+	} else if snapshot.SizeBytes != len(content) {
+		t.Fatalf("expected size %d, got %d", len(content), snapshot.SizeBytes)
+	} else if !snapshot.Truncated {
+		t.Fatal("expected snapshot to be marked truncated")
+	}
+
+	// File only written once committed
+	path := Archive_dir + "/" + snapshot.Hash
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no archive file %s before it is written", path)
+	}
+	if err = WriteArchiveSnapshotFile(content); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(saved) != string(content) {
+		t.Fatalf("saved content differs: got %s", saved)
+	}
+
+	// Cleanup: file should be removed once unreferenced
+	if _, err = TestClient.Exec(
+		`DELETE FROM "Archive Snapshots" WHERE hash = ?;`,
+		snapshot.Hash,
+	); err != nil {
+		t.Fatal(err)
+	}
+	DeleteUnreferencedArchiveFiles([]string{snapshot.Hash})
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected archive file %s to be deleted", path)
+	}
+}
+
+func TestGetFinalURLAndExtraMetadataTruncatesPageContent(t *testing.T) {
+	test_server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write(bytes.Repeat([]byte("a"), MAX_ARCHIVE_SNAPSHOT_BYTES+10))
+			},
+		))
+	defer test_server.Close()
+
+	_, x_md, err := GetFinalURLAndExtraMetadata(test_server.URL)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(x_md.PageContent) != MAX_ARCHIVE_SNAPSHOT_BYTES {
+		t.Fatalf("expected %d bytes of page content, got %d", MAX_ARCHIVE_SNAPSHOT_BYTES, len(x_md.PageContent))
+	} else if !x_md.PageContentTruncated {
+		t.Fatal("expected page content to be marked truncated")
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Query params that only track where a visitor came from and never
+// change what page is served. Any param starting with "utm_" is also removed.
+var TRACKING_PARAMS = []string{
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"mc_cid",
+	"mc_eid",
+	"igshid",
+}
+
+// Reduces a URL to a key that is the same for all trivial variants of it:
+// scheme, "www.", host case, default ports, fragments, trailing slashes,
+// tracking params and query param order are all ignored.
+// Used for duplicate detection only; the URL shown to users is unchanged.
+func GetCanonicalURLKey(raw_url string) (string, error) {
+	raw_url = strings.TrimSpace(raw_url)
+	if !strings.HasPrefix(raw_url, "http://") && !strings.HasPrefix(raw_url, "https://") {
+		raw_url = "https://" + raw_url
+	}
+
+	u, err := url.Parse(raw_url)
+	if err != nil {
+		return "", err
+	} else if u.Hostname() == "" {
+		return "", invalidURLError(raw_url)
+	}
+
+	host := getNormalizedHost(u)
+	port := u.Port()
+	if port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+
+	params := u.Query()
+	for param := range params {
+		if isTrackingParam(param) {
+			params.Del(param)
+		}
+	}
+
+	key := host + path
+	// Encode() sorts by key
+	if len(params) > 0 {
+		key += "?" + params.Encode()
+	}
+
+	return key, nil
+}
+
+func getNormalizedHost(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	return strings.TrimSuffix(host, ".")
+}
+
+func hasSameHost(a *url.URL, b *url.URL) bool {
+	return getNormalizedHost(a) == getNormalizedHost(b)
+}
+
+func isTrackingParam(param string) bool {
+	param = strings.ToLower(param)
+	if strings.HasPrefix(param, "utm_") {
+		return true
+	}
+
+	return slices.Contains(TRACKING_PARAMS, param)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetCanonicalURLKey(t *testing.T) {
+	var test_urls = []struct {
+		URL         string
+		ExpectedKey string
+	}{
+		{"https://example.com", "example.com"},
+		{"http://example.com/", "example.com"},
+		{"example.com", "example.com"},
+		{"https://www.example.com", "example.com"},
+		{"https://WWW.Example.COM/Path", "example.com/Path"},
+		{"https://example.com:443/a", "example.com/a"},
+		{"http://example.com:80/a", "example.com/a"},
+		{"https://example.com:8080/a", "example.com:8080/a"},
+		{"https://example.com/a#section-2", "example.com/a"},
+		{"https://example.com/a/?utm_source=x&utm_medium=y", "example.com/a"},
+		{"https://example.com/a?fbclid=abc&id=7", "example.com/a?id=7"},
+		{"https://example.com/a?b=2&a=1", "example.com/a?a=1&b=2"},
+	}
+
+	for _, tu := range test_urls {
+		key, err := GetCanonicalURLKey(tu.URL)
+		if err != nil {
+			t.Fatalf("unexpected error for URL %s: %s", tu.URL, err)
+		} else if key != tu.ExpectedKey {
+			t.Fatalf("expected key %s for URL %s, got %s", tu.ExpectedKey, tu.URL, key)
+		}
+	}
+
+	if _, err := GetCanonicalURLKey("https://"); err == nil {
+		t.Fatal("expected error for URL without host")
+	}
+}
+
+func TestGetLinkExtraMetadataFromHTMLCanonical(t *testing.T) {
+	page_url, err := url.Parse("https://www.example.com/post?utm_source=feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var test_canonicals = []struct {
+		Canonical            string
+		ExpectedCanonicalURL string
+	}{
+		{"/post", "https://www.example.com/post"},
+		{"https://example.com/post", "https://example.com/post"},
+		{"https://EXAMPLE.com/post", "https://EXAMPLE.com/post"},
+		// other hosts ignored
+		{"https://someoneelse.com/post", ""},
+		{"https://sub.example.com/post", ""},
+	}
+
+	for _, tc := range test_canonicals {
+		x_md := getLinkExtraMetadataFromHTML(page_url, HTMLMetadata{Canonical: tc.Canonical})
+		if x_md.CanonicalURL != tc.ExpectedCanonicalURL {
+			t.Fatalf(
+				"canonical %s: expected %q, got %q",
+				tc.Canonical,
+				tc.ExpectedCanonicalURL,
+				x_md.CanonicalURL,
+			)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func GetCatGraphOptionsFromRequestParams(params url.Values) (*model.CatGraphOptions, error) {
+	opts := &model.CatGraphOptions{MinWeight: 1}
+
+	// Only links with these cats count toward nodes and edges
+	cats_params := params.Get("cats")
+	if cats_params != "" {
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
+	}
+	neutered_params := params.Get("neutered")
+	if neutered_params != "" {
+		// Since we use IN, not FTS MATCH, spelling variants are not
+		// needed (and casing matters)
+		opts.NeuteredCatFilters = strings.Split(neutered_params, ",")
+	}
+	period_params := params.Get("period")
+	if period_params != "" {
+		period := model.Period(period_params)
+		if _, ok := model.ValidPeriodsInDays[period]; !ok {
+			return nil, e.ErrInvalidPeriod
+		}
+		opts.Period = period
+	}
+	nsfw_params := params.Get("include_nsfw")
+	if nsfw_params == "true" {
+		opts.IncludeNSFW = true
+	} else if nsfw_params != "false" && nsfw_params != "" {
+		return nil, e.ErrInvalidNSFWParams
+	}
+	min_weight_params := params.Get("min_weight")
+	if min_weight_params != "" {
+		min_weight, err := strconv.Atoi(min_weight_params)
+		if err != nil || min_weight < 1 {
+			return nil, e.ErrInvalidMinWeightParams
+		}
+		opts.MinWeight = min_weight
+	}
+
+	return opts, nil
+}
+
+func GetCatGraph(opts *model.CatGraphOptions) (*model.CatGraph, error) {
+	nodes_sql, err := query.NewCatGraphNodes().FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := scanCatGraphNodes(nodes_sql)
+	if err != nil {
+		return nil, err
+	}
+
+	edges_sql, err := query.NewCatGraphEdges(opts.MinWeight).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := scanCatGraphEdges(edges_sql)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CatGraph{
+		Nodes: nodes,
+		Edges: edges,
+	}, nil
+}
+
+func scanCatGraphNodes(nodes_sql *query.CatGraph) ([]model.CatCount, error) {
+	rows, err := nodes_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []model.CatCount{}
+	for rows.Next() {
+		var node model.CatCount
+		if err := rows.Scan(&node.Category, &node.Count); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+func scanCatGraphEdges(edges_sql *query.CatGraph) ([]model.CatGraphEdge, error) {
+	rows, err := edges_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []model.CatGraphEdge{}
+	for rows.Next() {
+		var edge model.CatGraphEdge
+		if err := rows.Scan(&edge.Source, &edge.Target, &edge.Weight); err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
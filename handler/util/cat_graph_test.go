@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestGetCatGraphOptionsFromRequestParams(t *testing.T) {
+	opts, err := GetCatGraphOptionsFromRequestParams(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	} else if opts.MinWeight != 1 {
+		t.Fatalf("expected default min weight 1, got %d", opts.MinWeight)
+	}
+
+	opts, err = GetCatGraphOptionsFromRequestParams(url.Values{
+		"cats":         []string{"umvc3,flowers"},
+		"neutered":     []string{"test"},
+		"period":       []string{"month"},
+		"include_nsfw": []string{"true"},
+		"min_weight":   []string{"3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(opts.CatFiltersWithSpellingVariants) != 2 ||
+		len(opts.NeuteredCatFilters) != 1 ||
+		opts.Period != model.PeriodMonth ||
+		!opts.IncludeNSFW ||
+		opts.MinWeight != 3 {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	for _, tp := range []struct {
+		Params      url.Values
+		ExpectedErr error
+	}{
+		{url.Values{"period": []string{"decade"}}, e.ErrInvalidPeriod},
+		{url.Values{"include_nsfw": []string{"yes"}}, e.ErrInvalidNSFWParams},
+		{url.Values{"min_weight": []string{"0"}}, e.ErrInvalidMinWeightParams},
+		{url.Values{"min_weight": []string{"two"}}, e.ErrInvalidMinWeightParams},
+	} {
+		if _, err := GetCatGraphOptionsFromRequestParams(tp.Params); err != tp.ExpectedErr {
+			t.Fatalf("params %v: expected %v, got %v", tp.Params, tp.ExpectedErr, err)
+		}
+	}
+}
+
+func TestGetCatGraph(t *testing.T) {
+	const test_link_id = "cat-graph-test"
+	initial_cats := []string{"catgraphtestgo", "catgraphtestrust"}
+	if _, err := db.Client.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+		VALUES (?, 'https://cat-graph-test.com', 'bradley', '2025-01-01', ?);`,
+		test_link_id,
+		"catgraphtestgo,catgraphtestrust",
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := IncrementSpellfixRanksForCats(nil, initial_cats); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		db.Client.Exec("DELETE FROM Links WHERE id = ?;", test_link_id)
+		db.Client.Exec(`DELETE FROM "Link Global Cats" WHERE link_id = ?;`, test_link_id)
+		db.Client.Exec(`DELETE FROM "Link Global Cat Pairs" WHERE link_id = ?;`, test_link_id)
+		db.Client.Exec("DELETE FROM global_cats_spellfix WHERE word LIKE 'catgraphtest%';")
+	}()
+
+	// Setting global cats keeps "Link Global Cats" and "Link Global Cat
+	// Pairs" in sync
+	if err := setGlobalCats(test_link_id, "catgraphtestgo,catgraphtestdatabases"); err != nil {
+		t.Fatal(err)
+	}
+
+	var cat_a, cat_b string
+	if err := db.Client.QueryRow(
+		`SELECT normalized_cat_a, normalized_cat_b
+		FROM "Link Global Cat Pairs"
+		WHERE link_id = ?;`,
+		test_link_id,
+	).Scan(&cat_a, &cat_b); err != nil {
+		t.Fatal(err)
+	} else if cat_a != "catgraphtestdatabase" || cat_b != "catgraphtestgo" {
+		t.Fatalf("expected pair catgraphtestdatabase-catgraphtestgo, got %s-%s", cat_a, cat_b)
+	}
+
+	graph, err := GetCatGraph(&model.CatGraphOptions{
+		CatFiltersWithSpellingVariants: []string{`("catgraphtestgo" OR "catgraphtestgos")`},
+		MinWeight:                      1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(graph.Nodes) != 2 ||
+		!slices.Contains(graph.Nodes, model.CatCount{Category: "catgraphtestgo", Count: 1}) ||
+		!slices.Contains(graph.Nodes, model.CatCount{Category: "catgraphtestdatabases", Count: 1}) {
+		t.Fatalf("got nodes %+v", graph.Nodes)
+	} else if len(graph.Edges) != 1 || graph.Edges[0] != (model.CatGraphEdge{
+		Source: "catgraphtestdatabases",
+		Target: "catgraphtestgo",
+		Weight: 1,
+	}) {
+		t.Fatalf("got edges %+v", graph.Edges)
+	}
+}
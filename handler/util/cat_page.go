@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"database/sql"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+// sort_by params work the same as for top links; default times starred
+func GetCatPageSortByFromRequestParams(params url.Values) (model.SortBy, error) {
+	sort_params := params.Get("sort_by")
+	if sort_params == "" {
+		return "", nil
+	}
+
+	sort_by := model.SortBy(sort_params)
+	if !slices.Contains(model.ValidSortBys[:], sort_by) {
+		return "", e.ErrInvalidSortByParams
+	}
+
+	return sort_by, nil
+}
+
+func ValidateCatPageCat(cat string) error {
+	switch {
+	case cat == "":
+		return e.ErrNoCat
+	case strings.Contains(cat, ","):
+		return e.ErrCatPageHasMultipleCats
+	case len(cat) > mutil.CAT_CHAR_LIMIT:
+		return e.CatCharsExceedLimit(mutil.CAT_CHAR_LIMIT)
+	}
+
+	return nil
+}
+
+// Links, contributors and counts all match cat's plural/singular
+// variants and synonyms, the same as a "cats" filter with only cat would
+func GetCatPage[T model.HasCats](cat string, req_user_id string, sort_by model.SortBy) (*model.CatPage[T], error) {
+	cat_filters := query.GetCatsOptionalPluralOrSingularForms([]string{cat})
+
+	description, err := GetCatDescription(cat)
+	if err != nil {
+		return nil, err
+	}
+
+	links_sql, err := query.NewTopLinks().FromOptions(&model.TopLinksOptions{
+		CatFiltersWithSpellingVariants: cat_filters,
+		AsSignedInUser:                 req_user_id,
+		SortBy:                         sort_by,
+	})
+	if err != nil {
+		return nil, err
+	}
+	links_page, err := PrepareLinksPage[T](
+		links_sql,
+		&model.LinksPageOptions{CatFilters: []string{cat}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	contributors_sql, err := query.NewTopContributors().FromOptions(&model.TopContributorsOptions{
+		CatFiltersWithSpellingVariants: cat_filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	contributors, err := scanContributors(contributors_sql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Omit cat itself in any spelling, as well as its synonyms
+	omitted_cats := []string{cat}
+	for _, c := range append([]string{query.GetSingularCat(cat)}, query.GetCatSynonyms(cat)...) {
+		if !slices.Contains(omitted_cats, c) {
+			omitted_cats = append(omitted_cats, c)
+		}
+	}
+	co_occurring_cats_sql, err := query.NewTopGlobalCatCounts().FromOptions(&model.TopCatCountsOptions{
+		RawCatFilters:                  omitted_cats,
+		CatFiltersWithSpellingVariants: cat_filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	co_occurring_cats, err := ScanGlobalCatCounts(co_occurring_cats_sql)
+	if err != nil {
+		return nil, err
+	}
+
+	monthly_link_counts, err := GetMonthlyLinkCountsForCat(cat)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CatPage[T]{
+		Cat:               cat,
+		Description:       description,
+		TopLinks:          links_page,
+		TopContributors:   contributors,
+		CoOccurringCats:   co_occurring_cats,
+		MonthlyLinkCounts: monthly_link_counts,
+	}, nil
+}
+
+// nil if cat has no description yet
+func GetCatDescription(cat string) (*model.CatDescription, error) {
+	description_sql := query.NewCatDescription(cat)
+	row, err := description_sql.ValidateAndExecuteRow()
+	if err != nil {
+		return nil, err
+	}
+
+	var description model.CatDescription
+	if err := row.Scan(
+		&description.Text,
+		&description.LastEditedBy,
+		&description.LastEditedAt,
+		&description.RevisionCount,
+	); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &description, nil
+}
+
+// Adds a revision, which becomes the current description
+func EditCatDescription(cat string, request *model.EditCatDescriptionRequest, login_name string) error {
+	current, err := GetCatDescription(cat)
+	if err != nil {
+		return err
+	} else if current != nil && current.Text == request.Text {
+		return e.ErrCatDescriptionUnchanged
+	}
+
+	_, err = db.Client.Exec(
+		`INSERT INTO "Cat Description Revisions" (id, cat, text, edited_by, edited_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		request.ID,
+		query.GetSingularCat(strings.ToLower(cat)),
+		request.Text,
+		login_name,
+		request.EditedAt,
+	)
+
+	return err
+}
+
+func GetCatDescriptionRevisionsPage(cat string, opts *model.CatDescriptionRevisionsOptions) (*model.CatDescriptionRevisionsPage, error) {
+	revisions_sql, err := query.NewCatDescriptionRevisions(cat).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := revisions_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.CatDescriptionRevisionsPage{Revisions: []model.CatDescriptionRevision{}}
+	for rows.Next() {
+		var rev model.CatDescriptionRevision
+		if err := rows.Scan(
+			&rev.ID,
+			&rev.Cat,
+			&rev.Text,
+			&rev.EditedBy,
+			&rev.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		page.Revisions = append(page.Revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Revisions) > query.CAT_DESCRIPTION_REVISIONS_PAGE_LIMIT {
+		page.Revisions = page.Revisions[:query.CAT_DESCRIPTION_REVISIONS_PAGE_LIMIT]
+		page.NextPage = int(max(opts.Page, 1)) + 1
+	}
+
+	return page, nil
+}
+
+func GetMonthlyLinkCountsForCat(cat string) ([]model.MonthlyLinkCount, error) {
+	rows, err := query.NewMonthlyLinkCountsForCat(cat).ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []model.MonthlyLinkCount{}
+	for rows.Next() {
+		var mlc model.MonthlyLinkCount
+		if err := rows.Scan(&mlc.Month, &mlc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, mlc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
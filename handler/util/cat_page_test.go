@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestValidateCatPageCat(t *testing.T) {
+	var test_cats = []struct {
+		Cat         string
+		ExpectedErr error
+	}{
+		{"", e.ErrNoCat},
+		{"umvc3,flowers", e.ErrCatPageHasMultipleCats},
+		{"umvc3", nil},
+	}
+
+	for _, tc := range test_cats {
+		if err := ValidateCatPageCat(tc.Cat); err != tc.ExpectedErr {
+			t.Fatalf("cat %q: expected %v, got %v", tc.Cat, tc.ExpectedErr, err)
+		}
+	}
+}
+
+func TestGetCatPageSortByFromRequestParams(t *testing.T) {
+	var test_params = []struct {
+		SortByParams   string
+		ExpectedSortBy model.SortBy
+		ExpectedErr    error
+	}{
+		{"", "", nil},
+		{"newest", model.SortByNewest, nil},
+		{"hot", model.SortByHot, nil},
+		{"alphabetical", "", e.ErrInvalidSortByParams},
+	}
+
+	for _, tp := range test_params {
+		sort_by, err := GetCatPageSortByFromRequestParams(url.Values{
+			"sort_by": []string{tp.SortByParams},
+		})
+		if err != tp.ExpectedErr {
+			t.Fatalf("sort_by %q: expected error %v, got %v", tp.SortByParams, tp.ExpectedErr, err)
+		} else if sort_by != tp.ExpectedSortBy {
+			t.Fatalf("sort_by %q: expected %q, got %q", tp.SortByParams, tp.ExpectedSortBy, sort_by)
+		}
+	}
+}
+
+func TestGetCatPage(t *testing.T) {
+	for _, l := range []struct {
+		ID          string
+		SubmittedBy string
+		SubmitDate  string
+		GlobalCats  string
+	}{
+		{"cat-page-test-1", TEST_LOGIN_NAME, "2025-01-05", "catpagetestwidgets,catpagetestgears"},
+		{"cat-page-test-2", "bradley", "2025-03-10", "catpagetestwidget,catpagetestsprockets"},
+		{"cat-page-test-3", "bradley", "2025-03-20", "catpagetestgears"},
+	} {
+		if _, err := db.Client.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, ?, ?);`,
+			l.ID,
+			"https://"+l.ID+".com",
+			l.SubmittedBy,
+			l.SubmitDate,
+			l.GlobalCats,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		db.Client.Exec("DELETE FROM Links WHERE id LIKE 'cat-page-test-%';")
+		db.Client.Exec(`DELETE FROM "Cat Description Revisions" WHERE cat LIKE 'catpagetest%';`)
+	}()
+
+	// Description
+	edit := func(id string, text string, edited_at string) error {
+		return EditCatDescription("catpagetestwidgets", &model.EditCatDescriptionRequest{
+			ID:       id,
+			Text:     text,
+			EditedAt: edited_at,
+		}, TEST_LOGIN_NAME)
+	}
+	if err := edit("cat-page-test-rev-1", "Small mechanical things", "2025-04-01 00:00:00"); err != nil {
+		t.Fatal(err)
+	} else if err := edit("cat-page-test-rev-2", "Small mechanical things", "2025-04-02 00:00:00"); err != e.ErrCatDescriptionUnchanged {
+		t.Fatalf("expected ErrCatDescriptionUnchanged, got %v", err)
+	} else if err := edit("cat-page-test-rev-3", "Small mechanical parts", "2025-04-03 00:00:00"); err != nil {
+		t.Fatal(err)
+	}
+
+	// (singular variant shares the page)
+	page, err := GetCatPage[model.Link]("catpagetestwidget", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Description == nil {
+		t.Fatal("expected description")
+	} else if page.Description.Text != "Small mechanical parts" ||
+		page.Description.LastEditedBy != TEST_LOGIN_NAME ||
+		page.Description.RevisionCount != 2 {
+		t.Fatalf("got description %+v", page.Description)
+	}
+
+	if page.TopLinks == nil || page.TopLinks.Links == nil || len(*page.TopLinks.Links) != 2 {
+		t.Fatalf("expected 2 top links, got %+v", page.TopLinks)
+	}
+	for _, l := range *page.TopLinks.Links {
+		if l.ID == "cat-page-test-3" {
+			t.Fatal("got link without cat")
+		}
+	}
+
+	if page.TopContributors == nil || len(*page.TopContributors) != 2 {
+		t.Fatalf("expected 2 top contributors, got %+v", page.TopContributors)
+	}
+	for _, c := range *page.TopContributors {
+		if c.LinksSubmitted != 1 {
+			t.Fatalf("expected 1 link submitted by %s, got %d", c.LoginName, c.LinksSubmitted)
+		}
+	}
+
+	if page.CoOccurringCats == nil || len(*page.CoOccurringCats) != 2 {
+		t.Fatalf("expected 2 co-occurring cats, got %+v", page.CoOccurringCats)
+	}
+	for _, c := range *page.CoOccurringCats {
+		switch c.Category {
+		case "catpagetestgears", "catpagetestsprockets":
+			if c.Count != 1 {
+				t.Fatalf("expected %s count 1, got %d", c.Category, c.Count)
+			}
+		default:
+			t.Fatalf("unexpected co-occurring cat %s", c.Category)
+		}
+	}
+
+	expected_monthly_counts := []model.MonthlyLinkCount{
+		{Month: "2025-01", Count: 1},
+		{Month: "2025-03", Count: 1},
+	}
+	if len(page.MonthlyLinkCounts) != len(expected_monthly_counts) {
+		t.Fatalf("expected monthly counts %+v, got %+v", expected_monthly_counts, page.MonthlyLinkCounts)
+	}
+	for i, mlc := range page.MonthlyLinkCounts {
+		if mlc != expected_monthly_counts[i] {
+			t.Fatalf("expected monthly counts %+v, got %+v", expected_monthly_counts, page.MonthlyLinkCounts)
+		}
+	}
+
+	// Revisions, newest first
+	revisions, err := GetCatDescriptionRevisionsPage("catpagetestwidgets", &model.CatDescriptionRevisionsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(revisions.Revisions) != 2 ||
+		revisions.Revisions[0].ID != "cat-page-test-rev-3" ||
+		revisions.Revisions[1].ID != "cat-page-test-rev-1" ||
+		revisions.Revisions[0].Cat != "catpagetestwidget" {
+		t.Fatalf("got revisions %+v", revisions.Revisions)
+	} else if revisions.NextPage != 0 {
+		t.Fatalf("expected no next page, got %d", revisions.NextPage)
+	}
+
+	// No description
+	if description, err := GetCatDescription("catpagetestsprockets"); err != nil {
+		t.Fatal(err)
+	} else if description != nil {
+		t.Fatalf("expected no description, got %+v", description)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+// "cats" params, with cats also matching their descendants if
+// "descendants" params are "true"
+func parseCatQueryParams(params url.Values) (*query.CatQuery, error) {
+	cat_query, err := query.ParseCatQuery(params.Get("cats"))
+	if err != nil {
+		return nil, err
+	}
+
+	descendants_params := params.Get("descendants")
+	if descendants_params == "true" {
+		cat_query.IncludeDescendants()
+	} else if descendants_params != "false" && descendants_params != "" {
+		return nil, e.ErrInvalidDescendantsParams
+	}
+
+	return cat_query, nil
+}
+
+// Cats named in "cats" params, excluding NOT cats and prefixes (nil if the
+// params don't parse, since they are validated before this is needed)
+func GetCatsFromCatQueryParams(cats_params string) []string {
+	if cats_params == "" {
+		return nil
+	}
+	cat_query, err := query.ParseCatQuery(cats_params)
+	if err != nil {
+		return nil
+	}
+
+	return cat_query.Cats()
+}
+
+func ValidateCatParent(cat string, parent string) error {
+	if CatsResembleEachOther(cat, parent) {
+		return e.ErrCatIsOwnParent
+	} else if query.CatIsDescendantOf(parent, cat) {
+		return e.ErrCatParentIsDescendant
+	}
+
+	return nil
+}
+
+// Replaces cat's parent if it already has one
+func SetCatParent(request *model.SetCatParentRequest, admin_login_name string) error {
+	if _, err := db.Client.Exec(
+		`INSERT INTO "Cat Parents" (cat, parent, set_by, set_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(cat) DO UPDATE SET
+			parent = excluded.parent,
+			set_by = excluded.set_by,
+			set_at = excluded.set_at;`,
+		query.GetSingularCat(request.Cat),
+		request.Parent,
+		admin_login_name,
+		request.SetAt,
+	); err != nil {
+		return err
+	}
+
+	return query.LoadCatParents()
+}
+
+// e.ErrNoCatParent if cat has no parent
+func RemoveCatParent(cat string) error {
+	res, err := db.Client.Exec(
+		`DELETE FROM "Cat Parents" WHERE cat = ?;`,
+		query.GetSingularCat(strings.ToLower(cat)),
+	)
+	if err != nil {
+		return err
+	}
+	if rows_affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows_affected == 0 {
+		return e.ErrNoCatParent
+	}
+
+	return query.LoadCatParents()
+}
+
+// Cats are nested under their nearest ancestor in counts, or are roots
+// if none of their ancestors are. Order of counts is kept at each level.
+func GetCatCountTree(counts []model.CatCount) []model.CatCountTree {
+	parent_indexes := make([]int, len(counts))
+	for i, c := range counts {
+		parent_indexes[i] = -1
+		for _, ancestor := range query.GetCatAncestors(c.Category) {
+			if j := getCatCountIndex(counts, ancestor); j != -1 && j != i {
+				parent_indexes[i] = j
+				break
+			}
+		}
+	}
+
+	var build func(parent_index int) []model.CatCountTree
+	build = func(parent_index int) []model.CatCountTree {
+		nodes := []model.CatCountTree{}
+		for i, c := range counts {
+			if parent_indexes[i] == parent_index {
+				nodes = append(nodes, model.CatCountTree{
+					CatCount: c,
+					Children: build(i),
+				})
+			}
+		}
+		return nodes
+	}
+
+	return build(-1)
+}
+
+func getCatCountIndex(counts []model.CatCount, cat string) int {
+	for i, c := range counts {
+		if CatsResembleEachOther(c.Category, cat) {
+			return i
+		}
+	}
+	return -1
+}
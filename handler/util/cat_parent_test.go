@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestCatParents(t *testing.T) {
+	defer func() {
+		db.Client.Exec(`DELETE FROM "Cat Parents" WHERE cat LIKE 'cattreetest%';`)
+		query.LoadCatParents()
+	}()
+
+	set_parent := func(cat string, parent string) error {
+		if err := ValidateCatParent(cat, parent); err != nil {
+			return err
+		}
+		return SetCatParent(&model.SetCatParentRequest{
+			Cat:    cat,
+			Parent: parent,
+			SetAt:  "2025-01-01 00:00:00",
+		}, "admin")
+	}
+	for _, tc := range []struct {
+		Cat         string
+		Parent      string
+		ExpectedErr error
+	}{
+		{"cattreetestsqlite", "cattreetestdatabases", nil},
+		{"cattreetestdatabases", "cattreetestprogramming", nil},
+		// replaced below
+		{"cattreetestpostgres", "cattreetestprogramming", nil},
+		{"cattreetestpostgres", "cattreetestdatabases", nil},
+		{"cattreetestprogramming", "cattreetestprogrammings", e.ErrCatIsOwnParent},
+		{"cattreetestprogramming", "cattreetestsqlites", e.ErrCatParentIsDescendant},
+	} {
+		if err := set_parent(tc.Cat, tc.Parent); err != tc.ExpectedErr {
+			t.Fatalf("%s => %s: expected %v, got %v", tc.Cat, tc.Parent, tc.ExpectedErr, err)
+		}
+	}
+	if parent, ok := query.GetCatParent("cattreetestpostgres"); !ok || parent != "cattreetestdatabases" {
+		t.Fatalf("expected replaced parent cattreetestdatabases, got %q", parent)
+	}
+
+	// Filters
+	opts, err := GetTopLinksOptionsFromRequestParams(url.Values{
+		"cats":        {"cattreetestprogramming"},
+		"descendants": {"true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, descendant := range []string{`"cattreetestdatabase"`, `"cattreetestsqlite"`, `"cattreetestpostgre"`} {
+		if !slices.ContainsFunc(opts.CatFiltersWithSpellingVariants, func(arg string) bool {
+			return strings.Contains(arg, descendant)
+		}) {
+			t.Fatalf("expected %s in cat filters, got %v", descendant, opts.CatFiltersWithSpellingVariants)
+		}
+	}
+	if _, err := GetTopLinksOptionsFromRequestParams(url.Values{
+		"cats":        {"cattreetestprogramming"},
+		"descendants": {"yes"},
+	}); err != e.ErrInvalidDescendantsParams {
+		t.Fatalf("expected ErrInvalidDescendantsParams, got %v", err)
+	}
+
+	// Tree: nested under nearest ancestor in counts
+	tree := GetCatCountTree([]model.CatCount{
+		{Category: "cattreetestprogramming", Count: 5},
+		{Category: "cattreetestsqlite", Count: 3},
+		{Category: "cattreetestunrelated", Count: 2},
+		{Category: "cattreetestpostgres", Count: 1},
+	})
+	if len(tree) != 2 ||
+		tree[0].Category != "cattreetestprogramming" ||
+		tree[1].Category != "cattreetestunrelated" {
+		t.Fatalf("expected programming and unrelated roots, got %+v", tree)
+	} else if children := tree[0].Children; len(children) != 2 ||
+		children[0].Category != "cattreetestsqlite" ||
+		children[1].Category != "cattreetestpostgres" {
+		t.Fatalf("expected sqlite and postgres under programming, got %+v", children)
+	}
+
+	// Treasure Map counts roll up
+	links := []model.TmapLink{
+		{Link: model.Link{Cats: "cattreetestsqlite"}},
+		{Link: model.Link{Cats: "cattreetestpostgres,cattreetestdatabase"}},
+	}
+	counts := getCatCountsFromTmapLinks(&links, nil)
+	for cat, expected := range map[string]int32{
+		"cattreetestsqlite":      1,
+		"cattreetestpostgres":    1,
+		"cattreetestdatabase":    2,
+		"cattreetestprogramming": 2,
+	} {
+		i := slices.IndexFunc(*counts, func(c model.CatCount) bool {
+			return CatsResembleEachOther(c.Category, cat)
+		})
+		if i == -1 || (*counts)[i].Count != expected {
+			t.Fatalf("expected %s count %d, got %+v", cat, expected, *counts)
+		}
+	}
+
+	if err := RemoveCatParent("cattreetestsqlites"); err != nil {
+		t.Fatal(err)
+	} else if err := RemoveCatParent("cattreetestsqlite"); err != e.ErrNoCatParent {
+		t.Fatalf("expected ErrNoCatParent, got %v", err)
+	} else if ancestors := query.GetCatAncestors("cattreetestsqlite"); len(ancestors) != 0 {
+		t.Fatalf("expected no ancestors after removing parent, got %v", ancestors)
+	}
+}
+
+func TestGetCatsFromCatQueryParams(t *testing.T) {
+	var test_params = []struct {
+		CatsParams   string
+		ExpectedCats []string
+	}{
+		{"", nil},
+		{"umvc3,flowers", []string{"umvc3", "flowers"}},
+		// quoted commas are part of the phrase
+		{`"rock, paper, scissors",games`, []string{"rock, paper, scissors", "games"}},
+		{"go NOT orm", []string{"go"}},
+		{`"unterminated`, nil},
+	}
+
+	for _, tp := range test_params {
+		cats := GetCatsFromCatQueryParams(tp.CatsParams)
+		if !slices.Equal(cats, tp.ExpectedCats) {
+			t.Fatalf("params %q: expected %v, got %v", tp.CatsParams, tp.ExpectedCats, cats)
+		}
+	}
+}
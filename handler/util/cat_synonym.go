@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+// Synonym groups are 1 level deep: every synonym points directly to the
+// cat that the group is named for, so a cat can't be a synonym of a
+// synonym, and a cat that others are synonyms of can't become a synonym
+// itself
+func ValidateCatSynonym(cat string, synonym_of string) error {
+	switch {
+	case CatsResembleEachOther(cat, synonym_of):
+		return e.ErrCatsAlreadyMerged
+	case query.CatIsSynonymOfAnother(cat):
+		return e.ErrCatAlreadyHasSynonym
+	case query.CatHasSynonyms(cat):
+		return e.ErrCatHasSynonyms
+	case query.CatIsSynonymOfAnother(synonym_of):
+		return e.ErrSynonymOfIsAlreadySynonym
+	}
+
+	return nil
+}
+
+// e.ErrCatSynonymAlreadyProposed if the same synonym is pending review
+func CreateCatSynonym(request *model.NewCatSynonymRequest, login_name string) error {
+	var already_proposed bool
+	if err := db.Client.QueryRow(
+		`SELECT COUNT(*) > 0
+		FROM "Cat Synonyms"
+		WHERE cat = ? AND synonym_of = ? AND status = ?;`,
+		request.Cat,
+		request.SynonymOf,
+		model.CatSynonymPending,
+	).Scan(&already_proposed); err != nil {
+		return err
+	} else if already_proposed {
+		return e.ErrCatSynonymAlreadyProposed
+	}
+
+	_, err := db.Client.Exec(
+		`INSERT INTO "Cat Synonyms" (id, cat, synonym_of, proposed_by, proposed_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		request.ID,
+		request.Cat,
+		request.SynonymOf,
+		login_name,
+		request.ProposedAt,
+	)
+	return err
+}
+
+func GetCatSynonymsPage(opts *model.CatSynonymsOptions) (*model.CatSynonymsPage, error) {
+	synonyms_sql, err := query.NewCatSynonyms().FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := synonyms_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.CatSynonymsPage{Synonyms: []model.CatSynonym{}}
+	for rows.Next() {
+		var cs model.CatSynonym
+		if err := rows.Scan(
+			&cs.ID,
+			&cs.Cat,
+			&cs.SynonymOf,
+			&cs.ProposedBy,
+			&cs.ProposedAt,
+			&cs.Status,
+			&cs.ReviewedBy,
+			&cs.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		page.Synonyms = append(page.Synonyms, cs)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Synonyms) > query.CAT_SYNONYMS_PAGE_LIMIT {
+		page.Synonyms = page.Synonyms[:query.CAT_SYNONYMS_PAGE_LIMIT]
+		page.NextPage = int(max(opts.Page, 1)) + 1
+	}
+
+	return page, nil
+}
+
+// sql.ErrNoRows if no synonym with ID
+func GetCatSynonym(synonym_id string) (*model.CatSynonym, error) {
+	cs := &model.CatSynonym{}
+	if err := db.Client.QueryRow(
+		`SELECT id, cat, synonym_of, proposed_by, proposed_at, status, reviewed_by, reviewed_at
+		FROM "Cat Synonyms"
+		WHERE id = ?;`,
+		synonym_id,
+	).Scan(
+		&cs.ID,
+		&cs.Cat,
+		&cs.SynonymOf,
+		&cs.ProposedBy,
+		&cs.ProposedAt,
+		&cs.Status,
+		&cs.ReviewedBy,
+		&cs.ReviewedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// Approved synonyms are re-validated, since other synonyms may have been
+// approved since this one was proposed. Links already tagged with either
+// cat have their global cats recalculated so that the synonyms merge.
+func ReviewCatSynonym(synonym *model.CatSynonym, action model.CatSynonymAction, admin_login_name string) error {
+	if synonym.Status != model.CatSynonymPending {
+		return e.ErrCatSynonymAlreadyReviewed
+	}
+	if action == model.CatSynonymActionApprove {
+		if err := ValidateCatSynonym(synonym.Cat, synonym.SynonymOf); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Client.Exec(
+		`UPDATE "Cat Synonyms"
+		SET status = ?, reviewed_by = ?, reviewed_at = ?
+		WHERE id = ?;`,
+		action.Status(),
+		admin_login_name,
+		mutil.NEW_LONG_TIMESTAMP(),
+		synonym.ID,
+	); err != nil {
+		return err
+	}
+	if action != model.CatSynonymActionApprove {
+		return nil
+	}
+
+	if err := query.LoadCatSynonyms(); err != nil {
+		return err
+	}
+
+	return recalculateGlobalCatsForLinksWithCat(synonym.Cat)
+}
+
+// Any link tagged with cat (or its plural/singular variants or synonyms)
+// by anyone
+func recalculateGlobalCatsForLinksWithCat(cat string) error {
+	rows, err := db.Client.Query(
+		`SELECT DISTINCT link_id
+		FROM user_cats_fts
+		WHERE cats MATCH ?;`,
+		query.GetCatsOptionalPluralOrSingularForms([]string{cat})[0],
+	)
+	if err != nil {
+		return err
+	}
+
+	var link_ids []string
+	for rows.Next() {
+		var link_id string
+		if err := rows.Scan(&link_id); err != nil {
+			rows.Close()
+			return err
+		}
+		link_ids = append(link_ids, link_id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, link_id := range link_ids {
+		if err := CalculateAndSetGlobalCats(link_id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestCatSynonyms(t *testing.T) {
+	const test_link_id = "synonym-test"
+	if _, err := db.Client.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+		VALUES (?, 'https://synonym-test.com', 'bradley', '2025-01-01', 'synonymtestjs,synonymtestjavascript');`,
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	for login_name, cats := range map[string]string{
+		"bradley":       "synonymtestjs",
+		TEST_LOGIN_NAME: "synonymtestjavascript",
+	} {
+		if _, err := db.Client.Exec(
+			`INSERT INTO Tags (id, link_id, cats, submitted_by, last_updated)
+			VALUES (?, ?, ?, ?, '2025-01-01 00:00:00');`,
+			"synonym-test-"+login_name,
+			test_link_id,
+			cats,
+			login_name,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := IncrementSpellfixRanksForCats(nil, []string{"synonymtestjs", "synonymtestjavascript"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		db.Client.Exec("DELETE FROM Links WHERE id = ?;", test_link_id)
+		db.Client.Exec("DELETE FROM Tags WHERE link_id = ?;", test_link_id)
+		db.Client.Exec(`DELETE FROM "Cat Synonyms" WHERE cat LIKE 'synonymtest%';`)
+		db.Client.Exec("DELETE FROM global_cats_spellfix WHERE word LIKE 'synonymtest%';")
+		query.LoadCatSynonyms()
+	}()
+
+	new_synonym := func(id string, cat string, synonym_of string) error {
+		return CreateCatSynonym(&model.NewCatSynonymRequest{
+			ID:         id,
+			Cat:        cat,
+			SynonymOf:  synonym_of,
+			ProposedAt: "2025-01-02 00:00:00",
+		}, TEST_LOGIN_NAME)
+	}
+	if err := ValidateCatSynonym("synonymtestjs", "synonymtestjss"); err != e.ErrCatsAlreadyMerged {
+		t.Fatalf("expected ErrCatsAlreadyMerged, got %v", err)
+	} else if err := new_synonym("synonym-test-1", "synonymtestjs", "synonymtestjavascript"); err != nil {
+		t.Fatal(err)
+	} else if err := new_synonym("synonym-test-2", "synonymtestjs", "synonymtestjavascript"); err != e.ErrCatSynonymAlreadyProposed {
+		t.Fatalf("expected ErrCatSynonymAlreadyProposed, got %v", err)
+	}
+
+	pending, err := GetCatSynonymsPage(&model.CatSynonymsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(pending.Synonyms, func(cs model.CatSynonym) bool {
+		return cs.ID == "synonym-test-1"
+	})
+	if i == -1 {
+		t.Fatal("expected synonym-test-1 in pending synonyms")
+	}
+
+	// Approve: link's global cats merge
+	synonym, err := GetCatSynonym("synonym-test-1")
+	if err != nil {
+		t.Fatal(err)
+	} else if err := ReviewCatSynonym(synonym, model.CatSynonymActionApprove, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if synonym, err = GetCatSynonym("synonym-test-1"); err != nil {
+		t.Fatal(err)
+	} else if err := ReviewCatSynonym(synonym, model.CatSynonymActionReject, "admin"); err != e.ErrCatSynonymAlreadyReviewed {
+		t.Fatalf("expected ErrCatSynonymAlreadyReviewed, got %v", err)
+	}
+
+	var global_cats string
+	if err := db.Client.QueryRow(
+		"SELECT global_cats FROM Links WHERE id = ?;",
+		test_link_id,
+	).Scan(&global_cats); err != nil {
+		t.Fatal(err)
+	} else if global_cats != "synonymtestjavascript" {
+		t.Fatalf("expected synonyms merged into synonymtestjavascript, got %q", global_cats)
+	}
+
+	if !CatsResembleEachOther("SynonymTestJS", "synonymtestjavascripts") {
+		t.Fatal("expected synonyms to resemble each other")
+	}
+	merged_cats := getMergedCatSpellingVariantsInLinksFromCatFilters(
+		&[]model.Link{{Cats: "synonymtestjavascript,synonymtestweb"}},
+		[]string{"synonymtestjs"},
+	)
+	if !slices.Equal(merged_cats, []string{"synonymtestjavascript"}) {
+		t.Fatalf("expected synonymtestjavascript merged, got %v", merged_cats)
+	}
+
+	// Groups are 1 level deep
+	for _, tc := range []struct {
+		Cat         string
+		SynonymOf   string
+		ExpectedErr error
+	}{
+		{"synonymtestjs", "synonymtestecmascript", e.ErrCatAlreadyHasSynonym},
+		{"synonymtestjavascripts", "synonymtestweb", e.ErrCatHasSynonyms},
+		{"synonymtestes6", "synonymtestjs", e.ErrSynonymOfIsAlreadySynonym},
+		{"synonymtestecmascript", "synonymtestjavascript", nil},
+	} {
+		if err := ValidateCatSynonym(tc.Cat, tc.SynonymOf); err != tc.ExpectedErr {
+			t.Fatalf(
+				"%s => %s: expected %v, got %v",
+				tc.Cat,
+				tc.SynonymOf,
+				tc.ExpectedErr,
+				err,
+			)
+		}
+	}
+
+	// Reject: no effect on merging
+	if err := new_synonym("synonym-test-3", "synonymtestweb", "synonymtestjavascript"); err != nil {
+		t.Fatal(err)
+	} else if synonym, err = GetCatSynonym("synonym-test-3"); err != nil {
+		t.Fatal(err)
+	} else if err := ReviewCatSynonym(synonym, model.CatSynonymActionReject, "admin"); err != nil {
+		t.Fatal(err)
+	} else if CatsResembleEachOther("synonymtestweb", "synonymtestjavascript") {
+		t.Fatal("expected rejected synonym not to merge")
+	}
+
+	rejected, err := GetCatSynonymsPage(&model.CatSynonymsOptions{Status: model.CatSynonymRejected})
+	if err != nil {
+		t.Fatal(err)
+	} else if !slices.ContainsFunc(rejected.Synonyms, func(cs model.CatSynonym) bool {
+		return cs.ID == "synonym-test-3" && cs.ReviewedBy == "admin"
+	}) {
+		t.Fatalf("expected synonym-test-3 rejected by admin, got %v", rejected.Synonyms)
+	}
+}
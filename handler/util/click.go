@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+var Click_dedup_window = CLICK_DEDUP_WINDOW
+
+var bot_user_agent_regex = regexp.MustCompile(BOT_USER_AGENT_REGEX)
+
+func init() {
+	window_env := os.Getenv("MODEEP_CLICK_DEDUP_WINDOW")
+	if window_env == "" {
+		return
+	}
+
+	window, err := time.ParseDuration(window_env)
+	if err != nil || window < 0 {
+		log.Printf("Invalid $MODEEP_CLICK_DEDUP_WINDOW %q, using default %s", window_env, CLICK_DEDUP_WINDOW)
+		return
+	}
+	Click_dedup_window = window
+}
+
+// Port is dropped so that clicks from the same IP can be matched
+func GetClickIPAddr(remote_addr string) string {
+	host, _, err := net.SplitHostPort(remote_addr)
+	if err != nil {
+		return remote_addr
+	}
+	return host
+}
+
+// Clicks are recorded either way, but only qualified ones
+// (ClickQualified) are counted.
+// Anonymous clicks are matched by IP, others by user ID.
+func GetClickDisqualification(link_id string, user_id string, ip_addr string, user_agent string) (model.ClickDisqualification, error) {
+	if user_agent == "" {
+		return model.ClickBotUserAgent, nil
+	} else if bot_user_agent_regex.MatchString(user_agent) {
+		return model.ClickBotUserAgent, nil
+	}
+
+	clicker_condition, clicker_arg := "user_id = ?", user_id
+	if user_id == "anonymous" {
+		clicker_condition, clicker_arg = "user_id = 'anonymous' AND ip_addr = ?", ip_addr
+	}
+
+	// Click rate (on any links)
+	var recent_clicks int
+	if err := db.Client.QueryRow(
+		`SELECT COUNT(*)
+		FROM Clicks
+		WHERE `+clicker_condition+`
+		AND timestamp >= ?;`,
+		clicker_arg,
+		time.Now().Add(-BOT_CLICK_RATE_WINDOW).Format("2006-01-02 15:04:05"),
+	).Scan(&recent_clicks); err != nil {
+		return model.ClickQualified, err
+	}
+	if recent_clicks >= BOT_CLICK_RATE_LIMIT {
+		return model.ClickBotRate, nil
+	}
+
+	// Duplicate (of a click on this link that was counted)
+	var is_duplicate bool
+	if err := db.Client.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1
+			FROM Clicks
+			WHERE link_id = ?
+			AND `+clicker_condition+`
+			AND disqualified_reason = ''
+			AND timestamp >= ?
+		);`,
+		link_id,
+		clicker_arg,
+		time.Now().Add(-Click_dedup_window).Format("2006-01-02 15:04:05"),
+	).Scan(&is_duplicate); err != nil {
+		return model.ClickQualified, err
+	}
+	if is_duplicate {
+		return model.ClickDuplicate, nil
+	}
+
+	return model.ClickQualified, nil
+}
+
+// Defaults: daily buckets over all time
+func GetLinkClicksOptionsFromRequestParams(params url.Values) (*model.LinkClicksOptions, error) {
+	opts := &model.LinkClicksOptions{
+		Bucket: model.ClickBucketDay,
+		Period: model.PeriodAll,
+	}
+
+	bucket_params := params.Get("bucket")
+	if bucket_params != "" {
+		bucket := model.ClickBucket(bucket_params)
+		found := false
+		for _, b := range model.ValidClickBuckets {
+			if bucket == b {
+				opts.Bucket = bucket
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, e.ErrInvalidClickBucket
+		}
+	}
+	period_params := params.Get("period")
+	if period_params != "" {
+		period := model.Period(period_params)
+		if _, ok := model.ValidPeriodsInDays[period]; !ok {
+			return nil, e.ErrInvalidPeriod
+		}
+		opts.Period = period
+	}
+
+	// LinkID is added to opts directly in GetLinkClicks() handler
+	return opts, nil
+}
+
+func GetLinkClickStats(opts *model.LinkClicksOptions) (*model.LinkClickStats, error) {
+	stats := &model.LinkClickStats{
+		LinkID:   opts.LinkID,
+		Bucket:   opts.Bucket,
+		Period:   opts.Period,
+		Buckets:  []model.LinkClickBucketCounts{},
+		Surfaces: []model.LinkClickSurfaceCount{},
+	}
+
+	// Totals
+	counts_sql, err := query.NewLinkClickCounts(opts.LinkID).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	row, err := counts_sql.ValidateAndExecuteRow()
+	if err != nil {
+		return nil, err
+	}
+	if err := row.Scan(
+		&stats.Clicks,
+		&stats.UniqueUsers,
+		&stats.AnonymousIPs,
+	); err != nil {
+		return nil, err
+	}
+
+	// Buckets
+	buckets_sql, err := query.NewLinkClickBuckets(opts.LinkID).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := buckets_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.LinkClickBucketCounts
+		if err := rows.Scan(
+			&b.Start,
+			&b.Clicks,
+			&b.UniqueUsers,
+			&b.AnonymousIPs,
+		); err != nil {
+			return nil, err
+		}
+		stats.Buckets = append(stats.Buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Surfaces
+	surfaces_sql, err := query.NewLinkClickSurfaces(opts.LinkID).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	surface_rows, err := surfaces_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer surface_rows.Close()
+
+	for surface_rows.Next() {
+		var s model.LinkClickSurfaceCount
+		if err := surface_rows.Scan(&s.Surface, &s.Clicks); err != nil {
+			return nil, err
+		}
+		stats.Surfaces = append(stats.Surfaces, s)
+	}
+
+	return stats, surface_rows.Err()
+}
+
+func GetRawClicksPage(opts *model.RawClicksOptions) (*model.RawClicksPage, error) {
+	raw_clicks_sql, err := query.NewRawClicks().FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := raw_clicks_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.RawClicksPage{Clicks: []model.RawClick{}}
+	for rows.Next() {
+		var c model.RawClick
+		if err := rows.Scan(
+			&c.ID,
+			&c.LinkID,
+			&c.UserID,
+			&c.IPAddr,
+			&c.UserAgent,
+			&c.Timestamp,
+			&c.Surface,
+			&c.DisqualifiedReason,
+		); err != nil {
+			return nil, err
+		}
+		page.Clicks = append(page.Clicks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 1 extra row is queried to tell if there is a next page
+	if len(page.Clicks) > query.RAW_CLICKS_PAGE_LIMIT {
+		page.Clicks = page.Clicks[:query.RAW_CLICKS_PAGE_LIMIT]
+		page.NextPage = int(max(opts.Page, 1)) + 1
+	}
+
+	return page, nil
+}
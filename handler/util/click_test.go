@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestGetLinkClicksOptionsFromRequestParams(t *testing.T) {
+	var test_params = []struct {
+		Bucket         string
+		Period         string
+		ExpectedBucket model.ClickBucket
+		ExpectedPeriod model.Period
+		Valid          bool
+	}{
+		{"", "", model.ClickBucketDay, model.PeriodAll, true},
+		{"week", "", model.ClickBucketWeek, model.PeriodAll, true},
+		{"day", "month", model.ClickBucketDay, model.PeriodMonth, true},
+		{"month", "", "", "", false},
+		{"", "decade", "", "", false},
+	}
+
+	for _, tp := range test_params {
+		params := url.Values{}
+		if tp.Bucket != "" {
+			params.Set("bucket", tp.Bucket)
+		}
+		if tp.Period != "" {
+			params.Set("period", tp.Period)
+		}
+
+		opts, err := GetLinkClicksOptionsFromRequestParams(params)
+		if tp.Valid && err != nil {
+			t.Fatalf("unexpected error for %+v: %s", tp, err)
+		} else if !tp.Valid {
+			if err == nil {
+				t.Fatalf("expected error for %+v", tp)
+			}
+			continue
+		}
+		if opts.Bucket != tp.ExpectedBucket || opts.Period != tp.ExpectedPeriod {
+			t.Fatalf("expected bucket %s and period %s, got %+v", tp.ExpectedBucket, tp.ExpectedPeriod, opts)
+		}
+	}
+}
+
+func TestGetLinkClickStats(t *testing.T) {
+	test_link_id := "1"
+	test_clicks := []struct {
+		ID      string
+		UserID  string
+		IPAddr  string
+		Surface model.ClickSurface
+	}{
+		{"click-stats-test-a", TEST_USER_ID, "", model.ClickSurfaceTmap},
+		{"click-stats-test-b", "anonymous", "5.6.7.8:1234", model.ClickSurfaceTmap},
+	}
+	for _, c := range test_clicks {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp, surface)
+			VALUES (?, ?, ?, ?, datetime('now'), ?);`,
+			c.ID,
+			test_link_id,
+			c.UserID,
+			c.IPAddr,
+			c.Surface,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Clicks WHERE id = ?;", c.ID)
+	}
+
+	stats, err := GetLinkClickStats(&model.LinkClicksOptions{
+		LinkID: test_link_id,
+		Bucket: model.ClickBucketDay,
+		Period: model.PeriodWeek,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.LinkID != test_link_id || stats.Bucket != model.ClickBucketDay || stats.Period != model.PeriodWeek {
+		t.Fatalf("unexpected stats options: %+v", stats)
+	} else if stats.Clicks < len(test_clicks) || stats.UniqueUsers < 1 || stats.AnonymousIPs < 1 {
+		t.Fatalf("expected at least the test clicks to be counted, got %+v", stats.LinkClickCounts)
+	}
+
+	var bucket_clicks int
+	for _, b := range stats.Buckets {
+		bucket_clicks += b.Clicks
+	}
+	if bucket_clicks != stats.Clicks {
+		t.Fatalf("expected bucket clicks to sum to %d, got %d", stats.Clicks, bucket_clicks)
+	}
+
+	var surface_clicks, tmap_clicks int
+	for _, s := range stats.Surfaces {
+		surface_clicks += s.Clicks
+		if s.Surface == model.ClickSurfaceTmap {
+			tmap_clicks = s.Clicks
+		}
+	}
+	if surface_clicks != stats.Clicks {
+		t.Fatalf("expected surface clicks to sum to %d, got %d", stats.Clicks, surface_clicks)
+	} else if tmap_clicks < len(test_clicks) {
+		t.Fatalf("expected at least %d tmap clicks, got %d", len(test_clicks), tmap_clicks)
+	}
+}
+
+func TestGetClickIPAddr(t *testing.T) {
+	var test_addrs = []struct {
+		RemoteAddr     string
+		ExpectedIPAddr string
+	}{
+		{"1.2.3.4:5678", "1.2.3.4"},
+		{"[2001:db8::1]:443", "2001:db8::1"},
+		{"1.2.3.4", "1.2.3.4"},
+	}
+
+	for _, ta := range test_addrs {
+		if got := GetClickIPAddr(ta.RemoteAddr); got != ta.ExpectedIPAddr {
+			t.Fatalf("expected %s for %s, got %s", ta.ExpectedIPAddr, ta.RemoteAddr, got)
+		}
+	}
+}
+
+func TestGetClickDisqualification(t *testing.T) {
+	test_link_id := "1"
+	test_ip_addr := "203.0.113.7"
+	browser_user_agent := "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+	defer TestClient.Exec("DELETE FROM Clicks WHERE ip_addr = ?;", test_ip_addr)
+
+	insert_click := func(i int, reason model.ClickDisqualification) {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp, disqualified_reason)
+			VALUES (?, ?, 'anonymous', ?, datetime('now'), ?);`,
+			"disqualification-test-"+strconv.Itoa(i),
+			test_link_id,
+			test_ip_addr,
+			reason,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// User agents
+	for _, ua := range []string{
+		"",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"curl/8.5.0",
+		"python-requests/2.31.0",
+	} {
+		reason, err := GetClickDisqualification(test_link_id, "anonymous", test_ip_addr, ua)
+		if err != nil {
+			t.Fatal(err)
+		} else if reason != model.ClickBotUserAgent {
+			t.Fatalf("expected %s for user agent %q, got %q", model.ClickBotUserAgent, ua, reason)
+		}
+	}
+
+	// First click
+	reason, err := GetClickDisqualification(test_link_id, "anonymous", test_ip_addr, browser_user_agent)
+	if err != nil {
+		t.Fatal(err)
+	} else if reason != model.ClickQualified {
+		t.Fatalf("expected first click to qualify, got %q", reason)
+	}
+	insert_click(0, reason)
+
+	// Same IP within window
+	reason, err = GetClickDisqualification(test_link_id, "anonymous", test_ip_addr, browser_user_agent)
+	if err != nil {
+		t.Fatal(err)
+	} else if reason != model.ClickDuplicate {
+		t.Fatalf("expected %s, got %q", model.ClickDuplicate, reason)
+	}
+
+	// Different link, same IP: not a duplicate
+	reason, err = GetClickDisqualification("2", "anonymous", test_ip_addr, browser_user_agent)
+	if err != nil {
+		t.Fatal(err)
+	} else if reason != model.ClickQualified {
+		t.Fatalf("expected click on other link to qualify, got %q", reason)
+	}
+
+	// Too many clicks
+	for i := 1; i < BOT_CLICK_RATE_LIMIT; i++ {
+		insert_click(i, model.ClickDuplicate)
+	}
+	reason, err = GetClickDisqualification("2", "anonymous", test_ip_addr, browser_user_agent)
+	if err != nil {
+		t.Fatal(err)
+	} else if reason != model.ClickBotRate {
+		t.Fatalf("expected %s, got %q", model.ClickBotRate, reason)
+	}
+}
+
+func TestGetRawClicksPage(t *testing.T) {
+	page, err := GetRawClicksPage(&model.RawClicksOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(page.Clicks) > query.RAW_CLICKS_PAGE_LIMIT {
+		t.Fatalf("expected at most %d clicks, got %d", query.RAW_CLICKS_PAGE_LIMIT, len(page.Clicks))
+	}
+	for i := 1; i < len(page.Clicks); i++ {
+		if page.Clicks[i].Timestamp > page.Clicks[i-1].Timestamp {
+			t.Fatal("expected clicks sorted newest first")
+		}
+	}
+
+	page, err = GetRawClicksPage(&model.RawClicksOptions{LinkID: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range page.Clicks {
+		if c.LinkID != "2" {
+			t.Fatalf("expected only clicks on link 2, got %+v", c)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"database/sql"
+	"slices"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+const COLLECTION_FIELDS = `SELECT
+	c.id,
+	c.title,
+	c.description,
+	c.visibility,
+	c.created_by,
+	c.created_at,
+	c.last_updated,
+	(
+		SELECT COUNT(*)
+		FROM "Collection Items" ci
+		INNER JOIN "Public Links" l ON l.id = ci.link_id
+		WHERE ci.collection_id = c.id
+	) AS link_count
+FROM Collections c`
+
+// sql.ErrNoRows if no collection with ID
+func GetCollection(collection_id string) (*model.Collection, error) {
+	c := &model.Collection{}
+	if err := db.Client.QueryRow(
+		COLLECTION_FIELDS+`
+		WHERE c.id = ?;`,
+		collection_id,
+	).Scan(
+		&c.ID,
+		&c.Title,
+		&c.Description,
+		&c.Visibility,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.LastUpdated,
+		&c.LinkCount,
+	); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Private collections are only viewable by their owner
+func UserCanViewCollection(login_name string, collection *model.Collection) bool {
+	return collection.Visibility != model.CollectionPrivate ||
+		collection.CreatedBy == login_name
+}
+
+// Most recently updated first. Only public collections unless
+// include_hidden, e.g., for the owner.
+func GetCollectionsCreatedByUser(login_name string, include_hidden bool) (*[]model.Collection, error) {
+	collections_sql := COLLECTION_FIELDS + `
+	WHERE c.created_by = ?`
+	if !include_hidden {
+		collections_sql += `
+		AND c.visibility = 'public'`
+	}
+	collections_sql += `
+	ORDER BY c.last_updated DESC, c.id ASC;`
+
+	rows, err := db.Client.Query(collections_sql, login_name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []model.Collection{}
+	for rows.Next() {
+		var c model.Collection
+		if err := rows.Scan(
+			&c.ID,
+			&c.Title,
+			&c.Description,
+			&c.Visibility,
+			&c.CreatedBy,
+			&c.CreatedAt,
+			&c.LastUpdated,
+			&c.LinkCount,
+		); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+
+	return &collections, rows.Err()
+}
+
+func CreateCollection(request *model.NewCollectionRequest, login_name string) error {
+	_, err := db.Client.Exec(
+		`INSERT INTO Collections (id, title, description, visibility, created_by, created_at, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		request.ID,
+		request.Title,
+		request.Description,
+		request.Visibility,
+		login_name,
+		request.CreatedAt,
+		request.CreatedAt,
+	)
+	return err
+}
+
+func EditCollection(collection_id string, request *model.EditCollectionRequest) error {
+	_, err := db.Client.Exec(
+		`UPDATE Collections
+		SET title = ?, description = ?, visibility = ?, last_updated = ?
+		WHERE id = ?;`,
+		request.Title,
+		request.Description,
+		request.Visibility,
+		mutil.NEW_LONG_TIMESTAMP(),
+		collection_id,
+	)
+	return err
+}
+
+func DeleteCollection(collection_id string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`DELETE FROM "Collection Items" WHERE collection_id = ?;`,
+		collection_id,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM Collections WHERE id = ?;`,
+		collection_id,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Appended to the end of the collection (see MAX_COLLECTION_LINKS)
+func AddLinkToCollection(collection_id string, request *model.AddCollectionLinkRequest) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var last_position int
+	var already_added bool
+	if err := tx.QueryRow(
+		`SELECT
+			COALESCE(MAX(position), 0),
+			COALESCE(SUM(link_id = ?), 0) > 0
+		FROM "Collection Items"
+		WHERE collection_id = ?;`,
+		request.LinkID,
+		collection_id,
+	).Scan(&last_position, &already_added); err != nil {
+		return err
+	}
+	if already_added {
+		return e.ErrLinkAlreadyInCollection
+	}
+
+	now := mutil.NEW_LONG_TIMESTAMP()
+	if _, err := tx.Exec(
+		`INSERT INTO "Collection Items" (collection_id, link_id, position, note, added_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		collection_id,
+		request.LinkID,
+		last_position+1,
+		request.Note,
+		now,
+	); err != nil {
+		return err
+	}
+	if err := setCollectionLastUpdated(tx, collection_id, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func EditCollectionLinkNote(collection_id string, link_id string, note string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE "Collection Items" SET note = ? WHERE collection_id = ? AND link_id = ?;`,
+		note,
+		collection_id,
+		link_id,
+	)
+	if err != nil {
+		return err
+	} else if rows_affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows_affected == 0 {
+		return e.ErrLinkNotInCollection
+	}
+	if err := setCollectionLastUpdated(tx, collection_id, mutil.NEW_LONG_TIMESTAMP()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Positions of the remaining links are left as-is since only their
+// order matters
+func RemoveLinkFromCollection(collection_id string, link_id string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`DELETE FROM "Collection Items" WHERE collection_id = ? AND link_id = ?;`,
+		collection_id,
+		link_id,
+	)
+	if err != nil {
+		return err
+	} else if rows_affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows_affected == 0 {
+		return e.ErrLinkNotInCollection
+	}
+	if err := setCollectionLastUpdated(tx, collection_id, mutil.NEW_LONG_TIMESTAMP()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// link_ids must contain each link in the collection exactly once
+func ReorderCollectionLinks(collection_id string, link_ids []string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT link_id FROM "Collection Items" WHERE collection_id = ?;`,
+		collection_id,
+	)
+	if err != nil {
+		return err
+	}
+	var current_link_ids []string
+	for rows.Next() {
+		var link_id string
+		if err := rows.Scan(&link_id); err != nil {
+			rows.Close()
+			return err
+		}
+		current_link_ids = append(current_link_ids, link_id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sorted_link_ids := slices.Sorted(slices.Values(link_ids))
+	slices.Sort(current_link_ids)
+	if !slices.Equal(sorted_link_ids, current_link_ids) {
+		return e.ErrInvalidCollectionOrder
+	}
+
+	for i, link_id := range link_ids {
+		if _, err := tx.Exec(
+			`UPDATE "Collection Items" SET position = ? WHERE collection_id = ? AND link_id = ?;`,
+			i+1,
+			collection_id,
+			link_id,
+		); err != nil {
+			return err
+		}
+	}
+	if err := setCollectionLastUpdated(tx, collection_id, mutil.NEW_LONG_TIMESTAMP()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func setCollectionLastUpdated(tx *sql.Tx, collection_id string, last_updated string) error {
+	_, err := tx.Exec(
+		`UPDATE Collections SET last_updated = ? WHERE id = ?;`,
+		last_updated,
+		collection_id,
+	)
+	return err
+}
+
+func ScanCollectionLinks[T model.CollectionLink | model.CollectionLinkSignedIn](collection_links_sql *query.CollectionLinks) (*[]T, error) {
+	if collection_links_sql.Error != nil {
+		return nil, collection_links_sql.Error
+	}
+
+	rows, err := collection_links_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links any
+	var pages int
+
+	switch any(new(T)).(type) {
+	case *model.CollectionLink:
+		var signed_out_links = []model.CollectionLink{}
+		for rows.Next() {
+			l := model.CollectionLink{}
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+				&l.Position,
+				&l.Note,
+			); err != nil {
+				return nil, err
+			}
+			signed_out_links = append(signed_out_links, l)
+		}
+
+		links = &signed_out_links
+
+	case *model.CollectionLinkSignedIn:
+		var signed_in_links = []model.CollectionLinkSignedIn{}
+		for rows.Next() {
+			l := model.CollectionLinkSignedIn{}
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+				&l.StarsAssigned,
+				&l.Position,
+				&l.Note,
+			); err != nil {
+				return nil, err
+			}
+			signed_in_links = append(signed_in_links, l)
+		}
+
+		links = &signed_in_links
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links.(*[]T), nil
+}
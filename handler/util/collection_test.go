@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestCollections(t *testing.T) {
+	new_collection := &model.NewCollectionRequest{
+		ID:         "collection-test",
+		Title:      "Onboarding",
+		Visibility: model.CollectionUnlisted,
+		CreatedAt:  "2025-01-01 00:00:00",
+	}
+	if err := CreateCollection(new_collection, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteCollection(new_collection.ID)
+
+	// Add
+	for _, link_id := range []string{"1", "2"} {
+		if err := AddLinkToCollection(new_collection.ID, &model.AddCollectionLinkRequest{
+			LinkID: link_id,
+			Note:   "read " + link_id,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddLinkToCollection(new_collection.ID, &model.AddCollectionLinkRequest{
+		LinkID: "1",
+	}); err != e.ErrLinkAlreadyInCollection {
+		t.Fatalf("expected ErrLinkAlreadyInCollection, got %v", err)
+	}
+
+	// Edit note
+	if err := EditCollectionLinkNote(new_collection.ID, "2", "read first"); err != nil {
+		t.Fatal(err)
+	} else if err := EditCollectionLinkNote(new_collection.ID, "-1", ""); err != e.ErrLinkNotInCollection {
+		t.Fatalf("expected ErrLinkNotInCollection, got %v", err)
+	}
+
+	// Reorder
+	for _, invalid_order := range [][]string{
+		{"2"},
+		{"2", "1", "1"},
+		{"2", "-1"},
+	} {
+		if err := ReorderCollectionLinks(new_collection.ID, invalid_order); err != e.ErrInvalidCollectionOrder {
+			t.Fatalf("order %v: expected ErrInvalidCollectionOrder, got %v", invalid_order, err)
+		}
+	}
+	if err := ReorderCollectionLinks(new_collection.ID, []string{"2", "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := ScanCollectionLinks[model.CollectionLink](query.NewCollectionLinks(new_collection.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids, notes []string
+	for _, l := range *links {
+		ids = append(ids, l.ID)
+		notes = append(notes, l.Note)
+	}
+	if !slices.Equal(ids, []string{"2", "1"}) || !slices.Equal(notes, []string{"read first", "read 1"}) {
+		t.Fatalf("unexpected collection links %v with notes %v", ids, notes)
+	}
+	if _, err := ScanCollectionLinks[model.CollectionLinkSignedIn](
+		query.NewCollectionLinks(new_collection.ID).AsSignedInUser(TEST_USER_ID),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove
+	if err := RemoveLinkFromCollection(new_collection.ID, "2"); err != nil {
+		t.Fatal(err)
+	} else if err := RemoveLinkFromCollection(new_collection.ID, "2"); err != e.ErrLinkNotInCollection {
+		t.Fatalf("expected ErrLinkNotInCollection, got %v", err)
+	}
+	collection, err := GetCollection(new_collection.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if collection.LinkCount != 1 {
+		t.Fatalf("expected 1 link, got %d", collection.LinkCount)
+	}
+
+	// Visibility
+	if !UserCanViewCollection("", collection) {
+		t.Fatal("expected unlisted collection to be viewable")
+	}
+	public_collections, err := GetCollectionsCreatedByUser(TEST_LOGIN_NAME, false)
+	if err != nil {
+		t.Fatal(err)
+	} else if slices.ContainsFunc(*public_collections, func(c model.Collection) bool {
+		return c.ID == new_collection.ID
+	}) {
+		t.Fatal("unlisted collection returned as public")
+	}
+
+	if err := EditCollection(new_collection.ID, &model.EditCollectionRequest{
+		Title:      "Onboarding (private)",
+		Visibility: model.CollectionPrivate,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	collection, err = GetCollection(new_collection.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if UserCanViewCollection("", collection) || UserCanViewCollection("bradley", collection) {
+		t.Fatal("expected private collection to only be viewable by owner")
+	} else if !UserCanViewCollection(TEST_LOGIN_NAME, collection) {
+		t.Fatal("expected private collection to be viewable by owner")
+	}
+	all_collections, err := GetCollectionsCreatedByUser(TEST_LOGIN_NAME, true)
+	if err != nil {
+		t.Fatal(err)
+	} else if !slices.ContainsFunc(*all_collections, func(c model.Collection) bool {
+		return c.ID == new_collection.ID && c.Title == "Onboarding (private)"
+	}) {
+		t.Fatal("expected private collection to be returned for owner")
+	}
+
+	// Delete
+	if err := DeleteCollection(new_collection.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err := GetCollection(new_collection.ID); err == nil {
+		t.Fatal("expected deleted collection to be gone")
+	}
+	var items_count int
+	if err := TestClient.QueryRow(
+		`SELECT COUNT(*) FROM "Collection Items" WHERE collection_id = ?;`,
+		new_collection.ID,
+	).Scan(&items_count); err != nil {
+		t.Fatal(err)
+	} else if items_count != 0 {
+		t.Fatalf("expected deleted collection's items to be gone, got %d", items_count)
+	}
+}
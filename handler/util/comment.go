@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"database/sql"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+func GetCommentsPage(opts *model.CommentsOptions) (*model.CommentsPage, error) {
+	comments_sql, err := query.NewComments(opts.LinkID).FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := comments_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Replies are always ordered right after their parent
+	page := &model.CommentsPage{Comments: []model.CommentThread{}}
+	for rows.Next() {
+		var c model.Comment
+		var parent_id string
+		if err := rows.Scan(
+			&c.ID,
+			&parent_id,
+			&c.SubmittedBy,
+			&c.Text,
+			&c.SubmitDate,
+			&c.LastUpdated,
+			&c.IsDeleted,
+		); err != nil {
+			return nil, err
+		}
+		if c.IsDeleted {
+			c.SubmittedBy = model.DELETED_COMMENT_PLACEHOLDER
+			c.Text = model.DELETED_COMMENT_PLACEHOLDER
+		}
+
+		if parent_id == "" {
+			page.Comments = append(page.Comments, model.CommentThread{
+				Comment: c,
+				Replies: []model.Comment{},
+			})
+		} else {
+			thread := &page.Comments[len(page.Comments)-1]
+			thread.Replies = append(thread.Replies, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Comments) > query.COMMENTS_PAGE_LIMIT {
+		page.Comments = page.Comments[:query.COMMENTS_PAGE_LIMIT]
+		page.NextPage = int(max(opts.Page, 1)) + 1
+	}
+
+	return page, nil
+}
+
+// sql.ErrNoRows if no comment with ID or it was deleted
+func GetCommentSubmitter(comment_id string) (string, error) {
+	var submitted_by string
+	err := db.Client.QueryRow(
+		`SELECT submitted_by FROM Comments WHERE id = ? AND deleted = 0;`,
+		comment_id,
+	).Scan(&submitted_by)
+
+	return submitted_by, err
+}
+
+// Replies are only allowed to undeleted top-level comments on the same link
+func ValidateCommentParent(link_id string, parent_id string) error {
+	var grandparent_id sql.NullString
+	err := db.Client.QueryRow(
+		`SELECT parent_id FROM Comments WHERE id = ? AND link_id = ? AND deleted = 0;`,
+		parent_id,
+		link_id,
+	).Scan(&grandparent_id)
+	if err == sql.ErrNoRows {
+		return e.ErrParentCommentNotFound
+	} else if err != nil {
+		return err
+	} else if grandparent_id.Valid {
+		return e.ErrCannotReplyToReply
+	}
+
+	return nil
+}
+
+func CreateComment(request *model.NewCommentRequest, login_name string) error {
+	var parent_id sql.NullString
+	if request.ParentID != "" {
+		parent_id = sql.NullString{String: request.ParentID, Valid: true}
+	}
+
+	_, err := db.Client.Exec(
+		`INSERT INTO Comments (id, link_id, parent_id, submitted_by, text, submit_date, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		request.ID,
+		request.LinkID,
+		parent_id,
+		login_name,
+		request.Text,
+		request.SubmitDate,
+		request.SubmitDate,
+	)
+	return err
+}
+
+func EditComment(comment_id string, text string) error {
+	_, err := db.Client.Exec(
+		`UPDATE Comments SET text = ?, last_updated = ? WHERE id = ?;`,
+		text,
+		mutil.NEW_LONG_TIMESTAMP(),
+		comment_id,
+	)
+	return err
+}
+
+// Soft deleted so that any replies keep their place in the thread
+func DeleteComment(comment_id string) error {
+	_, err := db.Client.Exec(
+		`UPDATE Comments SET text = '', deleted = 1, last_updated = ? WHERE id = ?;`,
+		mutil.NEW_LONG_TIMESTAMP(),
+		comment_id,
+	)
+	return err
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestComments(t *testing.T) {
+	const test_link_id = "1"
+	defer TestClient.Exec("DELETE FROM Comments WHERE link_id = ?;", test_link_id)
+
+	new_comment := func(id string, parent_id string, submit_date string) {
+		if err := CreateComment(&model.NewCommentRequest{
+			ID:         id,
+			LinkID:     test_link_id,
+			ParentID:   parent_id,
+			Text:       "comment " + id,
+			SubmitDate: submit_date,
+		}, TEST_LOGIN_NAME); err != nil {
+			t.Fatal(err)
+		}
+	}
+	new_comment("comment-a", "", "2025-01-01 00:00:00")
+	new_comment("comment-b", "", "2025-01-02 00:00:00")
+	new_comment("comment-a-reply", "comment-a", "2025-01-03 00:00:00")
+
+	// Parent validation
+	var test_parents = []struct {
+		LinkID      string
+		ParentID    string
+		ExpectedErr error
+	}{
+		{test_link_id, "comment-a", nil},
+		{test_link_id, "comment-a-reply", e.ErrCannotReplyToReply},
+		{test_link_id, "comment-nonexistent", e.ErrParentCommentNotFound},
+		{"2", "comment-a", e.ErrParentCommentNotFound},
+	}
+	for _, tp := range test_parents {
+		if err := ValidateCommentParent(tp.LinkID, tp.ParentID); err != tp.ExpectedErr {
+			t.Fatalf("parent %s on link %s: expected %v, got %v", tp.ParentID, tp.LinkID, tp.ExpectedErr, err)
+		}
+	}
+
+	// Edit
+	if err := EditComment("comment-b", "edited"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete: a with a reply keeps a placeholder, b with none is omitted
+	for _, id := range []string{"comment-a", "comment-b"} {
+		if err := DeleteComment(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := GetCommentSubmitter("comment-a"); err == nil {
+		t.Fatal("expected deleted comment to have no submitter")
+	} else if err := ValidateCommentParent(test_link_id, "comment-a"); err != e.ErrParentCommentNotFound {
+		t.Fatalf("expected ErrParentCommentNotFound for deleted parent, got %v", err)
+	}
+
+	page, err := GetCommentsPage(&model.CommentsOptions{LinkID: test_link_id})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(page.Comments) != 1 {
+		t.Fatalf("expected 1 thread, got %d", len(page.Comments))
+	}
+	thread := page.Comments[0]
+	if thread.ID != "comment-a" ||
+		!thread.IsDeleted ||
+		thread.Text != model.DELETED_COMMENT_PLACEHOLDER ||
+		thread.SubmittedBy != model.DELETED_COMMENT_PLACEHOLDER {
+		t.Fatalf("expected deleted placeholder for comment-a, got %+v", thread.Comment)
+	} else if len(thread.Replies) != 1 || thread.Replies[0].ID != "comment-a-reply" {
+		t.Fatalf("expected comment-a-reply under comment-a, got %+v", thread.Replies)
+	}
+
+	// Comment count excludes deleted comments
+	link, err := ScanSingleLink[model.Link](query.NewSingleLink(test_link_id))
+	if err != nil {
+		t.Fatal(err)
+	} else if link.CommentCount != 1 {
+		t.Fatalf("expected comment count 1, got %d", link.CommentCount)
+	}
+}
+
+func TestGetCommentsPage(t *testing.T) {
+	const test_link_id = "2"
+	defer TestClient.Exec("DELETE FROM Comments WHERE link_id = ?;", test_link_id)
+
+	// 1 more thread than fits on a page, each with a reply
+	var thread_ids []string
+	for i := range query.COMMENTS_PAGE_LIMIT + 1 {
+		id := fmt.Sprintf("comments-page-test-%02d", i)
+		thread_ids = append(thread_ids, id)
+		for _, c := range []struct{ ID, ParentID string }{
+			{id, ""},
+			{id + "-reply", id},
+		} {
+			if err := CreateComment(&model.NewCommentRequest{
+				ID:         c.ID,
+				LinkID:     test_link_id,
+				ParentID:   c.ParentID,
+				Text:       "comment",
+				SubmitDate: "2025-01-01 00:00:00",
+			}, TEST_LOGIN_NAME); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var test_pages = []struct {
+		Page             uint
+		ExpectedIDs      []string
+		ExpectedNextPage int
+	}{
+		{0, thread_ids[:query.COMMENTS_PAGE_LIMIT], 2},
+		{2, thread_ids[query.COMMENTS_PAGE_LIMIT:], 0},
+		{3, nil, 0},
+	}
+
+	for _, tp := range test_pages {
+		page, err := GetCommentsPage(&model.CommentsOptions{
+			LinkID: test_link_id,
+			Page:   tp.Page,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		for _, thread := range page.Comments {
+			ids = append(ids, thread.ID)
+			if len(thread.Replies) != 1 || thread.Replies[0].ID != thread.ID+"-reply" {
+				t.Fatalf("page %d: unexpected replies for %s: %+v", tp.Page, thread.ID, thread.Replies)
+			}
+		}
+		if !slices.Equal(ids, tp.ExpectedIDs) {
+			t.Fatalf("page %d: expected %v, got %v", tp.Page, tp.ExpectedIDs, ids)
+		} else if page.NextPage != tp.ExpectedNextPage {
+			t.Fatalf("page %d: expected next page %d, got %d", tp.Page, tp.ExpectedNextPage, page.NextPage)
+		}
+	}
+}
@@ -3,12 +3,63 @@ package handler
 import "time"
 
 const (
+	// Frontend
+	MODEEP_URL = "https://modeep.org"
+
 	// Link
 	MAX_DAILY_SUBMITTED_LINKS = 50
 	MAX_PREVIEW_IMG_WIDTH_PX  = 200
 	MODEEP_BOT_USER_AGENT     = "Modeep-Bot (https://modeep.org/about/how#retrieving-metadata)"
 	YT_VID_URL_REGEX          = `^(https?:\/\/)?(www\.)?(youtube\.com|youtu\.be)\/.+`
 
+	// Related links: score = cats weight * share of cats in common
+	// + co-stars weight * share of the link's starrers who also starred
+	// the related link
+	RELATED_LINKS_LIMIT                   = 10
+	RELATED_LINKS_CATS_WEIGHT     float64 = 0.6
+	RELATED_LINKS_CO_STARS_WEIGHT float64 = 0.4
+
+	// Recommended links: cat affinity is the sum of a user's weighted
+	// stars, tags and clicks on links with a cat. score = affinity weight
+	// * the link's cats' affinity relative to the top cat (max 1)
+	// + quality weight * rating / 5
+	RECOMMENDED_LINKS_LIMIT                       = 10
+	RECOMMENDED_LINKS_AFFINITY_CATS_LIMIT         = 20
+	RECOMMENDED_LINKS_STAR_WEIGHT         float64 = 1
+	RECOMMENDED_LINKS_TAG_WEIGHT          float64 = 3
+	RECOMMENDED_LINKS_CLICK_WEIGHT        float64 = 0.5
+	RECOMMENDED_LINKS_AFFINITY_WEIGHT     float64 = 0.75
+	RECOMMENDED_LINKS_QUALITY_WEIGHT      float64 = 0.25
+
+	// Collection
+	MAX_COLLECTION_LINKS = 500
+
+	// Archive
+	MAX_ARCHIVE_SNAPSHOT_BYTES = 5 << 20
+
+	// Page content
+	MAX_PAGE_CONTENT_CHARS = 100_000
+
+	// Click
+	// (default, overridden by $MODEEP_CLICK_DEDUP_WINDOW)
+	CLICK_DEDUP_WINDOW        = 30 * time.Minute
+	BOT_CLICK_RATE_WINDOW     = time.Minute
+	BOT_CLICK_RATE_LIMIT  int = 20
+	BOT_USER_AGENT_REGEX      = `(?i)(bot|crawl|spider|slurp|scrape|curl|wget|httpie|python-requests|python-urllib|aiohttp|go-http-client|java/|okhttp|libwww|headless|phantomjs|lighthouse)`
+
+	// Import
+	MAX_IMPORT_FILE_BYTES       = 10 << 20
+	MAX_IMPORT_ITEMS            = 5000
+	IMPORT_ITEM_DELAY           = time.Second
+	IMPORT_FALLBACK_CAT         = "imported"
+	IMPORT_STARS          uint8 = 1
+
+	// Link health
+	LINK_HEALTH_CHECK_INTERVAL        = 7 * 24 * time.Hour
+	LINK_HEALTH_CHECKER_SLEEP         = time.Hour
+	LINK_HEALTH_CHECK_DELAY           = 2 * time.Second
+	LINK_DEAD_AFTER_FAILED_CHECKS int = 3
+
 	// Tag
 	PERCENT_OF_MAX_CAT_SCORE_NEEDED_FOR_ASSIGNMENT float32 = 25
 
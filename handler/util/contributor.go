@@ -15,9 +15,11 @@ func GetTopContributorsOptionsFromRequestParams(params url.Values) (*model.TopCo
 
 	cat_filters_params := params.Get("cats")
 	if cat_filters_params != "" {
-		opts.CatFiltersWithSpellingVariants = query.GetCatsOptionalPluralOrSingularForms(
-			strings.Split(cat_filters_params, ","),
-		)
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
 	}
 	neutered_params := params.Get("neutered")
 	if neutered_params != "" {
@@ -29,6 +31,11 @@ func GetTopContributorsOptionsFromRequestParams(params url.Values) (*model.TopCo
 	if summary_contains_params != "" {
 		opts.SummaryContains = summary_contains_params
 	}
+	// (whitespace-only would be an empty FTS5 MATCH, so is ignored)
+	content_contains_params := strings.TrimSpace(params.Get("content_contains"))
+	if content_contains_params != "" {
+		opts.ContentContains = content_contains_params
+	}
 	url_contains_params := params.Get("url_contains")
 	if url_contains_params != "" {
 		opts.URLContains = url_contains_params
@@ -37,6 +44,14 @@ func GetTopContributorsOptionsFromRequestParams(params url.Values) (*model.TopCo
 	if url_lacks_params != "" {
 		opts.URLLacks = url_lacks_params
 	}
+	domain_params := params.Get("domain")
+	if domain_params != "" {
+		domain, err := GetLinkDomain(domain_params)
+		if err != nil {
+			return nil, e.ErrInvalidDomain
+		}
+		opts.Domain = domain
+	}
 	period_params := params.Get("period")
 	if period_params != "" {
 		period := model.Period(period_params)
@@ -51,24 +66,36 @@ func GetTopContributorsOptionsFromRequestParams(params url.Values) (*model.TopCo
 }
 
 func ScanContributors(contributors_sql *query.Contributors) *[]model.Contributor {
-	rows, err := contributors_sql.ValidateAndExecuteRows()
+	contributors, err := scanContributors(contributors_sql)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	return contributors
+}
+
+// Like ScanContributors() but returns any error instead of exiting
+func scanContributors(contributors_sql *query.Contributors) (*[]model.Contributor, error) {
+	rows, err := contributors_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	contributors := []model.Contributor{}
 	for rows.Next() {
 		contributor := model.Contributor{}
-		err := rows.Scan(
+		if err := rows.Scan(
 			&contributor.LinksSubmitted,
 			&contributor.LoginName,
-		)
-		if err != nil {
-			log.Fatal(err)
+		); err != nil {
+			return nil, err
 		}
 		contributors = append(contributors, contributor)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return &contributors
+	return &contributors, nil
 }
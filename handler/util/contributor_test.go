@@ -41,6 +41,22 @@ func TestGetContributorsOptionsFromRequestParams(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
+
+	// Invalid cats query
+	if _, err := GetTopContributorsOptionsFromRequestParams(url.Values{
+		"cats": []string{"umvc3 AND"},
+	}); err == nil {
+		t.Fatal("expected error for invalid cats query")
+	}
+
+	// Whitespace-only content_contains ignored
+	if opts, err := GetTopContributorsOptionsFromRequestParams(url.Values{
+		"content_contains": []string{" \t "},
+	}); err != nil {
+		t.Fatal(err)
+	} else if opts.ContentContains != "" {
+		t.Fatalf("expected content_contains to be ignored, got %q", opts.ContentContains)
+	}
 }
 
 func TestNewTopContributors(t *testing.T) {
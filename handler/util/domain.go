@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Registrable domain (eTLD+1) of a URL, e.g., "github.com" for
+// "https://gist.github.com/x" and "example.co.uk" for
+// "www.example.co.uk". Stored as Links.domain for the domain filter and
+// /domains counts.
+// IP addresses and single-label hosts (e.g., "localhost") are returned
+// as-is since they have no public suffix.
+func GetLinkDomain(raw_url string) (string, error) {
+	raw_url = strings.TrimSpace(raw_url)
+	if !strings.Contains(raw_url, "://") {
+		raw_url = "https://" + raw_url
+	}
+
+	u, err := url.Parse(raw_url)
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", invalidURLError(raw_url)
+	} else if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return host, nil
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// host is itself a public suffix, e.g., "github.io"
+		return host, nil
+	}
+	return domain, nil
+}
+
+func GetTopDomainsOptionsFromRequestParams(params url.Values) (*model.TopDomainsOptions, error) {
+	opts := &model.TopDomainsOptions{}
+
+	cat_filters_params := params.Get("cats")
+	if cat_filters_params != "" {
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
+	}
+	neutered_params := params.Get("neutered")
+	if neutered_params != "" {
+		// Since we use IN, not FTS MATCH, spelling variants are not
+		// needed (and casing matters)
+		opts.NeuteredCatFilters = strings.Split(neutered_params, ",")
+	}
+	period_params := params.Get("period")
+	if period_params != "" {
+		period := model.Period(period_params)
+		if _, ok := model.ValidPeriodsInDays[period]; !ok {
+			return nil, e.ErrInvalidPeriod
+		}
+		opts.Period = period
+	}
+
+	return opts, nil
+}
+
+func ScanDomainCounts(domains_sql *query.TopDomains) (*[]model.DomainCount, error) {
+	rows, err := domains_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []model.DomainCount{}
+	for rows.Next() {
+		var c model.DomainCount
+		if err := rows.Scan(&c.Count, &c.Domain); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return &counts, rows.Err()
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestGetLinkDomain(t *testing.T) {
+	var test_urls = []struct {
+		URL            string
+		ExpectedDomain string
+	}{
+		{"https://github.com/julianlk522/modeep", "github.com"},
+		{"https://gist.github.com/x", "github.com"},
+		{"http://WWW.Example.COM/a", "example.com"},
+		{"example.com", "example.com"},
+		{"https://www.bbc.co.uk/news", "bbc.co.uk"},
+		{"https://notgithub.io", "notgithub.io"},
+		{"https://someone.github.io/blog", "someone.github.io"},
+		{"https://github.io", "github.io"},
+		{"https://example.com.:8080/a", "example.com"},
+		{"http://127.0.0.1:3000/a", "127.0.0.1"},
+		{"http://[2001:db8::1]/a", "2001:db8::1"},
+		{"http://localhost:8080", "localhost"},
+	}
+
+	for _, tu := range test_urls {
+		domain, err := GetLinkDomain(tu.URL)
+		if err != nil {
+			t.Fatalf("unexpected error for URL %s: %s", tu.URL, err)
+		} else if domain != tu.ExpectedDomain {
+			t.Fatalf("expected domain %s for URL %s, got %s", tu.ExpectedDomain, tu.URL, domain)
+		}
+	}
+
+	if _, err := GetLinkDomain("https://"); err == nil {
+		t.Fatal("expected error for URL without host")
+	}
+}
+
+func TestGetTopDomainsOptionsFromRequestParams(t *testing.T) {
+	opts, err := GetTopDomainsOptionsFromRequestParams(url.Values{
+		"cats":     []string{"umvc3 OR flowers"},
+		"neutered": []string{"test"},
+		"period":   []string{"month"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(opts.CatFiltersWithSpellingVariants) != 1 ||
+		len(opts.NeuteredCatFilters) != 1 ||
+		opts.Period != model.PeriodMonth {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	for _, params := range []url.Values{
+		{"cats": []string{"umvc3 OR"}},
+		{"period": []string{"decade"}},
+	} {
+		if _, err := GetTopDomainsOptionsFromRequestParams(params); err == nil {
+			t.Fatalf("expected error for params %v", params)
+		}
+	}
+}
+
+func TestScanDomainCounts(t *testing.T) {
+	counts, err := ScanDomainCounts(query.NewTopDomains())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(*counts); i++ {
+		if (*counts)[i].Count > (*counts)[i-1].Count {
+			t.Fatal("expected domains sorted by count")
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+// Only these are passed on to the top links / Treasure Map options
+// parsers: anything else (pagination, NSFW, etc.) is ignored
+var FEED_PARAMS = []string{
+	"cats",
+	"neutered",
+	"period",
+	"url_contains",
+	"sort_by",
+}
+
+// Feed readers expect the newest entries first
+func getFeedParams(params url.Values) url.Values {
+	feed_params := url.Values{}
+	for _, p := range FEED_PARAMS {
+		if v := params.Get(p); v != "" {
+			feed_params.Set(p, v)
+		}
+	}
+	if feed_params.Get("sort_by") == "" {
+		feed_params.Set("sort_by", string(model.SortByNewest))
+	}
+
+	return feed_params
+}
+
+// First page of top links
+func GetTopLinksFeed(params url.Values, self_link string) (*model.Feed, error) {
+	feed_params := getFeedParams(params)
+	opts, err := GetTopLinksOptionsFromRequestParams(feed_params)
+	if err != nil {
+		return nil, err
+	}
+	links_sql, err := query.NewTopLinks().FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	links_page, err := scanRawLinksPageData[model.Link](links_sql)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &model.Feed{
+		Title:    "Modeep: top links",
+		Link:     MODEEP_URL + "/top",
+		SelfLink: self_link,
+		Entries:  []model.FeedEntry{},
+	}
+	if cats := feed_params.Get("cats"); cats != "" {
+		feed.Title += " in " + strings.ReplaceAll(cats, ",", ", ")
+	}
+	feed_params.Del("sort_by")
+	if len(feed_params) > 0 {
+		feed.Link += "?" + feed_params.Encode()
+	}
+
+	if links_page == nil || links_page.Links == nil {
+		return feed, nil
+	}
+	paginateLinks(links_page.Links)
+	for _, l := range *links_page.Links {
+		feed.Entries = append(feed.Entries, model.FeedEntry{
+			LinkID:      l.ID,
+			URL:         l.URL,
+			SubmittedBy: l.SubmittedBy,
+			SubmitDate:  l.SubmitDate,
+			Cats:        l.Cats,
+			// (Link.Summary is the global summary)
+			Summary: l.Summary,
+		})
+	}
+
+	return feed, nil
+}
+
+// Links from all 3 sections, combined and re-sorted, limited to
+// LINKS_PAGE_LIMIT entries
+func GetTmapFeed(login_name string, params url.Values, self_link string) (*model.Feed, error) {
+	feed_params := getFeedParams(params)
+	opts, err := GetTmapOptionsFromRequestParams(feed_params)
+	if err != nil {
+		return nil, err
+	}
+	opts.OwnerLoginName = login_name
+
+	all_tmap_links, err := getAllTmapSectionsForOwnerFromOpts[model.TmapLink](login_name, opts)
+	if err != nil {
+		return nil, err
+	}
+	links := slices.Concat(
+		*all_tmap_links.Submitted,
+		*all_tmap_links.Starred,
+		*all_tmap_links.Tagged,
+	)
+	sortTmapLinksForFeed(links, opts.SortBy)
+	if len(links) > query.LINKS_PAGE_LIMIT {
+		links = links[:query.LINKS_PAGE_LIMIT]
+	}
+
+	feed := &model.Feed{
+		Title:    login_name + "'s Treasure Map",
+		Link:     MODEEP_URL + "/map/" + url.PathEscape(login_name),
+		SelfLink: self_link,
+		Entries:  []model.FeedEntry{},
+	}
+	if cats := feed_params.Get("cats"); cats != "" {
+		feed.Title += ": " + strings.ReplaceAll(cats, ",", ", ")
+	}
+	feed_params.Del("sort_by")
+	if len(feed_params) > 0 {
+		feed.Link += "?" + feed_params.Encode()
+	}
+
+	if len(links) == 0 {
+		return feed, nil
+	}
+
+	// TmapLink.Summary may be the owner's summary
+	link_ids := make([]string, len(links))
+	for i, l := range links {
+		link_ids[i] = l.ID
+	}
+	global_summaries, err := getGlobalSummaries(link_ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range links {
+		feed.Entries = append(feed.Entries, model.FeedEntry{
+			LinkID:      l.ID,
+			URL:         l.URL,
+			SubmittedBy: l.SubmittedBy,
+			SubmitDate:  l.SubmitDate,
+			Cats:        l.Cats,
+			Summary:     global_summaries[l.ID],
+		})
+	}
+
+	return feed, nil
+}
+
+// Each section is already sorted by the query, but the combined
+// sections must be sorted again
+func sortTmapLinksForFeed(links []model.TmapLink, sort_by model.SortBy) {
+	var cmp func(a, b model.TmapLink) int
+	switch sort_by {
+	case model.SortByTimesStarred:
+		cmp = func(a, b model.TmapLink) int {
+			return int(b.TimesStarred - a.TimesStarred)
+		}
+	case model.SortByAverageStars:
+		cmp = func(a, b model.TmapLink) int {
+			if a.AvgStars > b.AvgStars {
+				return -1
+			} else if a.AvgStars < b.AvgStars {
+				return 1
+			}
+			return 0
+		}
+	case model.SortByRating:
+		cmp = func(a, b model.TmapLink) int {
+			if a.Rating > b.Rating {
+				return -1
+			} else if a.Rating < b.Rating {
+				return 1
+			}
+			return 0
+		}
+	case model.SortByClicks:
+		cmp = func(a, b model.TmapLink) int {
+			return int(b.ClickCount - a.ClickCount)
+		}
+	case model.SortByOldest:
+		cmp = func(a, b model.TmapLink) int {
+			return strings.Compare(a.SubmitDate, b.SubmitDate)
+		}
+	// (summary likes aren't loaded with Treasure Map links)
+	case model.SortByHot:
+		cmp = func(a, b model.TmapLink) int {
+			a_score := query.HotScore(a.TimesStarred, a.AvgStars, a.ClickCount, 0, a.SubmitDate)
+			b_score := query.HotScore(b.TimesStarred, b.AvgStars, b.ClickCount, 0, b.SubmitDate)
+			if a_score > b_score {
+				return -1
+			} else if a_score < b_score {
+				return 1
+			}
+			return 0
+		}
+	// relevance has no effect without content_contains, which feeds
+	// don't accept
+	default:
+		cmp = func(a, b model.TmapLink) int {
+			return strings.Compare(b.SubmitDate, a.SubmitDate)
+		}
+	}
+
+	slices.SortStableFunc(links, cmp)
+}
+
+// Keyed by link ID
+func getGlobalSummaries(link_ids []string) (map[string]string, error) {
+	args := make([]any, len(link_ids))
+	for i, id := range link_ids {
+		args[i] = id
+	}
+	rows, err := db.Client.Query(
+		`SELECT id, COALESCE(global_summary, '')
+		FROM Links
+		WHERE id IN (?`+strings.Repeat(", ?", len(link_ids)-1)+`);`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	global_summaries := make(map[string]string, len(link_ids))
+	for rows.Next() {
+		var id, summary string
+		if err := rows.Scan(&id, &summary); err != nil {
+			return nil, err
+		}
+		global_summaries[id] = summary
+	}
+
+	return global_summaries, rows.Err()
+}
+
+func WriteFeed(w io.Writer, feed *model.Feed, format model.FeedFormat) error {
+	var doc any
+	switch format {
+	case model.FeedFormatAtom:
+		doc = newAtomFeed(feed)
+	case model.FeedFormatRSS:
+		doc = newRSSFeed(feed)
+	default:
+		return e.ErrInvalidFeedFormat
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Links have no titles, so URLs are used instead
+func getFeedEntryTitle(entry model.FeedEntry) string {
+	return entry.URL
+}
+
+// Stable across feeds, so readers don't show the same link twice
+func getFeedEntryID(entry model.FeedEntry) string {
+	return "tag:modeep.org,2024:link:" + entry.LinkID
+}
+
+func getFeedEntryTime(entry model.FeedEntry) time.Time {
+	t, err := mutil.ParseSubmitDate(entry.SubmitDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// Latest entry submit date, or now if no entries
+func getFeedUpdatedTime(feed *model.Feed) time.Time {
+	var updated time.Time
+	for _, entry := range feed.Entries {
+		if t := getFeedEntryTime(entry); t.After(updated) {
+			updated = t
+		}
+	}
+	if updated.IsZero() {
+		return time.Now().UTC()
+	}
+	return updated
+}
+
+func getFeedEntryCats(entry model.FeedEntry) []string {
+	var cats []string
+	for cat := range strings.SplitSeq(entry.Cats, ",") {
+		if cat = strings.TrimSpace(cat); cat != "" {
+			cats = append(cats, cat)
+		}
+	}
+	return cats
+}
+
+// ATOM
+// (RFC 4287)
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomContent   `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+func newAtomFeed(feed *model.Feed) *atomFeed {
+	af := &atomFeed{
+		Title:   feed.Title,
+		ID:      feed.SelfLink,
+		Updated: getFeedUpdatedTime(feed).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.SelfLink, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+
+	for _, entry := range feed.Entries {
+		date := getFeedEntryTime(entry).Format(time.RFC3339)
+		ae := atomEntry{
+			Title:     getFeedEntryTitle(entry),
+			ID:        getFeedEntryID(entry),
+			Link:      atomLink{Href: entry.URL, Rel: "alternate"},
+			Published: date,
+			Updated:   date,
+			Author:    atomAuthor{Name: entry.SubmittedBy},
+		}
+		for _, cat := range getFeedEntryCats(entry) {
+			ae.Categories = append(ae.Categories, atomCategory{Term: cat})
+		}
+		if entry.Summary != "" {
+			ae.Content = &atomContent{Type: "text", Text: entry.Summary}
+		}
+		af.Entries = append(af.Entries, ae)
+	}
+
+	return af
+}
+
+// RSS 2.0
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+func newRSSFeed(feed *model.Feed) *rssFeed {
+	rf := &rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Title + " on Modeep",
+			LastBuildDate: getFeedUpdatedTime(feed).Format(time.RFC1123Z),
+			AtomLink: atomLink{
+				Href: feed.SelfLink,
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+		},
+	}
+
+	for _, entry := range feed.Entries {
+		rf.Channel.Items = append(rf.Channel.Items, rssItem{
+			Title:       getFeedEntryTitle(entry),
+			Link:        entry.URL,
+			GUID:        rssGUID{Text: getFeedEntryID(entry)},
+			PubDate:     getFeedEntryTime(entry).Format(time.RFC1123Z),
+			Author:      entry.SubmittedBy,
+			Categories:  getFeedEntryCats(entry),
+			Description: entry.Summary,
+		})
+	}
+
+	return rf
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestGetFeedParams(t *testing.T) {
+	params := url.Values{}
+	params.Set("cats", "go,databases")
+	params.Set("period", "week")
+	params.Set("page", "2")
+	params.Set("include_nsfw", "true")
+
+	feed_params := getFeedParams(params)
+	if feed_params.Get("cats") != "go,databases" || feed_params.Get("period") != "week" {
+		t.Fatalf("expected cats and period to be kept, got %v", feed_params)
+	} else if feed_params.Has("page") || feed_params.Has("include_nsfw") {
+		t.Fatalf("expected page and include_nsfw to be dropped, got %v", feed_params)
+	} else if feed_params.Get("sort_by") != string(model.SortByNewest) {
+		t.Fatalf("expected default sort_by %s, got %s", model.SortByNewest, feed_params.Get("sort_by"))
+	}
+
+	params.Set("sort_by", string(model.SortByClicks))
+	if sort_by := getFeedParams(params).Get("sort_by"); sort_by != string(model.SortByClicks) {
+		t.Fatalf("expected sort_by %s, got %s", model.SortByClicks, sort_by)
+	}
+}
+
+func TestGetTopLinksFeed(t *testing.T) {
+	feed, err := GetTopLinksFeed(url.Values{}, "https://api.modeep.org/links.atom")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(feed.Entries) == 0 || len(feed.Entries) > query.LINKS_PAGE_LIMIT {
+		t.Fatalf("expected 1-%d entries, got %d", query.LINKS_PAGE_LIMIT, len(feed.Entries))
+	}
+	for i := 1; i < len(feed.Entries); i++ {
+		if feed.Entries[i].SubmitDate > feed.Entries[i-1].SubmitDate {
+			t.Fatalf("expected entries sorted newest first, got %+v", feed.Entries)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("cats", "umvc3")
+	feed, err = GetTopLinksFeed(params, "https://api.modeep.org/links.atom?cats=umvc3")
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasSuffix(feed.Title, "umvc3") {
+		t.Fatalf("expected cats in title, got %s", feed.Title)
+	} else if !strings.HasSuffix(feed.Link, "?cats=umvc3") {
+		t.Fatalf("expected cats in link, got %s", feed.Link)
+	}
+	for _, entry := range feed.Entries {
+		if !strings.Contains(strings.ToLower(entry.Cats), "umvc3") {
+			t.Fatalf("expected only entries with cat umvc3, got %+v", entry)
+		}
+	}
+
+	params.Set("sort_by", "invalid")
+	if _, err = GetTopLinksFeed(params, ""); err == nil {
+		t.Fatal("expected error for invalid sort_by")
+	}
+}
+
+func TestGetTmapFeed(t *testing.T) {
+	feed, err := GetTmapFeed(TEST_LOGIN_NAME, url.Values{}, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(feed.Entries) > query.LINKS_PAGE_LIMIT {
+		t.Fatalf("expected at most %d entries, got %d", query.LINKS_PAGE_LIMIT, len(feed.Entries))
+	}
+
+	for i, entry := range feed.Entries {
+		if i > 0 && entry.SubmitDate > feed.Entries[i-1].SubmitDate {
+			t.Fatalf("expected entries sorted newest first, got %+v", feed.Entries)
+		}
+
+		var global_summary string
+		if err := TestClient.QueryRow(
+			"SELECT COALESCE(global_summary, '') FROM Links WHERE id = ?;",
+			entry.LinkID,
+		).Scan(&global_summary); err != nil {
+			t.Fatal(err)
+		} else if entry.Summary != global_summary {
+			t.Fatalf("expected global summary %q for link %s, got %q", global_summary, entry.LinkID, entry.Summary)
+		}
+	}
+}
+
+func TestSortTmapLinksForFeed(t *testing.T) {
+	new_link := func(id string, submit_date string, times_starred int64, clicks int64, rating float32) model.TmapLink {
+		l := model.TmapLink{}
+		l.ID = id
+		l.SubmitDate = submit_date
+		l.TimesStarred = times_starred
+		l.ClickCount = clicks
+		l.Rating = rating
+		return l
+	}
+	links := []model.TmapLink{
+		new_link("a", "2025-01-01 00:00:00", 5, 0, 2.1),
+		new_link("b", "2025-03-01 00:00:00", 1, 9, 2.5),
+		new_link("c", "2025-02-01 00:00:00", 3, 4, 2.3),
+	}
+
+	var test_sorts = []struct {
+		SortBy      model.SortBy
+		ExpectedIDs string
+	}{
+		{"", "bca"},
+		{model.SortByNewest, "bca"},
+		{model.SortByOldest, "acb"},
+		{model.SortByTimesStarred, "acb"},
+		{model.SortByClicks, "bca"},
+		// all old, so mostly by points
+		{model.SortByHot, "acb"},
+		{model.SortByRating, "bca"},
+	}
+
+	for _, ts := range test_sorts {
+		sortTmapLinksForFeed(links, ts.SortBy)
+		var ids string
+		for _, l := range links {
+			ids += l.ID
+		}
+		if ids != ts.ExpectedIDs {
+			t.Fatalf("sort_by %q: expected order %s, got %s", ts.SortBy, ts.ExpectedIDs, ids)
+		}
+	}
+}
+
+func TestWriteFeed(t *testing.T) {
+	feed := &model.Feed{
+		Title:    "Modeep: top links in go, databases",
+		Link:     "https://modeep.org/top?cats=go%2Cdatabases",
+		SelfLink: "https://api.modeep.org/links.atom?cats=go,databases",
+		Entries: []model.FeedEntry{
+			{
+				LinkID:      "1",
+				URL:         "https://example.com/a?b=1&c=2",
+				SubmittedBy: TEST_LOGIN_NAME,
+				SubmitDate:  "2025-01-01T00:00:00Z",
+				Cats:        "go,databases",
+				Summary:     "<b>not</b> markup",
+			},
+			{
+				LinkID:      "2",
+				URL:         "https://example.com/b",
+				SubmittedBy: TEST_LOGIN_NAME,
+				SubmitDate:  "2025-02-01 00:00:00",
+				Cats:        "go",
+			},
+		},
+	}
+
+	// Atom
+	var buf bytes.Buffer
+	if err := WriteFeed(&buf, feed, model.FeedFormatAtom); err != nil {
+		t.Fatal(err)
+	}
+	var af atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &af); err != nil {
+		t.Fatal(err)
+	}
+	if af.Title != feed.Title || af.Updated != "2025-02-01T00:00:00Z" {
+		t.Fatalf("unexpected feed metadata: %+v", af)
+	} else if len(af.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(af.Entries))
+	}
+	entry := af.Entries[0]
+	if entry.Link.Href != feed.Entries[0].URL ||
+		entry.ID != "tag:modeep.org,2024:link:1" ||
+		entry.Published != "2025-01-01T00:00:00Z" ||
+		len(entry.Categories) != 2 ||
+		entry.Content == nil ||
+		entry.Content.Text != feed.Entries[0].Summary {
+		t.Fatalf("unexpected entry: %+v", entry)
+	} else if af.Entries[1].Content != nil {
+		t.Fatalf("expected no content for entry without summary, got %+v", af.Entries[1].Content)
+	}
+
+	// RSS
+	buf.Reset()
+	if err := WriteFeed(&buf, feed, model.FeedFormatRSS); err != nil {
+		t.Fatal(err)
+	}
+	var rf struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Link        string   `xml:"link"`
+				GUID        string   `xml:"guid"`
+				PubDate     string   `xml:"pubDate"`
+				Categories  []string `xml:"category"`
+				Description string   `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &rf); err != nil {
+		t.Fatal(err)
+	}
+	if rf.Version != "2.0" || rf.Channel.Title != feed.Title || len(rf.Channel.Items) != 2 {
+		t.Fatalf("unexpected RSS feed: %+v", rf)
+	}
+	item := rf.Channel.Items[0]
+	if item.Link != feed.Entries[0].URL ||
+		item.GUID != "tag:modeep.org,2024:link:1" ||
+		item.PubDate != "Wed, 01 Jan 2025 00:00:00 +0000" ||
+		len(item.Categories) != 2 ||
+		item.Description != feed.Entries[0].Summary {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+
+	if err := WriteFeed(&buf, feed, "json"); err == nil {
+		t.Fatal("expected error for invalid feed format")
+	}
+}
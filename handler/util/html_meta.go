@@ -4,6 +4,7 @@ package handler
 
 import (
 	"io"
+	"strings"
 
 	"golang.org/x/net/html"
 )
@@ -20,6 +21,7 @@ type HTMLMetadata struct {
 	TwitterTitle string
 	TwitterDesc  string
 	TwitterImage string
+	Canonical    string
 }
 
 func extractHTMLMetadata(resp io.Reader) (html_md HTMLMetadata) {
@@ -39,6 +41,10 @@ func extractHTMLMetadata(resp io.Reader) (html_md HTMLMetadata) {
 				title_tag = true
 			} else if t.Data == "meta" {
 				assignTokenPropertyToHTMLMeta(t, &html_md)
+			} else if t.Data == "link" && html_md.Canonical == "" {
+				if href, ok := extractCanonicalHrefFromToken(t); ok {
+					html_md.Canonical = href
+				}
 			}
 		case html.TextToken:
 			if title_tag {
@@ -112,3 +118,20 @@ func extractMetaPropertyFromToken(mp string, token html.Token) (content string,
 	ok = has_property_attr && has_content_attr
 	return
 }
+
+func extractCanonicalHrefFromToken(token html.Token) (href string, ok bool) {
+	is_canonical, has_href_attr := false, false
+
+	for _, attr := range token.Attr {
+		if attr.Key == "rel" && strings.EqualFold(strings.TrimSpace(attr.Val), "canonical") {
+			is_canonical = true
+		}
+		if attr.Key == "href" {
+			href = strings.TrimSpace(attr.Val)
+			has_href_attr = href != ""
+		}
+	}
+
+	ok = is_canonical && has_href_attr
+	return
+}
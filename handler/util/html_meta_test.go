@@ -113,6 +113,17 @@ func TestOGSiteName(t *testing.T) {
 	}
 }
 
+func TestCanonical(t *testing.T) {
+	canonical := "https://example.com/post"
+	mp := NewMockPage(`<html><head><link rel="stylesheet" href="/style.css"><link rel="canonical" href="` + canonical + `"></head></html>`)
+
+	html_md := extractHTMLMetadata(&mp)
+
+	if html_md.Canonical != canonical {
+		t.Error("Expected canonical to be", canonical, ", but was:", html_md.Canonical)
+	}
+}
+
 func TestExtractHTMLMetadata(t *testing.T) {
 	title := "foobar"
 	description := "boo far"
@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Items are stored as pending and processed later by RunImportJob.
+// e.ErrImportAlreadyInProgress if login_name already has an active job
+// (also enforced by a unique index, in case of concurrent uploads).
+func CreateImportJob(login_name string, format model.ImportFormat, bookmarks []model.ImportedBookmark) (string, error) {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var active_jobs_count int
+	if err = tx.QueryRow(
+		`SELECT COUNT(*)
+		FROM "Import Jobs"
+		WHERE submitted_by = ?
+		AND status IN (?, ?);`,
+		login_name,
+		model.ImportJobQueued,
+		model.ImportJobRunning,
+	).Scan(&active_jobs_count); err != nil {
+		return "", err
+	} else if active_jobs_count > 0 {
+		return "", e.ErrImportAlreadyInProgress
+	}
+
+	job_id := uuid.New().String()
+	if _, err = tx.Exec(
+		`INSERT INTO "Import Jobs" (id, submitted_by, format, status, created_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		job_id,
+		login_name,
+		format,
+		model.ImportJobQueued,
+		mutil.NEW_LONG_TIMESTAMP(),
+	); err != nil {
+		var sqlite_err sqlite3.Error
+		if errors.As(err, &sqlite_err) && sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return "", e.ErrImportAlreadyInProgress
+		}
+		return "", err
+	}
+
+	for i, b := range bookmarks {
+		if _, err = tx.Exec(
+			`INSERT INTO "Import Job Items" (job_id, position, url, cats, summary, status)
+			VALUES (?, ?, ?, ?, ?, ?);`,
+			job_id,
+			i,
+			b.URL,
+			b.Cats,
+			b.Summary,
+			model.ImportItemPending,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	return job_id, tx.Commit()
+}
+
+// Queued or running
+func UserHasActiveImportJob(login_name string) (bool, error) {
+	var count int
+	if err := db.Client.QueryRow(
+		`SELECT COUNT(*)
+		FROM "Import Jobs"
+		WHERE submitted_by = ?
+		AND status IN (?, ?);`,
+		login_name,
+		model.ImportJobQueued,
+		model.ImportJobRunning,
+	).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// For jobs interrupted by a restart. Meant to be called once from main(),
+// before any new jobs can be created. Each job runs in its own goroutine
+// (like new jobs do) so that one user's long import doesn't hold up others'.
+func ResumeImportJobs() {
+	rows, err := db.Client.Query(
+		`SELECT id
+		FROM "Import Jobs"
+		WHERE status IN (?, ?)
+		ORDER BY created_at ASC;`,
+		model.ImportJobQueued,
+		model.ImportJobRunning,
+	)
+	if err != nil {
+		log.Printf("Could not get unfinished import jobs: %s", err)
+		return
+	}
+
+	var job_ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Could not scan unfinished import job: %s", err)
+			continue
+		}
+		job_ids = append(job_ids, id)
+	}
+	rows.Close()
+
+	for _, id := range job_ids {
+		go RunImportJob(id)
+	}
+}
+
+// Processes each pending item in order (see processImportItem) then
+// marks the job finished, or failed if it can't continue (so that the user
+// can start another). Safe to call again on a partially processed job.
+//
+// Imports deliberately bypass MAX_DAILY_SUBMITTED_LINKS: they are instead
+// capped at MAX_IMPORT_ITEMS per job and one active job per user, and
+// links added by them are not counted toward the daily limit
+// (see UserHasSubmittedMaxDailyLinks).
+func RunImportJob(job_id string) {
+	var login_name, user_id string
+	if err := db.Client.QueryRow(
+		`SELECT ij.submitted_by, u.id
+		FROM "Import Jobs" ij
+		INNER JOIN Users u ON u.login_name = ij.submitted_by
+		WHERE ij.id = ?;`,
+		job_id,
+	).Scan(&login_name, &user_id); err != nil {
+		failImportJob(job_id, fmt.Errorf("could not start import: %w", err))
+		return
+	}
+
+	if _, err := db.Client.Exec(
+		`UPDATE "Import Jobs" SET status = ? WHERE id = ?;`,
+		model.ImportJobRunning,
+		job_id,
+	); err != nil {
+		failImportJob(job_id, fmt.Errorf("could not start import: %w", err))
+		return
+	}
+
+	for {
+		item, err := getNextPendingImportItem(job_id)
+		if err == sql.ErrNoRows {
+			break
+		} else if err != nil {
+			failImportJob(job_id, fmt.Errorf("could not get next item: %w", err))
+			return
+		}
+
+		processImportItem(item, login_name, user_id)
+		if err = saveImportItemResult(item); err != nil {
+			failImportJob(job_id, fmt.Errorf("could not save result for item %d: %w", item.Position, err))
+			return
+		}
+
+		time.Sleep(IMPORT_ITEM_DELAY)
+	}
+
+	if _, err := db.Client.Exec(
+		`UPDATE "Import Jobs" SET status = ?, finished_at = ? WHERE id = ?;`,
+		model.ImportJobFinished,
+		mutil.NEW_LONG_TIMESTAMP(),
+		job_id,
+	); err != nil {
+		log.Printf("Could not finish import job %s: %s", job_id, err)
+	}
+}
+
+// Remaining items are left pending
+func failImportJob(job_id string, job_err error) {
+	log.Printf("Import job %s failed: %s", job_id, job_err)
+	if _, err := db.Client.Exec(
+		`UPDATE "Import Jobs" SET status = ?, finished_at = ?, error = ? WHERE id = ?;`,
+		model.ImportJobFailed,
+		mutil.NEW_LONG_TIMESTAMP(),
+		job_err.Error(),
+		job_id,
+	); err != nil {
+		log.Printf("Could not mark import job %s failed: %s", job_id, err)
+	}
+}
+
+type pendingImportItem struct {
+	model.ImportJobItem
+	ID      int64
+	Summary string
+}
+
+func getNextPendingImportItem(job_id string) (*pendingImportItem, error) {
+	item := &pendingImportItem{}
+	if err := db.Client.QueryRow(
+		`SELECT id, position, url, cats, summary
+		FROM "Import Job Items"
+		WHERE job_id = ? AND status = ?
+		ORDER BY position ASC
+		LIMIT 1;`,
+		job_id,
+		model.ImportItemPending,
+	).Scan(
+		&item.ID,
+		&item.Position,
+		&item.URL,
+		&item.Cats,
+		&item.Summary,
+	); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func saveImportItemResult(item *pendingImportItem) error {
+	_, err := db.Client.Exec(
+		`UPDATE "Import Job Items"
+		SET status = ?, link_id = ?, starred = ?, tagged = ?, error = ?
+		WHERE id = ?;`,
+		item.Status,
+		item.LinkID,
+		item.Starred,
+		item.Tagged,
+		item.Error,
+		item.ID,
+	)
+	return err
+}
+
+// Same steps as AddLink, except that bookmarks already on Modeep are
+// starred and/or tagged by the importing user instead of failing
+func processImportItem(item *pendingImportItem, login_name string, user_id string) {
+	fail := func(err error) {
+		item.Status = model.ImportItemFailed
+		item.Error = err.Error()
+	}
+
+	request := &model.NewLinkRequest{
+		URL:     item.URL,
+		Cats:    item.Cats,
+		Summary: item.Summary,
+	}
+	if err := request.Validate(); err != nil {
+		fail(err)
+		return
+	}
+	request.LinkID = uuid.New().String()
+	request.SubmitDate = mutil.NEW_LONG_TIMESTAMP()
+
+	final_url, x_md, err := GetFinalURLAndExtraMetadata(request.URL)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	link_id, is_duplicate, err := GetDuplicateLinkID(final_url, x_md, login_name)
+	if err != nil {
+		fail(err)
+		return
+	} else if is_duplicate && link_id == "" {
+		// (can't tag or star a link the importer can't see)
+		fail(e.ErrDuplicateLinkNotVisible(final_url))
+		return
+	} else if is_duplicate {
+		if err = addImportItemToExistingLink(item, link_id, login_name, user_id); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	canonical_key, err := GetNewLinkCanonicalKey(final_url, x_md)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	new_link := &model.NewLink{
+		SubmittedBy:    login_name,
+		NewLinkRequest: request,
+	}
+	new_link.URL = final_url
+	if err = SaveNewLink(new_link, x_md, canonical_key, user_id); err != nil {
+		fail(err)
+		return
+	}
+
+	item.Status = model.ImportItemAdded
+	item.LinkID = new_link.LinkID
+}
+
+// Tag and star are added together in one tx, the same way AddTag and
+// StarLink add them
+func addImportItemToExistingLink(item *pendingImportItem, link_id string, login_name string, user_id string) error {
+	item.LinkID = link_id
+
+	has_tagged, err := UserHasTaggedLink(login_name, link_id)
+	if err != nil {
+		return err
+	}
+	should_tag := !has_tagged
+	should_star := !UserSubmittedLink(login_name, link_id) && !UserHasStarredLink(user_id, link_id)
+
+	if !should_tag && !should_star {
+		item.Status = model.ImportItemSkipped
+		return nil
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if should_tag {
+		// (cats already validated along with the rest of the item)
+		cats := mutil.CapitalizeNSFWCatIfNotAlready(item.Cats)
+		tag := &model.NewTagRequest{
+			NewTag: &model.NewTag{
+				LinkID: link_id,
+				Cats:   mutil.TrimExcessAndTrailingSpaces(cats),
+			},
+			ID:          uuid.New().String(),
+			LastUpdated: mutil.NEW_LONG_TIMESTAMP(),
+		}
+		if err = SaveNewTag(tx, tag, login_name); err != nil {
+			return err
+		}
+	}
+	if should_star {
+		if err = SaveNewStar(tx, link_id, user_id, IMPORT_STARS); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	item.Tagged = should_tag
+	item.Starred = should_star
+	item.Status = model.ImportItemExisting
+
+	return nil
+}
+
+// Returns sql.ErrNoRows if no job with ID
+func GetImportJob(job_id string) (*model.ImportJob, error) {
+	job := &model.ImportJob{
+		Counts: map[model.ImportItemStatus]int{},
+		Items:  []model.ImportJobItem{},
+	}
+	var finished_at sql.NullString
+	if err := db.Client.QueryRow(
+		`SELECT id, submitted_by, format, status, created_at, finished_at, error
+		FROM "Import Jobs"
+		WHERE id = ?;`,
+		job_id,
+	).Scan(
+		&job.ID,
+		&job.SubmittedBy,
+		&job.Format,
+		&job.Status,
+		&job.CreatedAt,
+		&finished_at,
+		&job.Error,
+	); err != nil {
+		return nil, err
+	}
+	job.FinishedAt = finished_at.String
+
+	rows, err := db.Client.Query(
+		`SELECT position, url, cats, status, COALESCE(link_id, ''), starred, tagged, error
+		FROM "Import Job Items"
+		WHERE job_id = ?
+		ORDER BY position ASC;`,
+		job_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.ImportJobItem
+		if err := rows.Scan(
+			&item.Position,
+			&item.URL,
+			&item.Cats,
+			&item.Status,
+			&item.LinkID,
+			&item.Starred,
+			&item.Tagged,
+			&item.Error,
+		); err != nil {
+			return nil, err
+		}
+		job.Items = append(job.Items, item)
+		job.Counts[item.Status]++
+	}
+	job.ItemsCount = len(job.Items)
+
+	return job, rows.Err()
+}
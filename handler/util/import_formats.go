@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Guesses format from the first non-whitespace bytes of an export file
+func DetectImportFormat(content []byte) (model.ImportFormat, error) {
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case len(trimmed) == 0:
+		return "", e.ErrNoBookmarksFound
+	case trimmed[0] == '<':
+		return model.ImportFormatNetscape, nil
+	case trimmed[0] == '[':
+		return model.ImportFormatPinboard, nil
+	}
+
+	first_line, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if bytes.Contains(bytes.ToLower(first_line), []byte("url")) &&
+		bytes.Contains(first_line, []byte(",")) {
+		return model.ImportFormatPocket, nil
+	}
+
+	return "", e.ErrUndetectedImportFormat
+}
+
+func ParseBookmarks(content []byte, format model.ImportFormat) ([]model.ImportedBookmark, error) {
+	switch format {
+	case model.ImportFormatNetscape:
+		return parseNetscapeBookmarks(bytes.NewReader(content))
+	case model.ImportFormatPinboard:
+		return parsePinboardBookmarks(content)
+	case model.ImportFormatPocket:
+		return parsePocketBookmarks(bytes.NewReader(content))
+	default:
+		return nil, e.ErrInvalidImportFormat
+	}
+}
+
+// Top-level folders created by browsers themselves, which say nothing
+// about what a bookmark is about
+var BROWSER_ROOT_BOOKMARK_FOLDERS = []string{
+	"bookmarks bar",
+	"bookmarks menu",
+	"bookmarks toolbar",
+	"favorites bar",
+	"mobile bookmarks",
+	"other bookmarks",
+}
+
+// Netscape bookmark file (exported by all major browsers): each folder
+// containing a bookmark, and its TAGS attribute if any, become cats.
+// <DD> descriptions become summaries.
+func parseNetscapeBookmarks(r io.Reader) ([]model.ImportedBookmark, error) {
+	tokenizer := html.NewTokenizer(r)
+
+	var (
+		bookmarks []model.ImportedBookmark
+		// folder names of each open <DL>
+		folders []string
+		// name of the most recent <H3>, applied to the next <DL>
+		next_folder string
+
+		in_folder_name bool
+		in_description bool
+		description    strings.Builder
+	)
+
+	finish_description := func() {
+		if in_description && len(bookmarks) > 0 {
+			bookmarks[len(bookmarks)-1].Summary = getImportedSummary(description.String())
+		}
+		in_description = false
+		description.Reset()
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			finish_description()
+			if tokenizer.Err() == io.EOF {
+				return bookmarks, nil
+			}
+			return nil, tokenizer.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, has_attr := tokenizer.TagName()
+			switch string(name) {
+			case "h3":
+				finish_description()
+				in_folder_name = true
+				next_folder = ""
+			case "dl":
+				finish_description()
+				if slices.Contains(
+					BROWSER_ROOT_BOOKMARK_FOLDERS,
+					strings.ToLower(strings.TrimSpace(next_folder)),
+				) {
+					next_folder = ""
+				}
+				folders = append(folders, next_folder)
+				next_folder = ""
+			case "dt":
+				finish_description()
+			case "dd":
+				in_description = true
+			case "a":
+				finish_description()
+				var href string
+				var tags []string
+				for has_attr {
+					var key, val []byte
+					key, val, has_attr = tokenizer.TagAttr()
+					switch string(key) {
+					case "href":
+						href = string(val)
+					case "tags":
+						tags = strings.Split(string(val), ",")
+					}
+				}
+				if href == "" || !isImportableURL(href) {
+					continue
+				}
+
+				labels := slices.Clone(folders)
+				labels = append(labels, tags...)
+				bookmarks = append(bookmarks, model.ImportedBookmark{
+					URL:  href,
+					Cats: getImportedCats(labels),
+				})
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "h3":
+				in_folder_name = false
+			case "dl":
+				finish_description()
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			}
+
+		case html.TextToken:
+			if in_folder_name {
+				next_folder += string(tokenizer.Text())
+			} else if in_description {
+				description.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+type pinboardBookmark struct {
+	Href     string `json:"href"`
+	Extended string `json:"extended"`
+	Tags     string `json:"tags"`
+}
+
+// Pinboard JSON export (also produced by many other services): tags are
+// space-separated and the "extended" notes become summaries
+func parsePinboardBookmarks(content []byte) ([]model.ImportedBookmark, error) {
+	var pinboard_bookmarks []pinboardBookmark
+	if err := json.Unmarshal(content, &pinboard_bookmarks); err != nil {
+		return nil, err
+	}
+
+	bookmarks := make([]model.ImportedBookmark, 0, len(pinboard_bookmarks))
+	for _, pb := range pinboard_bookmarks {
+		if !isImportableURL(pb.Href) {
+			continue
+		}
+		bookmarks = append(bookmarks, model.ImportedBookmark{
+			URL:     pb.Href,
+			Cats:    getImportedCats(strings.Fields(pb.Tags)),
+			Summary: getImportedSummary(pb.Extended),
+		})
+	}
+
+	return bookmarks, nil
+}
+
+// Pocket CSV export: columns title,url,time_added,tags,status with
+// tags separated by "|". Columns are found by header name.
+func parsePocketBookmarks(r io.Reader) ([]model.ImportedBookmark, error) {
+	csv_reader := csv.NewReader(r)
+	csv_reader.FieldsPerRecord = -1
+
+	header, err := csv_reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	url_col, tags_col := -1, -1
+	for i, col := range header {
+		switch strings.ToLower(strings.TrimSpace(col)) {
+		case "url":
+			url_col = i
+		case "tags":
+			tags_col = i
+		}
+	}
+	if url_col == -1 {
+		return nil, e.ErrUndetectedImportFormat
+	}
+
+	var bookmarks []model.ImportedBookmark
+	for {
+		record, err := csv_reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if url_col >= len(record) || !isImportableURL(record[url_col]) {
+			continue
+		}
+		var labels []string
+		if tags_col != -1 && tags_col < len(record) {
+			labels = strings.Split(record[tags_col], "|")
+		}
+		bookmarks = append(bookmarks, model.ImportedBookmark{
+			URL:  strings.TrimSpace(record[url_col]),
+			Cats: getImportedCats(labels),
+		})
+	}
+
+	return bookmarks, nil
+}
+
+// Skips browser-internal and script bookmarks (place:, javascript:, etc.)
+func isImportableURL(url string) bool {
+	url = strings.ToLower(strings.TrimSpace(url))
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// Folder names and tags are mapped leniently so that one odd label
+// doesn't fail an entire item: commas are removed, labels too long to be
+// cats are dropped, duplicates are removed, and only the first
+// CATS_PER_LINK_LIMIT are kept.
+// Returns "" if no usable labels (caller applies a fallback).
+func getImportedCats(labels []string) string {
+	var cats []string
+	for _, label := range labels {
+		cat := strings.Join(strings.Fields(strings.ReplaceAll(label, ",", " ")), " ")
+		if cat == "" || len(cat) > mutil.CAT_CHAR_LIMIT {
+			continue
+		}
+		if slices.ContainsFunc(cats, func(c string) bool {
+			return strings.EqualFold(c, cat)
+		}) {
+			continue
+		}
+
+		cats = append(cats, cat)
+		if len(cats) == mutil.CATS_PER_LINK_LIMIT {
+			break
+		}
+	}
+
+	return strings.Join(cats, ",")
+}
+
+// Truncated to SUMMARY_CHAR_LIMIT on a word boundary
+func getImportedSummary(text string) string {
+	summary := strings.Join(strings.Fields(text), " ")
+	if len(summary) <= mutil.SUMMARY_CHAR_LIMIT {
+		return summary
+	}
+
+	summary = summary[:mutil.SUMMARY_CHAR_LIMIT]
+	if i := strings.LastIndexByte(summary, ' '); i > 0 {
+		summary = summary[:i]
+	}
+
+	return summary
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	var test_contents = []struct {
+		Content        string
+		ExpectedFormat model.ImportFormat
+		Valid          bool
+	}{
+		{"<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<DL><p></DL>", model.ImportFormatNetscape, true},
+		{`  [{"href": "https://a.com"}]`, model.ImportFormatPinboard, true},
+		{"title,url,time_added,tags,status\nA,https://a.com,1,,unread", model.ImportFormatPocket, true},
+		{"just some text", "", false},
+		{"   ", "", false},
+	}
+
+	for _, tc := range test_contents {
+		format, err := DetectImportFormat([]byte(tc.Content))
+		if tc.Valid && err != nil {
+			t.Fatalf("unexpected error for %q: %s", tc.Content, err)
+		} else if !tc.Valid && err == nil {
+			t.Fatalf("expected error for %q, got format %s", tc.Content, format)
+		} else if format != tc.ExpectedFormat {
+			t.Fatalf("expected format %s for %q, got %s", tc.ExpectedFormat, tc.Content, format)
+		}
+	}
+}
+
+func TestParseNetscapeBookmarks(t *testing.T) {
+	content := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/" ADD_DATE="1700000000">Go</A>
+        <DT><H3>Programming</H3>
+        <DL><p>
+            <DT><A HREF="https://www.rust-lang.org/" TAGS="rust,systems">Rust</A>
+            <DD>A language empowering everyone &amp; more
+            <DT><A HREF="javascript:void(0)">Bookmarklet</A>
+        </DL><p>
+        <DT><A HREF="http://example.com/after">After folder</A>
+    </DL><p>
+    <DT><A HREF="place:sort=8">Recent</A>
+</DL><p>`
+
+	bookmarks, err := ParseBookmarks([]byte(content), model.ImportFormatNetscape)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.ImportedBookmark{
+		{URL: "https://go.dev/"},
+		{
+			URL:     "https://www.rust-lang.org/",
+			Cats:    "Programming,rust,systems",
+			Summary: "A language empowering everyone & more",
+		},
+		{URL: "http://example.com/after"},
+	}
+	if len(bookmarks) != len(expected) {
+		t.Fatalf("expected %d bookmarks, got %d: %+v", len(expected), len(bookmarks), bookmarks)
+	}
+	for i := range expected {
+		if bookmarks[i] != expected[i] {
+			t.Fatalf("bookmark %d: expected %+v, got %+v", i, expected[i], bookmarks[i])
+		}
+	}
+}
+
+func TestParsePinboardBookmarks(t *testing.T) {
+	content := `[
+		{"href": "https://pinboard.in/", "description": "Pinboard", "extended": "  bookmarking   site ", "tags": "bookmarks tools"},
+		{"href": "ftp://old.example.com", "tags": ""},
+		{"href": "https://no-tags.example.com", "tags": ""}
+	]`
+
+	bookmarks, err := ParseBookmarks([]byte(content), model.ImportFormatPinboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.ImportedBookmark{
+		{URL: "https://pinboard.in/", Cats: "bookmarks,tools", Summary: "bookmarking site"},
+		{URL: "https://no-tags.example.com"},
+	}
+	if len(bookmarks) != len(expected) {
+		t.Fatalf("expected %d bookmarks, got %d: %+v", len(expected), len(bookmarks), bookmarks)
+	}
+	for i := range expected {
+		if bookmarks[i] != expected[i] {
+			t.Fatalf("bookmark %d: expected %+v, got %+v", i, expected[i], bookmarks[i])
+		}
+	}
+
+	if _, err = ParseBookmarks([]byte("[{"), model.ImportFormatPinboard); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestParsePocketBookmarks(t *testing.T) {
+	content := `title,url,time_added,tags,status
+"Hello, world",https://example.com/hello,1700000000,greetings|intro,unread
+No tags,https://example.com/none,1700000001,,archive
+Bad,not-a-url,1700000002,,unread`
+
+	bookmarks, err := ParseBookmarks([]byte(content), model.ImportFormatPocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.ImportedBookmark{
+		{URL: "https://example.com/hello", Cats: "greetings,intro"},
+		{URL: "https://example.com/none"},
+	}
+	if len(bookmarks) != len(expected) {
+		t.Fatalf("expected %d bookmarks, got %d: %+v", len(expected), len(bookmarks), bookmarks)
+	}
+	for i := range expected {
+		if bookmarks[i] != expected[i] {
+			t.Fatalf("bookmark %d: expected %+v, got %+v", i, expected[i], bookmarks[i])
+		}
+	}
+
+	if _, err = ParseBookmarks([]byte("title,link\nA,B"), model.ImportFormatPocket); err == nil {
+		t.Fatal("expected error for CSV without url column")
+	}
+}
+
+func TestGetImportedCats(t *testing.T) {
+	too_many_labels := make([]string, mutil.CATS_PER_LINK_LIMIT+5)
+	for i := range too_many_labels {
+		too_many_labels[i] = "cat" + strings.Repeat("x", i)
+	}
+
+	var test_labels = []struct {
+		Labels       []string
+		ExpectedCats string
+	}{
+		{[]string{"a", " b ", ""}, "a,b"},
+		{[]string{"Go", "go", "GO"}, "Go"},
+		{[]string{"one, two", "multiple   spaces"}, "one two,multiple spaces"},
+		{[]string{strings.Repeat("x", mutil.CAT_CHAR_LIMIT+1), "ok"}, "ok"},
+		{nil, ""},
+	}
+
+	for _, tl := range test_labels {
+		if got := getImportedCats(tl.Labels); got != tl.ExpectedCats {
+			t.Fatalf("expected %q for %v, got %q", tl.ExpectedCats, tl.Labels, got)
+		}
+	}
+
+	got := getImportedCats(too_many_labels)
+	if n := strings.Count(got, ",") + 1; n != mutil.CATS_PER_LINK_LIMIT {
+		t.Fatalf("expected %d cats, got %d", mutil.CATS_PER_LINK_LIMIT, n)
+	}
+}
+
+func TestGetImportedSummary(t *testing.T) {
+	long_text := strings.Repeat("word ", mutil.SUMMARY_CHAR_LIMIT)
+	summary := getImportedSummary(long_text)
+	if len(summary) > mutil.SUMMARY_CHAR_LIMIT {
+		t.Fatalf("expected summary of at most %d chars, got %d", mutil.SUMMARY_CHAR_LIMIT, len(summary))
+	} else if strings.HasSuffix(summary, " ") || !strings.HasSuffix(summary, "word") {
+		t.Fatalf("expected summary truncated on word boundary, got %q", summary)
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestCreateAndGetImportJob(t *testing.T) {
+	bookmarks := []model.ImportedBookmark{
+		{URL: "https://example.com/one", Cats: "a,b", Summary: "first"},
+		{URL: "https://example.com/two", Cats: "c"},
+	}
+
+	job_id, err := CreateImportJob(TEST_LOGIN_NAME, model.ImportFormatPinboard, bookmarks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec(`DELETE FROM "Import Job Items" WHERE job_id = ?;`, job_id)
+	defer TestClient.Exec(`DELETE FROM "Import Jobs" WHERE id = ?;`, job_id)
+
+	has_active_job, err := UserHasActiveImportJob(TEST_LOGIN_NAME)
+	if err != nil {
+		t.Fatal(err)
+	} else if !has_active_job {
+		t.Fatal("expected active import job after creating one")
+	}
+
+	job, err := GetImportJob(job_id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.SubmittedBy != TEST_LOGIN_NAME {
+		t.Fatalf("expected job submitted by %s, got %s", TEST_LOGIN_NAME, job.SubmittedBy)
+	} else if job.Status != model.ImportJobQueued {
+		t.Fatalf("expected job status %s, got %s", model.ImportJobQueued, job.Status)
+	} else if job.ItemsCount != len(bookmarks) {
+		t.Fatalf("expected %d items, got %d", len(bookmarks), job.ItemsCount)
+	} else if job.Counts[model.ImportItemPending] != len(bookmarks) {
+		t.Fatalf("expected %d pending items, got %d", len(bookmarks), job.Counts[model.ImportItemPending])
+	}
+	for i, item := range job.Items {
+		if item.Position != i || item.URL != bookmarks[i].URL || item.Cats != bookmarks[i].Cats {
+			t.Fatalf("item %d: expected %+v, got %+v", i, bookmarks[i], item)
+		}
+	}
+
+	if _, err = GetImportJob("-1"); err == nil {
+		t.Fatal("expected error for nonexistent job")
+	}
+
+	// One active job per user
+	if _, err = CreateImportJob(TEST_LOGIN_NAME, model.ImportFormatPinboard, bookmarks); err != e.ErrImportAlreadyInProgress {
+		t.Fatalf("expected %v, got %v", e.ErrImportAlreadyInProgress, err)
+	}
+	// (even if the check is raced)
+	if _, err = TestClient.Exec(
+		`INSERT INTO "Import Jobs" (id, submitted_by, format, status, created_at)
+		VALUES ('import-race-test', ?, ?, ?, '2025-01-01 00:00:00');`,
+		TEST_LOGIN_NAME,
+		model.ImportFormatPinboard,
+		model.ImportJobRunning,
+	); err == nil {
+		TestClient.Exec(`DELETE FROM "Import Jobs" WHERE id = 'import-race-test';`)
+		t.Fatal("expected second active import job to violate unique index")
+	}
+
+	// Failed jobs record why and no longer block new ones
+	failImportJob(job_id, errors.New("test failure"))
+	job, err = GetImportJob(job_id)
+	if err != nil {
+		t.Fatal(err)
+	} else if job.Status != model.ImportJobFailed || job.Error != "test failure" || job.FinishedAt == "" {
+		t.Fatalf("expected failed job with error, got %+v", job)
+	}
+	has_active_job, err = UserHasActiveImportJob(TEST_LOGIN_NAME)
+	if err != nil {
+		t.Fatal(err)
+	} else if has_active_job {
+		t.Fatal("expected failed import job not to be active")
+	}
+}
+
+func TestAddImportItemToExistingLink(t *testing.T) {
+	// user who has not submitted, tagged or starred the link
+	test_login_name := "import_test_user"
+	test_user_id := "import-test-user-id"
+	test_link_id := "1"
+
+	defer CalculateAndSetGlobalCats(test_link_id)
+	defer TestClient.Exec(
+		"DELETE FROM Tags WHERE link_id = ? AND submitted_by = ?;",
+		test_link_id,
+		test_login_name,
+	)
+	defer TestClient.Exec(
+		"DELETE FROM Stars WHERE link_id = ? AND user_id = ?;",
+		test_link_id,
+		test_user_id,
+	)
+
+	item := &pendingImportItem{}
+	item.Cats = "imported test cat"
+	if err := addImportItemToExistingLink(item, test_link_id, test_login_name, test_user_id); err != nil {
+		t.Fatal(err)
+	}
+	if item.Status != model.ImportItemExisting || !item.Tagged || !item.Starred {
+		t.Fatalf("expected existing link tagged and starred, got %+v", item)
+	} else if item.LinkID != test_link_id {
+		t.Fatalf("expected link ID %s, got %s", test_link_id, item.LinkID)
+	}
+
+	if stars := GetUsersStarsForLink(test_user_id, test_link_id); stars != IMPORT_STARS {
+		t.Fatalf("expected %d stars, got %d", IMPORT_STARS, stars)
+	}
+	tag, err := GetUserTagForLink(test_login_name, test_link_id)
+	if err != nil {
+		t.Fatal(err)
+	} else if tag == nil || tag.Cats != "imported test cat" {
+		t.Fatalf("expected tag cats %q, got %+v", "imported test cat", tag)
+	}
+
+	// Same bookmark again: nothing left to do
+	item = &pendingImportItem{}
+	item.Cats = "imported test cat"
+	if err := addImportItemToExistingLink(item, test_link_id, test_login_name, test_user_id); err != nil {
+		t.Fatal(err)
+	}
+	if item.Status != model.ImportItemSkipped || item.Tagged || item.Starred {
+		t.Fatalf("expected existing link skipped, got %+v", item)
+	}
+}
@@ -1,17 +1,21 @@
 package handler
 
 import (
+	"bytes"
 	"crypto/tls"
+	"io"
 	"log"
 	"os"
 	"slices"
 	"strings"
 
+	"github.com/google/uuid"
 	_ "golang.org/x/image/webp"
 
 	"github.com/julianlk522/modeep/db"
 	e "github.com/julianlk522/modeep/error"
 	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
 	"github.com/julianlk522/modeep/query"
 
 	"database/sql"
@@ -37,9 +41,11 @@ func GetTopLinksOptionsFromRequestParams(params url.Values) (*model.TopLinksOpti
 	// For cats that the links must have
 	cats_params := params.Get("cats")
 	if cats_params != "" {
-		opts.CatFiltersWithSpellingVariants = query.GetCatsOptionalPluralOrSingularForms(
-			strings.Split(cats_params, ","),
-		)
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
 	}
 	// For cats that the links must NOT have
 	neutered_params := params.Get("neutered")
@@ -52,6 +58,11 @@ func GetTopLinksOptionsFromRequestParams(params url.Values) (*model.TopLinksOpti
 	if summary_contains_params != "" {
 		opts.GlobalSummaryContains = summary_contains_params
 	}
+	// (whitespace-only would be an empty FTS5 MATCH, so is ignored)
+	content_contains_params := strings.TrimSpace(params.Get("content_contains"))
+	if content_contains_params != "" {
+		opts.ContentContains = content_contains_params
+	}
 	url_contains_params := params.Get("url_contains")
 	if url_contains_params != "" {
 		opts.URLContains = url_contains_params
@@ -60,6 +71,14 @@ func GetTopLinksOptionsFromRequestParams(params url.Values) (*model.TopLinksOpti
 	if url_lacks_params != "" {
 		opts.URLLacks = url_lacks_params
 	}
+	domain_params := params.Get("domain")
+	if domain_params != "" {
+		domain, err := GetLinkDomain(domain_params)
+		if err != nil {
+			return nil, e.ErrInvalidDomain
+		}
+		opts.Domain = domain
+	}
 	var nsfw_params string
 	if params.Get("include_nsfw") != "" {
 		nsfw_params = params.Get("include_nsfw")
@@ -69,6 +88,12 @@ func GetTopLinksOptionsFromRequestParams(params url.Values) (*model.TopLinksOpti
 	} else if nsfw_params != "false" && nsfw_params != "" {
 		return nil, e.ErrInvalidNSFWParams
 	}
+	exclude_dead_params := params.Get("exclude_dead")
+	if exclude_dead_params == "true" {
+		opts.ExcludeDead = true
+	} else if exclude_dead_params != "false" && exclude_dead_params != "" {
+		return nil, e.ErrInvalidExcludeDeadParams
+	}
 	var sort_by model.SortBy = model.SortByTimesStarred
 	sort_params := params.Get("sort_by")
 	if sort_params != "" {
@@ -93,17 +118,37 @@ func GetTopLinksOptionsFromRequestParams(params url.Values) (*model.TopLinksOpti
 		}
 		opts.Period = period
 	}
+	cursor_params := params.Get("cursor")
+	if cursor_params != "" {
+		// content_contains without sort_by sorts by relevance, which
+		// has no cursor
+		if opts.SortBy == "" && opts.ContentContains != "" {
+			sort_by = model.SortByRelevance
+		}
+		cursor, err := query.DecodeLinksCursor(cursor_params, sort_by)
+		if err != nil {
+			return nil, err
+		}
+		opts.Cursor = cursor
+	}
 	// AsSignedInUser and Page are added to opts directly in GetTopLinks() handler after middleware processing
 	return opts, nil
 }
 
+// Links added by bulk imports don't count (see RunImportJob)
 func UserHasSubmittedMaxDailyLinks(login_name string) (bool, error) {
 	var count int
 	err := db.Client.QueryRow(`SELECT count(*)
 		FROM Links
 		WHERE submitted_by = ?
-		AND submit_date >= date('now', '-1 days');`,
+		AND submit_date >= date('now', '-1 days')
+		AND id NOT IN (
+			SELECT link_id
+			FROM "Import Job Items"
+			WHERE status = ?
+		);`,
 		login_name,
+		model.ImportItemAdded,
 	).Scan(&count)
 	if err != nil {
 		return false, err
@@ -119,6 +164,11 @@ func PrepareLinksPage[T model.HasCats](links_sql *query.TopLinks, options *model
 	}
 
 	paginateLinks(links_page.Links)
+	links_page.OffsetOnly = links_sql.OffsetOnly()
+	if links_page.Links != nil && len(*links_page.Links) > 0 {
+		last_link := getLink((*links_page.Links)[len(*links_page.Links)-1])
+		links_page.NextCursor = links_sql.NextCursor(last_link, links_page.Pages)
+	}
 
 	cat_filters := options.CatFilters
 	if len(cat_filters) > 0 {
@@ -172,10 +222,15 @@ func scanRawLinksPageData[T model.Link | model.LinkSignedIn](links_sql *query.To
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
+				&l.CommentCount,
 				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
 				&pages,
 			)
 			if err != nil {
@@ -200,10 +255,15 @@ func scanRawLinksPageData[T model.Link | model.LinkSignedIn](links_sql *query.To
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
+				&l.CommentCount,
 				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
 				&pages,
 				&l.StarsAssigned,
 			); err != nil {
@@ -245,10 +305,14 @@ func ScanSingleLink[T model.Link | model.LinkSignedIn](single_link_sql *query.Si
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
 			&l.StarsAssigned,
 		); err != nil {
 			return nil, err
@@ -267,10 +331,14 @@ func ScanSingleLink[T model.Link | model.LinkSignedIn](single_link_sql *query.Si
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -281,6 +349,16 @@ func ScanSingleLink[T model.Link | model.LinkSignedIn](single_link_sql *query.Si
 	return link.(*T), nil
 }
 
+func getLink[T model.HasCats](link T) *model.Link {
+	switch l := any(link).(type) {
+	case model.LinkSignedIn:
+		return &l.Link
+	case model.Link:
+		return &l
+	}
+	return nil
+}
+
 func paginateLinks[T model.LinkSignedIn | model.Link](links *[]T) {
 	if links == nil || len(*links) == 0 {
 		return
@@ -332,7 +410,73 @@ func getNSFWLinksCount[T model.HasCats](links_sql *query.TopLinks) (int, error)
 	return int(hidden_links.Int32), nil
 }
 
-// Add link (non-YT)
+// Resolves a submitted URL and fetches its auto summary / preview image URL.
+// Used both when adding a link and when refreshing an existing link's metadata.
+func GetFinalURLAndExtraMetadata(submitted_url string) (string, *model.LinkExtraMetadata, error) {
+	var final_url string
+	x_md := &model.LinkExtraMetadata{}
+
+	// Check for YT video:
+	// In that case test request needs to use API key
+	if IsYTVideo(submitted_url) {
+		if yt_md, err := GetYTVideoMetadata(submitted_url); err == nil {
+			final_url = "https://www.youtube.com/watch?v=" + yt_md.ID
+			x_md.AutoSummary = yt_md.Items[0].Snippet.Title
+			x_md.PreviewImgURL = yt_md.Items[0].Snippet.Thumbnails.Default.URL
+		}
+
+		return final_url, x_md, nil
+	}
+
+	// Test URL response
+	resp, err := GetResolvedURLResponse(submitted_url)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	// Save adjusted URL (after any redirects e.g., to wwww.)...
+	url_after_redirects := resp.Request.URL.String()
+
+	// ...unless modified due to 302/401/403/429 etc. redirect
+	is_unauthorized := resp.StatusCode == http.StatusUnauthorized
+	is_forbidden := resp.StatusCode == http.StatusForbidden
+	is_too_many_requests := resp.StatusCode == http.StatusTooManyRequests
+	is_302_redirect := resp.StatusCode == http.StatusFound
+	is_google_sorry_page := strings.Contains(url_after_redirects, "google.com/sorry")
+
+	if is_unauthorized || is_forbidden || is_too_many_requests || is_302_redirect || is_google_sorry_page {
+		final_url = strings.TrimSuffix(submitted_url, "/")
+	} else {
+		final_url = strings.TrimSuffix(url_after_redirects, "/")
+	}
+
+	// Read body once so it can be both archived and parsed for metadata
+	// (capped; metadata is in <head> anyway)
+	// (1 extra byte read to tell whether the body was cut off)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MAX_ARCHIVE_SNAPSHOT_BYTES+1))
+	if err != nil {
+		log.Printf("Could not read response body for %s: %s", final_url, err)
+	}
+	body_is_truncated := len(body) > MAX_ARCHIVE_SNAPSHOT_BYTES
+	if body_is_truncated {
+		body = body[:MAX_ARCHIVE_SNAPSHOT_BYTES]
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Get metadata for non-YT links
+	if resp_x_md := GetLinkExtraMetadataFromResponse(resp); resp_x_md != nil {
+		x_md = resp_x_md
+	}
+
+	if isArchivable(resp) {
+		x_md.PageContent = body
+		x_md.PageContentType = resp.Header.Get("Content-Type")
+		x_md.PageContentTruncated = body_is_truncated
+	}
+
+	return final_url, x_md, nil
+}
+
 func GetLinkExtraMetadataFromResponse(resp *http.Response) *model.LinkExtraMetadata {
 	if resp == nil {
 		return nil
@@ -344,7 +488,7 @@ func GetLinkExtraMetadataFromResponse(resp *http.Response) *model.LinkExtraMetad
 	return nil
 }
 
-func getLinkExtraMetadataFromHTML(url *url.URL, html_md HTMLMetadata) *model.LinkExtraMetadata {
+func getLinkExtraMetadataFromHTML(page_url *url.URL, html_md HTMLMetadata) *model.LinkExtraMetadata {
 	x_md := &model.LinkExtraMetadata{}
 
 	switch {
@@ -364,11 +508,23 @@ func getLinkExtraMetadataFromHTML(url *url.URL, html_md HTMLMetadata) *model.Lin
 		x_md.AutoSummary = html_md.TwitterTitle
 	}
 
+	// Resolve rel="canonical" href relative to the page URL
+	// (ignored if on another host: a page could otherwise pass itself off
+	// as a duplicate of any link)
+	if html_md.Canonical != "" {
+		if canonical_url, err := url.Parse(html_md.Canonical); err == nil {
+			canonical_url = page_url.ResolveReference(canonical_url)
+			if hasSameHost(canonical_url, page_url) {
+				x_md.CanonicalURL = canonical_url.String()
+			}
+		}
+	}
+
 	// Test preview image URL to confirm it can be accessed
 	// TODO cleanup
 	if html_md.OGImage != "" {
 		if !strings.HasPrefix(html_md.OGImage, "http") {
-			html_md.OGImage = url.Scheme + "://" + url.Host + "/" + html_md.OGImage
+			html_md.OGImage = page_url.Scheme + "://" + page_url.Host + "/" + html_md.OGImage
 			log.Printf("updated preview img URL: %v\n", html_md.OGImage)
 		}
 		if _, err := GetResolvedURLResponse(html_md.OGImage); err == nil {
@@ -376,7 +532,7 @@ func getLinkExtraMetadataFromHTML(url *url.URL, html_md HTMLMetadata) *model.Lin
 		}
 	} else if html_md.TwitterImage != "" {
 		if !strings.HasPrefix(html_md.TwitterImage, "http") {
-			html_md.TwitterImage = url.Scheme + "://" + url.Host + "/" + html_md.TwitterImage
+			html_md.TwitterImage = page_url.Scheme + "://" + page_url.Host + "/" + html_md.TwitterImage
 			log.Printf("updated preview img URL: %v\n", html_md.OGImage)
 		}
 		if _, err := GetResolvedURLResponse(html_md.TwitterImage); err == nil {
@@ -481,16 +637,214 @@ func SavePreviewImgAndGetFileName(url string, link_id string) (string, error) {
 	return file_name, nil
 }
 
-func LinkAlreadyAdded(url string) (bool, string) {
+// Matches either the exact URL or any URL sharing its canonical key
+// (see GetCanonicalURLKey)
+func LinkAlreadyAdded(url string) (bool, string, error) {
 	var id sql.NullString
 
-	err := db.Client.QueryRow("SELECT id FROM Links WHERE url = ?", url).Scan(&id)
-
-	if err == nil && id.Valid {
-		return true, id.String
+	var err error
+	if canonical_key, key_err := GetCanonicalURLKey(url); key_err == nil {
+		err = db.Client.QueryRow(
+			"SELECT id FROM Links WHERE url = ? OR canonical_key = ?",
+			url,
+			canonical_key,
+		).Scan(&id)
 	} else {
-		return false, ""
+		err = db.Client.QueryRow("SELECT id FROM Links WHERE url = ?", url).Scan(&id)
+	}
+
+	if err == sql.ErrNoRows {
+		return false, "", nil
+	} else if err != nil {
+		return false, "", err
+	}
+
+	return id.Valid, id.String, nil
+}
+
+// Also checks the page's self-declared canonical URL, if any.
+// Since URLs are unique, a duplicate that login_name can't see (another
+// user's private link, or a hidden link) is still reported, but without
+// its ID.
+func GetDuplicateLinkID(final_url string, x_md *model.LinkExtraMetadata, login_name string) (string, bool, error) {
+	is_duplicate, link_id, err := LinkAlreadyAdded(final_url)
+	if err != nil {
+		return "", false, err
+	}
+	if !is_duplicate && x_md.CanonicalURL != "" {
+		is_duplicate, link_id, err = LinkAlreadyAdded(x_md.CanonicalURL)
+		if err != nil {
+			return "", false, err
+		}
+	}
+	if !is_duplicate {
+		return "", false, nil
+	}
+
+	link_is_visible, err := LinkIsVisibleToUser(link_id, login_name)
+	if err != nil {
+		return "", false, err
+	} else if !link_is_visible {
+		return "", true, nil
+	}
+
+	return link_id, true, nil
+}
+
+// The page's self-declared canonical URL, if any, takes precedence
+// over the final URL when deriving the stored canonical key
+func GetNewLinkCanonicalKey(final_url string, x_md *model.LinkExtraMetadata) (string, error) {
+	if x_md.CanonicalURL != "" {
+		return GetCanonicalURLKey(x_md.CanonicalURL)
+	}
+
+	return GetCanonicalURLKey(final_url)
+}
+
+// Inserts the link with its submitter's tag and summary (if any) plus
+// auto summary, preview image, archive snapshot and indexed page content.
+// new_link must have LinkID, SubmitDate, SubmittedBy, URL and Cats set.
+func SaveNewLink(new_link *model.NewLink, x_md *model.LinkExtraMetadata, canonical_key string, submitter_user_id string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	new_link.AutoSummary = x_md.AutoSummary
+	new_link.PreviewImgURL = x_md.PreviewImgURL
+
+	// Insert auto summary
+	if new_link.AutoSummary != "" {
+		if _, err := tx.Exec(
+			"INSERT INTO Summaries VALUES(?,?,?,?,?);",
+			uuid.New().String(),
+			new_link.AutoSummary,
+			new_link.LinkID,
+			db.AUTO_SUMMARY_USER_ID,
+			new_link.SubmitDate,
+		); err != nil {
+			log.Print("Error adding auto summary: ", err)
+		} else {
+			new_link.SummaryCount = 1
+		}
+	}
+
+	// Insert summary
+	if new_link.Summary != "" {
+		if _, err := tx.Exec(
+			"INSERT INTO Summaries VALUES(?,?,?,?,?);",
+			uuid.New().String(),
+			new_link.Summary,
+			new_link.LinkID,
+			submitter_user_id,
+			new_link.SubmitDate,
+		); err != nil {
+			return err
+		} else {
+			new_link.SummaryCount += 1
+		}
+	}
+
+	// Insert tag
+	raw_cats := new_link.Cats
+	new_link.Cats = TidyCats(raw_cats)
+	if _, err = tx.Exec(
+		"INSERT INTO Tags VALUES(?,?,?,?,?);",
+		uuid.New().String(),
+		new_link.LinkID,
+		new_link.Cats,
+		new_link.SubmittedBy,
+		new_link.SubmitDate,
+	); err != nil {
+		return err
+	}
+
+	// Insert link
+	if new_link.Summary == "" && new_link.AutoSummary != "" {
+		new_link.Summary = new_link.AutoSummary
+	}
+
+	if new_link.PreviewImgURL != "" {
+		new_link.PreviewImgFilename, err = SavePreviewImgAndGetFileName(
+			new_link.PreviewImgURL,
+			new_link.LinkID,
+		)
+		if err != nil {
+			// skip - link won't have a preview image
+			log.Printf("Could not save preview image for link %s: %s", new_link.LinkID, err)
+		}
+	}
+
+	// (NULL if it can't be determined, same as links not yet backfilled)
+	var domain sql.NullString
+	if d, err := GetLinkDomain(new_link.URL); err == nil {
+		domain = sql.NullString{String: d, Valid: true}
+	}
+
+	if _, err = tx.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary, img_file, canonical_key, domain, visibility)
+		VALUES(?,?,?,?,?,?,?,?,?,?);`,
+		new_link.LinkID,
+		new_link.URL,
+		new_link.SubmittedBy,
+		new_link.SubmitDate,
+		new_link.Cats,
+		new_link.Summary,
+		new_link.PreviewImgFilename,
+		canonical_key,
+		domain,
+		new_link.Visibility,
+	); err != nil {
+		return err
+	}
+	if err = setLinkGlobalCats(tx, new_link.LinkID, new_link.Cats); err != nil {
+		return err
+	}
+
+	// Archive page snapshot
+	if err = SaveArchiveSnapshot(
+		tx,
+		new_link.LinkID,
+		x_md.PageContent,
+		x_md.PageContentType,
+		x_md.PageContentTruncated,
+	); err != nil {
+		// skip - link won't have an archived snapshot
+		log.Printf("Could not archive snapshot for link %s: %s", new_link.LinkID, err)
+	}
+
+	// Index page content for content_contains searches
+	if err = SetPageContentText(
+		tx,
+		new_link.LinkID,
+		x_md.PageContent,
+		x_md.PageContentType,
+	); err != nil {
+		// skip - link won't be found by page content
+		log.Printf("Could not index page content for link %s: %s", new_link.LinkID, err)
+	}
+
+	// Increment spellfix ranks
+	// (private links' cats are not suggested to others)
+	if new_link.Visibility != model.LinkPrivate {
+		if err = IncrementSpellfixRanksForCats(
+			tx,
+			strings.Split(raw_cats, ","),
+		); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
 	}
+
+	if err = WriteArchiveSnapshotFile(x_md.PageContent); err != nil {
+		log.Printf("Could not write archive snapshot for link %s: %s", new_link.LinkID, err)
+	}
+
+	return nil
 }
 
 func IncrementSpellfixRanksForCats(tx *sql.Tx, cats []string) error {
@@ -628,6 +982,88 @@ func UserSubmittedLink(login_name string, link_id string) bool {
 	return sb.String == login_name
 }
 
+// Private and hidden links are only visible to their submitter. False
+// if no link with link_id exists.
+func LinkIsVisibleToUser(link_id string, login_name string) (bool, error) {
+	var submitted_by string
+	var is_public bool
+	err := db.Client.QueryRow(
+		`SELECT submitted_by, id IN (SELECT id FROM "Public Links") FROM Links WHERE id = ?;`,
+		link_id,
+	).Scan(&submitted_by, &is_public)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return is_public || submitted_by == login_name, nil
+}
+
+// Neither private nor hidden
+func LinkIsPublic(link_id string) (bool, error) {
+	var is_public bool
+	if err := db.Client.QueryRow(
+		`SELECT id IN (SELECT id FROM "Public Links") FROM Links WHERE id = ?;`,
+		link_id,
+	).Scan(&is_public); err != nil {
+		return false, err
+	}
+
+	return is_public, nil
+}
+
+// Private and hidden links' cats don't count toward spellfix ranks, so
+// they are adjusted when visibility changes (unless the link is hidden)
+func SetLinkVisibility(link_id string, visibility model.LinkVisibility) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old_visibility model.LinkVisibility
+	var global_cats string
+	var is_hidden bool
+	if err = tx.QueryRow(
+		`SELECT
+			visibility,
+			COALESCE(global_cats, ''),
+			id IN (SELECT content_id FROM "Hidden Content" WHERE content_type = 'link')
+		FROM Links
+		WHERE id = ?;`,
+		link_id,
+	).Scan(&old_visibility, &global_cats, &is_hidden); err != nil {
+		return err
+	} else if old_visibility == visibility {
+		return nil
+	}
+
+	if _, err = tx.Exec(
+		"UPDATE Links SET visibility = ? WHERE id = ?;",
+		visibility,
+		link_id,
+	); err != nil {
+		return err
+	}
+
+	if is_hidden {
+		return tx.Commit()
+	}
+
+	cats := strings.Split(global_cats, ",")
+	if visibility == model.LinkPrivate {
+		err = DecrementSpellfixRanksForCats(tx, cats)
+	} else {
+		err = IncrementSpellfixRanksForCats(tx, cats)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func UserHasStarredLink(user_id string, link_id string) bool {
 	var l sql.NullString
 	err := db.Client.QueryRow(`SELECT id FROM Stars WHERE user_id = ? AND link_id = ?;`, user_id, link_id).Scan(&l)
@@ -635,6 +1071,18 @@ func UserHasStarredLink(user_id string, link_id string) bool {
 	return err == nil && l.Valid
 }
 
+func SaveNewStar(tx *sql.Tx, link_id string, user_id string, num_stars uint8) error {
+	_, err := tx.Exec(
+		`INSERT INTO "Stars" VALUES(?,?,?,?,?);`,
+		uuid.New().String(),
+		link_id,
+		user_id,
+		num_stars,
+		mutil.NEW_LONG_TIMESTAMP(),
+	)
+	return err
+}
+
 func GetUsersStarsForLink(user_id string, link_id string) uint8 {
 	var stars uint8
 	if err := db.Client.QueryRow(`SELECT num_stars FROM Stars WHERE user_id = ? AND link_id = ?;`, user_id, link_id).Scan(&stars); err != nil {
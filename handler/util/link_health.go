@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/julianlk522/modeep/db"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Runs forever: every LINK_HEALTH_CHECKER_SLEEP, re-requests any links not
+// checked within LINK_HEALTH_CHECK_INTERVAL and records the results.
+// Meant to be started once in its own goroutine from main().
+func RunLinkHealthChecker() {
+	for {
+		if err := checkStaleLinks(); err != nil {
+			log.Printf("Link health checker: %s", err)
+		}
+		time.Sleep(LINK_HEALTH_CHECKER_SLEEP)
+	}
+}
+
+type linkToCheck struct {
+	ID  string
+	URL string
+}
+
+func checkStaleLinks() error {
+	cutoff := time.Now().Add(-LINK_HEALTH_CHECK_INTERVAL).Format("2006-01-02 15:04:05")
+	rows, err := db.Client.Query(
+		`SELECT id, url
+		FROM Links
+		WHERE last_checked_at IS NULL OR last_checked_at < ?
+		ORDER BY last_checked_at ASC NULLS FIRST;`,
+		cutoff,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Collect first so the connection isn't held during slow requests
+	var links []linkToCheck
+	for rows.Next() {
+		var l linkToCheck
+		if err := rows.Scan(&l.ID, &l.URL); err != nil {
+			rows.Close()
+			return err
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range links {
+		check := CheckLinkHealth(l.ID, l.URL)
+		if err = RecordLinkHealthCheck(check); err != nil {
+			log.Printf("Could not record health check for link %s: %s", l.ID, err)
+		}
+		time.Sleep(LINK_HEALTH_CHECK_DELAY)
+	}
+
+	return nil
+}
+
+// Uses the same request logic as AddLink (GetResolvedURLResponse).
+// StatusCode is 0 if no usable response was received at all.
+func CheckLinkHealth(link_id string, url string) *model.LinkHealthCheck {
+	check := &model.LinkHealthCheck{
+		LinkID: link_id,
+	}
+
+	start := time.Now()
+	resp, err := GetResolvedURLResponse(url)
+	check.LatencyMS = time.Since(start).Milliseconds()
+	check.CheckedAt = mutil.NEW_LONG_TIMESTAMP()
+
+	if err == nil {
+		check.StatusCode = resp.StatusCode
+		check.FinalURL = resp.Request.URL.String()
+		resp.Body.Close()
+	}
+
+	return check
+}
+
+// Inserts the check into history and updates the link's current health.
+// A link is only considered dead after LINK_DEAD_AFTER_FAILED_CHECKS
+// consecutive failed checks, so one bad day doesn't bury it.
+func RecordLinkHealthCheck(check *model.LinkHealthCheck) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(
+		`INSERT INTO "Link Checks" (link_id, status_code, final_url, latency_ms, checked_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		check.LinkID,
+		check.StatusCode,
+		check.FinalURL,
+		check.LatencyMS,
+		check.CheckedAt,
+	); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(
+		`SELECT status_code
+		FROM "Link Checks"
+		WHERE link_id = ?
+		ORDER BY checked_at DESC, id DESC
+		LIMIT ?;`,
+		check.LinkID,
+		LINK_DEAD_AFTER_FAILED_CHECKS,
+	)
+	if err != nil {
+		return err
+	}
+
+	var recent_status_codes []int
+	for rows.Next() {
+		var sc int
+		if err := rows.Scan(&sc); err != nil {
+			rows.Close()
+			return err
+		}
+		recent_status_codes = append(recent_status_codes, sc)
+	}
+	// (closed before the update since the tx has only one connection)
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(
+		`UPDATE Links
+		SET health = ?, last_checked_at = ?
+		WHERE id = ?;`,
+		getLinkHealthFromRecentStatusCodes(recent_status_codes),
+		check.CheckedAt,
+		check.LinkID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func getLinkHealthFromRecentStatusCodes(status_codes []int) model.LinkHealth {
+	if len(status_codes) < LINK_DEAD_AFTER_FAILED_CHECKS {
+		return model.LinkHealthAlive
+	}
+
+	for _, sc := range status_codes {
+		if !linkHealthCheckFailed(sc) {
+			return model.LinkHealthAlive
+		}
+	}
+
+	return model.LinkHealthDead
+}
+
+// 401/403/429 are not failures: same benefit of the doubt as in AddLink
+// (the site likely exists but doesn't like bots)
+func linkHealthCheckFailed(status_code int) bool {
+	return status_code == 0 ||
+		status_code == http.StatusNotFound ||
+		status_code == http.StatusGone ||
+		status_code >= http.StatusInternalServerError
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+func TestGetLinkHealthFromRecentStatusCodes(t *testing.T) {
+	var test_status_codes = []struct {
+		StatusCodes    []int
+		ExpectedHealth model.LinkHealth
+	}{
+		{[]int{}, model.LinkHealthAlive},
+		{[]int{200}, model.LinkHealthAlive},
+		{[]int{0, 404}, model.LinkHealthAlive},
+		{[]int{404, 0, 500}, model.LinkHealthDead},
+		{[]int{410, 503, 502}, model.LinkHealthDead},
+		{[]int{0, 0, 200}, model.LinkHealthAlive},
+		// bot-averse sites are not dead
+		{[]int{403, 403, 403}, model.LinkHealthAlive},
+		{[]int{429, 401, 403}, model.LinkHealthAlive},
+	}
+
+	for _, tsc := range test_status_codes {
+		if health := getLinkHealthFromRecentStatusCodes(tsc.StatusCodes); health != tsc.ExpectedHealth {
+			t.Fatalf(
+				"expected health %q for status codes %v, got %q",
+				tsc.ExpectedHealth,
+				tsc.StatusCodes,
+				health,
+			)
+		}
+	}
+}
+
+func TestRecordLinkHealthCheck(t *testing.T) {
+	test_link_id := "1"
+
+	for range LINK_DEAD_AFTER_FAILED_CHECKS {
+		if err := RecordLinkHealthCheck(&model.LinkHealthCheck{
+			LinkID:     test_link_id,
+			StatusCode: 404,
+			CheckedAt:  mutil.NEW_LONG_TIMESTAMP(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var health, last_checked_at string
+	if err := TestClient.QueryRow(
+		"SELECT health, last_checked_at FROM Links WHERE id = ?;",
+		test_link_id,
+	).Scan(&health, &last_checked_at); err != nil {
+		t.Fatal(err)
+	} else if model.LinkHealth(health) != model.LinkHealthDead {
+		t.Fatalf("expected link %s to be dead, got %q", test_link_id, health)
+	} else if last_checked_at == "" {
+		t.Fatal("expected last_checked_at to be set")
+	}
+
+	// single successful check revives link
+	if err := RecordLinkHealthCheck(&model.LinkHealthCheck{
+		LinkID:     test_link_id,
+		StatusCode: 200,
+		FinalURL:   "https://example.com",
+		CheckedAt:  mutil.NEW_LONG_TIMESTAMP(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TestClient.QueryRow(
+		"SELECT health FROM Links WHERE id = ?;",
+		test_link_id,
+	).Scan(&health); err != nil {
+		t.Fatal(err)
+	} else if model.LinkHealth(health) != model.LinkHealthAlive {
+		t.Fatalf("expected link %s to be alive, got %q", test_link_id, health)
+	}
+}
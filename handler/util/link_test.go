@@ -57,13 +57,20 @@ func TestPrepareLinksPage(t *testing.T) {
 			continue
 		}
 
-		if _, err = PrepareLinksPage[model.Link](links_sql, tr.PageOptions); err != nil {
+		links_page, err := PrepareLinksPage[model.Link](links_sql, tr.PageOptions)
+		if err != nil {
 			t.Fatalf(
 				"got error %s, SQL text was %s, args were %v",
 				err,
 				links_sql.Text,
 				links_sql.Args,
 			)
+		} else if (links_page.Pages > 1) != (links_page.NextCursor != "") {
+			t.Fatalf(
+				"expected next cursor only if more than 1 page, got %d pages and cursor %q",
+				links_page.Pages,
+				links_page.NextCursor,
+			)
 		}
 	}
 }
@@ -498,8 +505,10 @@ func TestLinkAlreadyAdded(t *testing.T) {
 	}
 
 	for _, u := range test_urls {
-		added, _ := LinkAlreadyAdded(u.URL)
-		if u.Added && !added {
+		added, _, err := LinkAlreadyAdded(u.URL)
+		if err != nil {
+			t.Fatal(err)
+		} else if u.Added && !added {
 			t.Fatalf("expected url %s to be added", u.URL)
 		} else if !u.Added && added {
 			t.Fatalf("%s NOT added, expected error", u.URL)
@@ -507,6 +516,37 @@ func TestLinkAlreadyAdded(t *testing.T) {
 	}
 }
 
+func TestGetDuplicateLinkID(t *testing.T) {
+	const test_link_id = "duplicate-private-test"
+	const test_url = "https://duplicate-private-test.com"
+	if _, err := db.Client.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary, visibility)
+		VALUES (?, ?, ?, '2025-01-01', 'test', '', 'private');`,
+		test_link_id,
+		test_url,
+		TEST_LOGIN_NAME,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Client.Exec("DELETE FROM Links WHERE id = ?;", test_link_id)
+
+	// Other users learn of the duplicate but not its ID
+	for login_name, expected_link_id := range map[string]string{
+		TEST_LOGIN_NAME: test_link_id,
+		"bradley":       "",
+		"":              "",
+	} {
+		link_id, is_duplicate, err := GetDuplicateLinkID(test_url, &model.LinkExtraMetadata{}, login_name)
+		if err != nil {
+			t.Fatal(err)
+		} else if !is_duplicate {
+			t.Fatalf("expected %s to be a duplicate for %q", test_url, login_name)
+		} else if link_id != expected_link_id {
+			t.Fatalf("expected link ID %q for %q, got %q", expected_link_id, login_name, link_id)
+		}
+	}
+}
+
 func TestIncrementSpellfixRanksForCats(t *testing.T) {
 	var test_cats = []struct {
 		Cats         []string
@@ -655,3 +695,62 @@ func TestUserHasStarredLink(t *testing.T) {
 		}
 	}
 }
+
+func TestSetLinkVisibility(t *testing.T) {
+	if _, err := db.Client.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+		VALUES ('visibility-test', 'https://visibility-test.com', ?, '2025-01-01 00:00:00', 'visibilitytestcat');`,
+		TEST_LOGIN_NAME,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Client.Exec("DELETE FROM Links WHERE id = 'visibility-test';")
+	if err := IncrementSpellfixRanksForCats(nil, []string{"visibilitytestcat"}); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Client.Exec("DELETE FROM global_cats_spellfix WHERE word = 'visibilitytestcat';")
+
+	var test_cases = []struct {
+		Visibility           model.LinkVisibility
+		ExpectedSpellfixRank int
+		VisibleToOthers      bool
+	}{
+		{model.LinkPrivate, 0, false},
+		// no change
+		{model.LinkPrivate, 0, false},
+		{model.LinkPublic, 1, true},
+	}
+	for _, tc := range test_cases {
+		if err := SetLinkVisibility("visibility-test", tc.Visibility); err != nil {
+			t.Fatal(err)
+		}
+
+		var rank int
+		if err := db.Client.QueryRow(
+			"SELECT COALESCE(MAX(rank), 0) FROM global_cats_spellfix WHERE word = 'visibilitytestcat';",
+		).Scan(&rank); err != nil {
+			t.Fatal(err)
+		} else if rank != tc.ExpectedSpellfixRank {
+			t.Fatalf("expected spellfix rank %d when %s, got %d", tc.ExpectedSpellfixRank, tc.Visibility, rank)
+		}
+
+		for login_name, expected := range map[string]bool{
+			TEST_LOGIN_NAME: true,
+			"bradley":       tc.VisibleToOthers,
+			"":              tc.VisibleToOthers,
+		} {
+			visible, err := LinkIsVisibleToUser("visibility-test", login_name)
+			if err != nil {
+				t.Fatal(err)
+			} else if visible != expected {
+				t.Fatalf("expected %s link visible to %q: %t, got %t", tc.Visibility, login_name, expected, visible)
+			}
+		}
+	}
+
+	if visible, err := LinkIsVisibleToUser("-1", TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	} else if visible {
+		t.Fatal("expected nonexistent link not to be visible")
+	}
+}
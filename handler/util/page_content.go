@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Replaces the link's indexed page content (searched via the
+// content_contains filter) with the readable text of content.
+// No-op if there is no content or it isn't text.
+func SetPageContentText(tx *sql.Tx, link_id string, content []byte, content_type string) error {
+	if len(content) == 0 {
+		return nil
+	}
+
+	var text string
+	media_type, _, _ := mime.ParseMediaType(content_type)
+	switch media_type {
+	case "text/html", "application/xhtml+xml":
+		text = extractReadableText(bytes.NewReader(content), MAX_PAGE_CONTENT_CHARS)
+	case "text/plain":
+		text = strings.Join(strings.Fields(string(content)), " ")
+		if len(text) > MAX_PAGE_CONTENT_CHARS {
+			text = strings.ToValidUTF8(text[:MAX_PAGE_CONTENT_CHARS], "")
+		}
+	}
+	if text == "" {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM page_content_fts WHERE link_id = ?;",
+		link_id,
+	); err != nil {
+		return err
+	}
+	_, err := tx.Exec(
+		"INSERT INTO page_content_fts (link_id, content) VALUES (?, ?);",
+		link_id,
+		text,
+	)
+	return err
+}
+
+// Elements whose text is never part of a page's readable content
+var NON_CONTENT_ELEMENTS = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"head":     true,
+	"nav":      true,
+	"header":   true,
+	"footer":   true,
+	"aside":    true,
+	"form":     true,
+	"button":   true,
+	"iframe":   true,
+}
+
+// Returns visible text from an HTML document with whitespace collapsed,
+// truncated to max_chars
+func extractReadableText(resp io.Reader, max_chars int) string {
+	tokenizer := html.NewTokenizer(resp)
+
+	var sb strings.Builder
+	// depth inside non-content elements
+	skip_depth := 0
+
+	for sb.Len() < max_chars {
+		token_type := tokenizer.Next()
+		switch token_type {
+		case html.ErrorToken:
+			return strings.TrimSpace(sb.String())
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			if NON_CONTENT_ELEMENTS[string(name)] {
+				skip_depth++
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if NON_CONTENT_ELEMENTS[string(name)] && skip_depth > 0 {
+				skip_depth--
+			}
+		case html.TextToken:
+			if skip_depth > 0 {
+				continue
+			}
+
+			text := strings.Join(strings.Fields(string(tokenizer.Text())), " ")
+			if text == "" {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(text)
+		}
+	}
+
+	// Truncate on a word boundary
+	text := sb.String()[:max_chars]
+	if i := strings.LastIndexByte(text, ' '); i > 0 {
+		text = text[:i]
+	}
+
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractReadableText(t *testing.T) {
+	var test_pages = []struct {
+		HTML     string
+		MaxChars int
+		Expected string
+	}{
+		{
+			`<html><head><title>ignored</title></head>
+			<body><p>Hello,
+			   world!</p><p>Second   paragraph</p></body></html>`,
+			1000,
+			"Hello, world! Second paragraph",
+		},
+		{
+			`<body><nav>Home | About</nav><main>Main text</main>
+			<script>var x = "no";</script><style>p { color: red; }</style>
+			<footer>Copyright</footer></body>`,
+			1000,
+			"Main text",
+		},
+		{
+			`<body><p>one two three four</p></body>`,
+			10,
+			"one two",
+		},
+		{"", 1000, ""},
+	}
+
+	for _, tp := range test_pages {
+		got := extractReadableText(strings.NewReader(tp.HTML), tp.MaxChars)
+		if got != tp.Expected {
+			t.Fatalf("expected %q, got %q", tp.Expected, got)
+		}
+	}
+}
+
+func TestSetPageContentText(t *testing.T) {
+	test_link_id := "1"
+	defer TestClient.Exec(
+		"DELETE FROM page_content_fts WHERE link_id = ?;",
+		test_link_id,
+	)
+
+	var test_contents = []struct {
+		Content     string
+		ContentType string
+		Expected    string
+	}{
+		{
+			"<html><body><p>indexed   by TestSetPageContentText</p></body></html>",
+			"text/html; charset=utf-8",
+			"indexed by TestSetPageContentText",
+		},
+		// replaces previous content
+		{
+			"plain\ntext   content",
+			"text/plain",
+			"plain text content",
+		},
+		// non-text content is ignored, previous content kept
+		{
+			"%PDF-1.4",
+			"application/pdf",
+			"plain text content",
+		},
+	}
+
+	for _, tc := range test_contents {
+		tx, err := TestClient.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = SetPageContentText(
+			tx,
+			test_link_id,
+			[]byte(tc.Content),
+			tc.ContentType,
+		); err != nil {
+			tx.Rollback()
+			t.Fatal(err)
+		}
+		if err = tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+
+		var rows_count int
+		var content string
+		if err = TestClient.QueryRow(
+			`SELECT COUNT(*), COALESCE(MAX(content), '')
+			FROM page_content_fts
+			WHERE link_id = ?;`,
+			test_link_id,
+		).Scan(&rows_count, &content); err != nil {
+			t.Fatal(err)
+		}
+
+		if rows_count != 1 {
+			t.Fatalf("expected 1 indexed row for link %s, got %d", test_link_id, rows_count)
+		} else if content != tc.Expected {
+			t.Fatalf("expected content %q, got %q", tc.Expected, content)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+// Sorted by weight, highest first. Cats that resemble each other (e.g.,
+// "flower" and "Flowers") are merged under the first one found.
+func GetCatAffinityProfile(sources_sql *query.CatAffinitySources) ([]model.CatAffinity, error) {
+	if sources_sql.Error != nil {
+		return nil, sources_sql.Error
+	}
+
+	rows, err := sources_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profile []model.CatAffinity
+	// highest stars given to each affinity's StarredLinkID
+	var starred_link_stars []int
+
+	for rows.Next() {
+		var source, link_id, url, cats string
+		var weight int
+		if err := rows.Scan(&source, &link_id, &url, &cats, &weight); err != nil {
+			return nil, err
+		}
+
+		var source_weight float64
+		switch source {
+		case "star":
+			source_weight = RECOMMENDED_LINKS_STAR_WEIGHT * float64(weight)
+		case "tag":
+			source_weight = RECOMMENDED_LINKS_TAG_WEIGHT * float64(weight)
+		case "click":
+			source_weight = RECOMMENDED_LINKS_CLICK_WEIGHT * float64(weight)
+		}
+
+		for cat := range strings.SplitSeq(cats, ",") {
+			if cat == "" {
+				continue
+			}
+
+			i := slices.IndexFunc(profile, func(ca model.CatAffinity) bool {
+				return CatsResembleEachOther(ca.Cat, cat)
+			})
+			if i == -1 {
+				profile = append(profile, model.CatAffinity{Cat: cat})
+				starred_link_stars = append(starred_link_stars, 0)
+				i = len(profile) - 1
+			}
+
+			profile[i].Weight += source_weight
+			if source == "star" && weight > starred_link_stars[i] {
+				profile[i].StarredLinkID = link_id
+				profile[i].StarredLinkURL = url
+				starred_link_stars[i] = weight
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(profile, func(a, b model.CatAffinity) int {
+		return cmp.Compare(b.Weight, a.Weight)
+	})
+
+	return profile, nil
+}
+
+// Cats to search for recommended links with
+func GetTopAffinityCats(profile []model.CatAffinity) []string {
+	var cats []string
+	for i := 0; i < len(profile) && i < RECOMMENDED_LINKS_AFFINITY_CATS_LIMIT; i++ {
+		cats = append(cats, profile[i].Cat)
+	}
+
+	return cats
+}
+
+// Scans candidates and returns the RECOMMENDED_LINKS_LIMIT highest
+// scoring (see GetRecommendedLinkScore()), each with the reason it was
+// recommended. Ties keep the query's order (highest rated first).
+func ScanRecommendedLinks(recommended_sql *query.RecommendedLinks, profile []model.CatAffinity) (*[]model.RecommendedLink, error) {
+	if recommended_sql.Error != nil {
+		return nil, recommended_sql.Error
+	}
+
+	rows, err := recommended_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scoredLink struct {
+		Link  model.RecommendedLink
+		Score float64
+	}
+	var scored_links []scoredLink
+
+	for rows.Next() {
+		l := model.RecommendedLink{}
+		var pages int
+		if err := rows.Scan(
+			&l.ID,
+			&l.URL,
+			&l.SubmittedBy,
+			&l.SubmitDate,
+			&l.Cats,
+			&l.Summary,
+			&l.SummaryCount,
+			&l.TimesStarred,
+			&l.AvgStars,
+			&l.Rating,
+			&l.EarliestStarrers,
+			&l.ClickCount,
+			&l.TagCount,
+			&l.CommentCount,
+			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
+			&pages,
+			&l.StarsAssigned,
+		); err != nil {
+			return nil, err
+		}
+
+		score, top_affinity := GetRecommendedLinkScore(l.Cats, l.Rating, profile)
+		if top_affinity != nil {
+			l.ReasonCat = top_affinity.Cat
+			if top_affinity.StarredLinkID != "" {
+				l.ReasonStarredLinkID = top_affinity.StarredLinkID
+				l.Reason = "because you starred " + top_affinity.StarredLinkURL
+			} else {
+				l.Reason = "because you like " + top_affinity.Cat
+			}
+		}
+
+		scored_links = append(scored_links, scoredLink{l, score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(scored_links, func(a, b scoredLink) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(scored_links) > RECOMMENDED_LINKS_LIMIT {
+		scored_links = scored_links[:RECOMMENDED_LINKS_LIMIT]
+	}
+
+	recommended_links := make([]model.RecommendedLink, len(scored_links))
+	for i, sl := range scored_links {
+		recommended_links[i] = sl.Link
+	}
+
+	return &recommended_links, nil
+}
+
+// Weighted sum of the link's cats' combined affinity relative to the
+// user's top cat (max 1) and its rating out of 5.
+// Also returns the affinity of the link's cat the user likes most, if
+// they like any.
+func GetRecommendedLinkScore(link_cats string, rating float32, profile []model.CatAffinity) (float64, *model.CatAffinity) {
+	var affinity float64
+	var top_affinity *model.CatAffinity
+
+	if len(profile) > 0 && profile[0].Weight > 0 && link_cats != "" {
+		for cat := range strings.SplitSeq(link_cats, ",") {
+			i := slices.IndexFunc(profile, func(ca model.CatAffinity) bool {
+				return CatsResembleEachOther(ca.Cat, cat)
+			})
+			if i == -1 {
+				continue
+			}
+
+			affinity += profile[i].Weight
+			if top_affinity == nil || profile[i].Weight > top_affinity.Weight {
+				top_affinity = &profile[i]
+			}
+		}
+
+		// profile is sorted so the first cat is the top one
+		affinity = min(1, affinity/profile[0].Weight)
+	}
+
+	return RECOMMENDED_LINKS_AFFINITY_WEIGHT*affinity +
+		RECOMMENDED_LINKS_QUALITY_WEIGHT*float64(rating)/5, top_affinity
+}
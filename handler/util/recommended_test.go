@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+const (
+	TEST_RECOMMENDED_USER_ID    = "recommended-test-user"
+	TEST_RECOMMENDED_LOGIN_NAME = "recommendedtestuser"
+)
+
+func insertRecommendedTestData(t *testing.T) func() {
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	test_links := []struct {
+		ID          string
+		Cats        string
+		SubmittedBy string
+	}{
+		{"rec-test-starred", "rectestcat,rectestother", TEST_LOGIN_NAME},
+		{"rec-test-tagged", "rectestother", TEST_LOGIN_NAME},
+		{"rec-test-clicked", "rectestclicked", TEST_LOGIN_NAME},
+		{"rec-test-submitted", "rectestcats", TEST_RECOMMENDED_LOGIN_NAME},
+		{"rec-test-nsfw", "rectestcat,NSFW", TEST_LOGIN_NAME},
+		// plural variant of starred link's cat
+		{"rec-test-cat", "rectestcats", TEST_LOGIN_NAME},
+		{"rec-test-tag-cat", "rectesttagged", TEST_LOGIN_NAME},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, ?, ?);`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			tl.SubmittedBy,
+			now,
+			tl.Cats,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, stmt := range []struct {
+		SQL  string
+		Args []any
+	}{
+		{
+			`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp) VALUES (?, ?, ?, 3, ?);`,
+			[]any{"rec-test-star", "rec-test-starred", TEST_RECOMMENDED_USER_ID, now},
+		},
+		{
+			`INSERT INTO Tags (id, link_id, cats, submitted_by, last_updated) VALUES (?, ?, ?, ?, ?);`,
+			[]any{"rec-test-tag", "rec-test-tagged", "rectesttagged", TEST_RECOMMENDED_LOGIN_NAME, now},
+		},
+		{
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp) VALUES (?, ?, ?, '', ?);`,
+			[]any{"rec-test-click-1", "rec-test-clicked", TEST_RECOMMENDED_USER_ID, now},
+		},
+		{
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp) VALUES (?, ?, ?, '', ?);`,
+			[]any{"rec-test-click-2", "rec-test-clicked", TEST_RECOMMENDED_USER_ID, now},
+		},
+	} {
+		if _, err := TestClient.Exec(stmt.SQL, stmt.Args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		TestClient.Exec("DELETE FROM Stars WHERE user_id = ?;", TEST_RECOMMENDED_USER_ID)
+		TestClient.Exec("DELETE FROM Tags WHERE submitted_by = ?;", TEST_RECOMMENDED_LOGIN_NAME)
+		TestClient.Exec("DELETE FROM Clicks WHERE user_id = ?;", TEST_RECOMMENDED_USER_ID)
+		for _, tl := range test_links {
+			TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+		}
+	}
+}
+
+func TestGetCatAffinityProfile(t *testing.T) {
+	defer insertRecommendedTestData(t)()
+
+	profile, err := GetCatAffinityProfile(query.NewCatAffinitySources(
+		TEST_RECOMMENDED_USER_ID,
+		TEST_RECOMMENDED_LOGIN_NAME,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected_profile := []model.CatAffinity{
+		{Cat: "rectestcat", Weight: 3, StarredLinkID: "rec-test-starred", StarredLinkURL: "https://rec-test-starred.com"},
+		{Cat: "rectestother", Weight: 3, StarredLinkID: "rec-test-starred", StarredLinkURL: "https://rec-test-starred.com"},
+		{Cat: "rectesttagged", Weight: RECOMMENDED_LINKS_TAG_WEIGHT},
+		{Cat: "rectestclicked", Weight: 2 * RECOMMENDED_LINKS_CLICK_WEIGHT},
+	}
+	if !slices.Equal(profile, expected_profile) {
+		t.Fatalf("expected profile %+v, got %+v", expected_profile, profile)
+	}
+
+	if cats := GetTopAffinityCats(profile); len(cats) != len(profile) || cats[0] != "rectestcat" {
+		t.Fatalf("unexpected top affinity cats %v", cats)
+	}
+}
+
+func TestScanRecommendedLinks(t *testing.T) {
+	defer insertRecommendedTestData(t)()
+
+	profile, err := GetCatAffinityProfile(query.NewCatAffinitySources(
+		TEST_RECOMMENDED_USER_ID,
+		TEST_RECOMMENDED_LOGIN_NAME,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recommended_links, err := ScanRecommendedLinks(
+		query.NewRecommendedLinks(
+			TEST_RECOMMENDED_USER_ID,
+			TEST_RECOMMENDED_LOGIN_NAME,
+			GetTopAffinityCats(profile),
+		),
+		profile,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// submitted, starred, tagged, clicked and NSFW links are excluded
+	expected_reasons := map[string]string{
+		"rec-test-cat":     "because you starred https://rec-test-starred.com",
+		"rec-test-tag-cat": "because you like rectesttagged",
+	}
+	if len(*recommended_links) != len(expected_reasons) {
+		t.Fatalf("expected %d recommended links, got %+v", len(expected_reasons), *recommended_links)
+	}
+	for _, rl := range *recommended_links {
+		if reason, ok := expected_reasons[rl.ID]; !ok {
+			t.Fatalf("unexpected recommended link %s", rl.ID)
+		} else if rl.Reason != reason {
+			t.Fatalf("link %s: expected reason %q, got %q", rl.ID, reason, rl.Reason)
+		}
+	}
+}
+
+func TestGetRecommendedLinkScore(t *testing.T) {
+	profile := []model.CatAffinity{
+		{Cat: "go", Weight: 4},
+		{Cat: "flowers", Weight: 2},
+	}
+
+	var test_links = []struct {
+		Cats              string
+		Rating            float32
+		ExpectedScore     float64
+		ExpectedReasonCat string
+	}{
+		{"go", 0, RECOMMENDED_LINKS_AFFINITY_WEIGHT, "go"},
+		// variant
+		{"flower", 0, RECOMMENDED_LINKS_AFFINITY_WEIGHT / 2, "flowers"},
+		// affinity is capped at the top cat's
+		{"go,flowers", 5, RECOMMENDED_LINKS_AFFINITY_WEIGHT + RECOMMENDED_LINKS_QUALITY_WEIGHT, "go"},
+		{"rust", 2.5, RECOMMENDED_LINKS_QUALITY_WEIGHT / 2, ""},
+	}
+
+	for _, tl := range test_links {
+		score, top_affinity := GetRecommendedLinkScore(tl.Cats, tl.Rating, profile)
+		if score != tl.ExpectedScore {
+			t.Fatalf("cats %q: expected score %f, got %f", tl.Cats, tl.ExpectedScore, score)
+		}
+
+		var reason_cat string
+		if top_affinity != nil {
+			reason_cat = top_affinity.Cat
+		}
+		if reason_cat != tl.ExpectedReasonCat {
+			t.Fatalf("cats %q: expected reason cat %q, got %q", tl.Cats, tl.ExpectedReasonCat, reason_cat)
+		}
+	}
+}
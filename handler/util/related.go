@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func GetRelatedLinksOptionsFromRequestParams(params url.Values) (*model.RelatedLinksOptions, error) {
+	opts := &model.RelatedLinksOptions{}
+
+	nsfw_params := params.Get("include_nsfw")
+	if nsfw_params == "true" {
+		opts.IncludeNSFW = true
+	} else if nsfw_params != "false" && nsfw_params != "" {
+		return nil, e.ErrInvalidNSFWParams
+	}
+
+	return opts, nil
+}
+
+// Scans candidates and returns the RELATED_LINKS_LIMIT highest scoring
+// (see GetRelatedLinkScore()). Ties keep the query's order (most
+// co-starred, then most starred).
+func ScanRelatedLinks[T model.HasCats](related_sql *query.RelatedLinks, link *model.Link) (*[]T, error) {
+	if related_sql.Error != nil {
+		return nil, related_sql.Error
+	}
+
+	rows, err := related_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scoredLink struct {
+		Link  T
+		Score float64
+	}
+	var scored_links []scoredLink
+
+	for rows.Next() {
+		var related_link any
+		var pages, co_stars int
+
+		switch any(new(T)).(type) {
+		case *model.Link:
+			l := model.Link{}
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+				&co_stars,
+			); err != nil {
+				return nil, err
+			}
+			related_link = l
+		case *model.LinkSignedIn:
+			l := model.LinkSignedIn{}
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+				&l.StarsAssigned,
+				&co_stars,
+			); err != nil {
+				return nil, err
+			}
+			related_link = l
+		}
+
+		scored_links = append(scored_links, scoredLink{
+			Link: related_link.(T),
+			Score: GetRelatedLinkScore(
+				link.Cats,
+				related_link.(T).GetCats(),
+				co_stars,
+				link.TimesStarred,
+			),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(scored_links, func(a, b scoredLink) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(scored_links) > RELATED_LINKS_LIMIT {
+		scored_links = scored_links[:RELATED_LINKS_LIMIT]
+	}
+
+	related_links := make([]T, len(scored_links))
+	for i, sl := range scored_links {
+		related_links[i] = sl.Link
+	}
+
+	return &related_links, nil
+}
+
+// Weighted sum of the share of cats in common and the share of the
+// link's starrers who also starred the related link, both 0-1
+func GetRelatedLinkScore(link_cats string, related_link_cats string, co_stars int, link_times_starred int64) float64 {
+	score := RELATED_LINKS_CATS_WEIGHT * getSharedCatsRatio(link_cats, related_link_cats)
+	if link_times_starred > 0 {
+		score += RELATED_LINKS_CO_STARS_WEIGHT * float64(co_stars) / float64(link_times_starred)
+	}
+
+	return score
+}
+
+// Jaccard index of 2 links' cats: cats in common / all distinct cats.
+// Cats that resemble each other (e.g., "flower" and "Flowers") count as
+// the same.
+func getSharedCatsRatio(cats_a string, cats_b string) float64 {
+	if cats_a == "" || cats_b == "" {
+		return 0
+	}
+
+	split_a := strings.Split(cats_a, ",")
+	split_b := strings.Split(cats_b, ",")
+
+	var shared int
+	for _, a := range split_a {
+		if slices.ContainsFunc(split_b, func(b string) bool {
+			return CatsResembleEachOther(a, b)
+		}) {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(split_a)+len(split_b)-shared)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+	"github.com/julianlk522/modeep/query"
+)
+
+func TestGetRelatedLinksOptionsFromRequestParams(t *testing.T) {
+	var test_params = []struct {
+		IncludeNSFW string
+		Valid       bool
+	}{
+		{"", true},
+		{"true", true},
+		{"false", true},
+		{"yes", false},
+	}
+
+	for _, tp := range test_params {
+		params := url.Values{}
+		if tp.IncludeNSFW != "" {
+			params.Set("include_nsfw", tp.IncludeNSFW)
+		}
+		opts, err := GetRelatedLinksOptionsFromRequestParams(params)
+		if tp.Valid && err != nil {
+			t.Fatalf("include_nsfw %q: %s", tp.IncludeNSFW, err)
+		} else if !tp.Valid && err == nil {
+			t.Fatalf("expected error for include_nsfw %q", tp.IncludeNSFW)
+		} else if tp.Valid && opts.IncludeNSFW != (tp.IncludeNSFW == "true") {
+			t.Fatalf("include_nsfw %q: got IncludeNSFW %t", tp.IncludeNSFW, opts.IncludeNSFW)
+		}
+	}
+}
+
+func TestGetRelatedLinkScore(t *testing.T) {
+	var test_links = []struct {
+		LinkCats         string
+		RelatedLinkCats  string
+		CoStars          int
+		LinkTimesStarred int64
+		ExpectedScore    float64
+	}{
+		{"go,sqlite", "go,sqlite", 0, 0, RELATED_LINKS_CATS_WEIGHT},
+		// spelling variants are the same cat
+		{"flower,umvc3", "Flowers,umvc3", 0, 0, RELATED_LINKS_CATS_WEIGHT},
+		// 1 of 3 distinct cats shared
+		{"go,sqlite", "go,rust", 0, 0, RELATED_LINKS_CATS_WEIGHT / 3},
+		// 2 of 4 starrers also starred related link
+		{"go", "rust", 2, 4, RELATED_LINKS_CO_STARS_WEIGHT / 2},
+		{"go", "go", 4, 4, RELATED_LINKS_CATS_WEIGHT + RELATED_LINKS_CO_STARS_WEIGHT},
+		{"", "go", 0, 0, 0},
+	}
+
+	for _, tl := range test_links {
+		got := GetRelatedLinkScore(tl.LinkCats, tl.RelatedLinkCats, tl.CoStars, tl.LinkTimesStarred)
+		if math.Abs(got-tl.ExpectedScore) > 1e-9 {
+			t.Fatalf("%+v: expected score %f, got %f", tl, tl.ExpectedScore, got)
+		}
+	}
+}
+
+func TestScanRelatedLinks(t *testing.T) {
+	link_sql := query.NewSingleLink(TEST_LINK_ID)
+	link, err := ScanSingleLink[model.Link](link_sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	related_sql := query.NewRelatedLinks(TEST_LINK_ID, strings.Split(link.Cats, ","))
+	related_links, err := ScanRelatedLinks[model.Link](related_sql, link)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(*related_links) > RELATED_LINKS_LIMIT {
+		t.Fatalf("expected at most %d related links, got %d", RELATED_LINKS_LIMIT, len(*related_links))
+	}
+
+	for _, rl := range *related_links {
+		if rl.ID == TEST_LINK_ID {
+			t.Fatal("link returned as related to itself")
+		}
+	}
+
+	related_sql = query.NewRelatedLinks(TEST_LINK_ID, strings.Split(link.Cats, ","))
+	related_sql, err = related_sql.FromOptions(&model.RelatedLinksOptions{AsSignedInUser: TEST_USER_ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := ScanRelatedLinks[model.LinkSignedIn](related_sql, link); err != nil {
+		t.Fatal(err)
+	}
+}
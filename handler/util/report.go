@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+// Hidden content can't be reported again, and neither can links that
+// aren't visible to the user
+func ReportedContentIsVisibleToUser(content_type model.ReportContentType, content_id string, login_name string) (bool, error) {
+	var content_sql string
+	switch content_type {
+	case model.ReportedLink:
+		return LinkIsVisibleToUser(content_id, login_name)
+	case model.ReportedSummary:
+		content_sql = `SELECT link_id FROM "Visible Summaries" WHERE id = ?;`
+	case model.ReportedTag:
+		content_sql = `SELECT link_id FROM "Visible Tags" WHERE id = ?;`
+	default:
+		return false, e.ErrInvalidReportContentType
+	}
+
+	var link_id string
+	err := db.Client.QueryRow(content_sql, content_id).Scan(&link_id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return LinkIsVisibleToUser(link_id, login_name)
+}
+
+// e.ErrAlreadyReported if the user has an open report on the same content
+func CreateReport(request *model.NewReportRequest, login_name string) error {
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var already_reported bool
+	if err := tx.QueryRow(
+		`SELECT COUNT(*) > 0
+		FROM Reports
+		WHERE content_type = ? AND content_id = ? AND reported_by = ? AND status = ?;`,
+		request.ContentType,
+		request.ContentID,
+		login_name,
+		model.ReportOpen,
+	).Scan(&already_reported); err != nil {
+		return err
+	} else if already_reported {
+		return e.ErrAlreadyReported
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO Reports (id, content_type, content_id, reason, details, reported_by, reported_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		request.ID,
+		request.ContentType,
+		request.ContentID,
+		request.Reason,
+		request.Details,
+		login_name,
+		request.ReportedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func GetReportsPage(opts *model.ReportsOptions) (*model.ReportsPage, error) {
+	reports_sql, err := query.NewReports().FromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := reports_sql.ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.ReportsPage{Reports: []model.Report{}}
+	for rows.Next() {
+		var r model.Report
+		if err := rows.Scan(
+			&r.ID,
+			&r.ContentType,
+			&r.ContentID,
+			&r.Reason,
+			&r.Details,
+			&r.ReportedBy,
+			&r.ReportedAt,
+			&r.Status,
+			&r.ResolvedBy,
+			&r.ResolvedAt,
+			&r.OpenReportCount,
+		); err != nil {
+			return nil, err
+		}
+		page.Reports = append(page.Reports, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Reports) > query.REPORTS_PAGE_LIMIT {
+		page.Reports = page.Reports[:query.REPORTS_PAGE_LIMIT]
+		page.NextPage = int(max(opts.Page, 1)) + 1
+	}
+
+	return page, nil
+}
+
+// sql.ErrNoRows if no report with ID
+func GetReport(report_id string) (*model.Report, error) {
+	r := &model.Report{}
+	if err := db.Client.QueryRow(
+		`SELECT id, content_type, content_id, reason, details, reported_by, reported_at, status, resolved_by, resolved_at
+		FROM Reports
+		WHERE id = ?;`,
+		report_id,
+	).Scan(
+		&r.ID,
+		&r.ContentType,
+		&r.ContentID,
+		&r.Reason,
+		&r.Details,
+		&r.ReportedBy,
+		&r.ReportedAt,
+		&r.Status,
+		&r.ResolvedBy,
+		&r.ResolvedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Closes every open report on the same content as report, hiding the
+// content first if action is hide
+func ModerateReport(report *model.Report, action model.ReportAction, admin_login_name string) error {
+	if report.Status != model.ReportOpen {
+		return e.ErrReportAlreadyClosed
+	}
+
+	now := mutil.NEW_LONG_TIMESTAMP()
+	// (content may have been deleted by its submitter since being reported)
+	if action == model.ReportActionHide {
+		if err := HideContent(report.ContentType, report.ContentID, admin_login_name, now); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	_, err := db.Client.Exec(
+		`UPDATE Reports
+		SET status = ?, resolved_by = ?, resolved_at = ?
+		WHERE content_type = ? AND content_id = ? AND status = ?;`,
+		action.Status(),
+		admin_login_name,
+		now,
+		report.ContentType,
+		report.ContentID,
+		model.ReportOpen,
+	)
+	return err
+}
+
+// Hidden links' cats stop counting toward spellfix ranks, and a link's
+// global cats or summary is recalculated without its hidden tags or
+// summaries
+func HideContent(content_type model.ReportContentType, content_id string, admin_login_name string, hidden_at string) error {
+	var link_id string
+	switch content_type {
+	case model.ReportedLink:
+		return hideLink(content_id, admin_login_name, hidden_at)
+	case model.ReportedSummary:
+		if err := db.Client.QueryRow(
+			"SELECT link_id FROM Summaries WHERE id = ?;",
+			content_id,
+		).Scan(&link_id); err != nil {
+			return err
+		}
+	case model.ReportedTag:
+		if err := db.Client.QueryRow(
+			"SELECT link_id FROM Tags WHERE id = ?;",
+			content_id,
+		).Scan(&link_id); err != nil {
+			return err
+		}
+	default:
+		return e.ErrInvalidReportContentType
+	}
+
+	if _, err := db.Client.Exec(
+		INSERT_HIDDEN_CONTENT,
+		content_type,
+		content_id,
+		admin_login_name,
+		hidden_at,
+	); err != nil {
+		return err
+	}
+
+	if content_type == model.ReportedSummary {
+		return CalculateAndSetGlobalSummary(link_id)
+	}
+	return CalculateAndSetGlobalCats(link_id)
+}
+
+func hideLink(link_id string, admin_login_name string, hidden_at string) error {
+	// (before hiding, since hidden links aren't public)
+	is_public, err := LinkIsPublic(link_id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		INSERT_HIDDEN_CONTENT,
+		model.ReportedLink,
+		link_id,
+		admin_login_name,
+		hidden_at,
+	); err != nil {
+		return err
+	}
+
+	if is_public {
+		var global_cats string
+		if err := tx.QueryRow(
+			"SELECT COALESCE(global_cats, '') FROM Links WHERE id = ?;",
+			link_id,
+		).Scan(&global_cats); err != nil {
+			return err
+		}
+		if err := DecrementSpellfixRanksForCats(tx, strings.Split(global_cats, ",")); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const INSERT_HIDDEN_CONTENT = `INSERT OR IGNORE INTO "Hidden Content" (content_type, content_id, hidden_by, hidden_at)
+VALUES (?, ?, ?, ?);`
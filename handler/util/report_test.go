@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/julianlk522/modeep/db"
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestReports(t *testing.T) {
+	const (
+		test_link_id  = "report-test"
+		test_tag_id   = "report-test-tag"
+		spam_summary  = "report-test-summary-spam"
+		other_summary = "report-test-summary-other"
+	)
+	if _, err := db.Client.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, global_summary)
+		VALUES (?, 'https://report-test.com', 'bradley', '2025-01-01', 'reporttestcat', 'aaa buy now');`,
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Client.Exec(
+		`INSERT INTO Tags (id, link_id, cats, submitted_by, last_updated)
+		VALUES (?, ?, 'reporttestcat', 'bradley', '2025-01-01 00:00:00');`,
+		test_tag_id,
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	for id, text := range map[string]string{
+		spam_summary:  "aaa buy now",
+		other_summary: "a fine site",
+	} {
+		if _, err := db.Client.Exec(
+			`INSERT INTO Summaries (id, text, link_id, submitted_by, last_updated)
+			VALUES (?, ?, ?, ?, '2025-01-01 00:00:00');`,
+			id,
+			text,
+			test_link_id,
+			TEST_USER_ID,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := IncrementSpellfixRanksForCats(nil, []string{"reporttestcat"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		db.Client.Exec("DELETE FROM Links WHERE id = ?;", test_link_id)
+		db.Client.Exec("DELETE FROM Tags WHERE link_id = ?;", test_link_id)
+		db.Client.Exec("DELETE FROM Summaries WHERE link_id = ?;", test_link_id)
+		db.Client.Exec("DELETE FROM Reports WHERE content_id LIKE 'report-test%';")
+		db.Client.Exec(`DELETE FROM "Hidden Content" WHERE content_id LIKE 'report-test%';`)
+		db.Client.Exec("DELETE FROM global_cats_spellfix WHERE word = 'reporttestcat';")
+	}()
+
+	new_report := func(id string, content_type model.ReportContentType, content_id string, login_name string) error {
+		return CreateReport(&model.NewReportRequest{
+			ID:          id,
+			ContentType: content_type,
+			ContentID:   content_id,
+			Reason:      model.ReportSpam,
+			ReportedAt:  "2025-01-02 00:00:00",
+		}, login_name)
+	}
+
+	// Create
+	for _, tr := range []struct {
+		ID          string
+		ContentType model.ReportContentType
+		ContentID   string
+		LoginName   string
+		ExpectedErr error
+	}{
+		{"report-test-1", model.ReportedSummary, spam_summary, TEST_LOGIN_NAME, nil},
+		{"report-test-2", model.ReportedSummary, spam_summary, TEST_LOGIN_NAME, e.ErrAlreadyReported},
+		{"report-test-3", model.ReportedSummary, spam_summary, "bradley", nil},
+		{"report-test-4", model.ReportedTag, test_tag_id, TEST_LOGIN_NAME, nil},
+		{"report-test-5", model.ReportedLink, test_link_id, TEST_LOGIN_NAME, nil},
+	} {
+		if err := new_report(tr.ID, tr.ContentType, tr.ContentID, tr.LoginName); err != tr.ExpectedErr {
+			t.Fatalf("report %s: expected %v, got %v", tr.ID, tr.ExpectedErr, err)
+		}
+	}
+
+	open_reports, err := GetReportsPage(&model.ReportsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(open_reports.Reports, func(r model.Report) bool {
+		return r.ID == "report-test-1"
+	})
+	if i == -1 {
+		t.Fatal("expected report-test-1 in open reports")
+	} else if open_reports.Reports[i].OpenReportCount != 2 {
+		t.Fatalf("expected 2 open reports on summary, got %d", open_reports.Reports[i].OpenReportCount)
+	}
+
+	// Hide summary: closes both reports and recalculates global summary
+	moderate := func(report_id string, action model.ReportAction) error {
+		report, err := GetReport(report_id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ModerateReport(report, action, "admin")
+	}
+	if err := moderate("report-test-1", model.ReportActionHide); err != nil {
+		t.Fatal(err)
+	} else if err := moderate("report-test-3", model.ReportActionHide); err != e.ErrReportAlreadyClosed {
+		t.Fatalf("expected ErrReportAlreadyClosed, got %v", err)
+	}
+	if visible, err := ReportedContentIsVisibleToUser(model.ReportedSummary, spam_summary, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	} else if visible {
+		t.Fatal("expected hidden summary not to be visible")
+	}
+	var global_summary string
+	if err := db.Client.QueryRow(
+		"SELECT global_summary FROM Links WHERE id = ?;",
+		test_link_id,
+	).Scan(&global_summary); err != nil {
+		t.Fatal(err)
+	} else if global_summary != "a fine site" {
+		t.Fatalf("expected global summary to skip hidden summary, got %q", global_summary)
+	}
+
+	// Dismiss tag report: tag stays visible
+	if err := moderate("report-test-4", model.ReportActionDismiss); err != nil {
+		t.Fatal(err)
+	} else if visible, err := ReportedContentIsVisibleToUser(model.ReportedTag, test_tag_id, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	} else if !visible {
+		t.Fatal("expected tag with dismissed report to be visible")
+	}
+
+	// Hide tag: link's only tag, so no global cats remain
+	if err := new_report("report-test-6", model.ReportedTag, test_tag_id, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	} else if err := moderate("report-test-6", model.ReportActionHide); err != nil {
+		t.Fatal(err)
+	}
+	var global_cats string
+	if err := db.Client.QueryRow(
+		"SELECT global_cats FROM Links WHERE id = ?;",
+		test_link_id,
+	).Scan(&global_cats); err != nil {
+		t.Fatal(err)
+	} else if global_cats != "" {
+		t.Fatalf("expected no global cats after hiding only tag, got %q", global_cats)
+	}
+	if err := IncrementSpellfixRanksForCats(nil, []string{"reporttestcat"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Client.Exec(
+		"UPDATE Links SET global_cats = 'reporttestcat' WHERE id = ?;",
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide link: only visible to submitter and cats no longer ranked
+	if err := moderate("report-test-5", model.ReportActionHide); err != nil {
+		t.Fatal(err)
+	}
+	for login_name, expected := range map[string]bool{
+		"bradley":       true,
+		TEST_LOGIN_NAME: false,
+		"":              false,
+	} {
+		if visible, err := LinkIsVisibleToUser(test_link_id, login_name); err != nil {
+			t.Fatal(err)
+		} else if visible != expected {
+			t.Fatalf("expected hidden link visible to %q: %t, got %t", login_name, expected, visible)
+		}
+	}
+	var rank int
+	if err := db.Client.QueryRow(
+		"SELECT COALESCE(MAX(rank), 0) FROM global_cats_spellfix WHERE word = 'reporttestcat';",
+	).Scan(&rank); err != nil {
+		t.Fatal(err)
+	} else if rank != 0 {
+		t.Fatalf("expected hidden link's cats to have spellfix rank 0, got %d", rank)
+	}
+
+	hidden_reports, err := GetReportsPage(&model.ReportsOptions{Status: model.ReportHidden})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hidden_ids []string
+	for _, r := range hidden_reports.Reports {
+		if r.ResolvedBy != "admin" {
+			t.Fatalf("expected report %s resolved by admin, got %q", r.ID, r.ResolvedBy)
+		}
+		hidden_ids = append(hidden_ids, r.ID)
+	}
+	for _, id := range []string{"report-test-1", "report-test-3", "report-test-5"} {
+		if !slices.Contains(hidden_ids, id) {
+			t.Fatalf("expected %s in hidden reports, got %v", id, hidden_ids)
+		}
+	}
+}
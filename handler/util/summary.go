@@ -217,7 +217,7 @@ func UserHasLikedSummary(user_id string, summary_id string) (bool, error) {
 func CalculateAndSetGlobalSummary(link_id string) error {
 	// If there are no summaries then should just be empty string
 	var summaries_count_for_link sql.NullInt32
-	err := db.Client.QueryRow("SELECT COUNT(id) FROM Summaries WHERE link_id = ?", link_id).Scan(&summaries_count_for_link)
+	err := db.Client.QueryRow(`SELECT COUNT(id) FROM "Visible Summaries" WHERE link_id = ?`, link_id).Scan(&summaries_count_for_link)
 	if err != nil {
 		return err
 	}
@@ -241,7 +241,7 @@ func CalculateAndSetGlobalSummary(link_id string) error {
 				CASE WHEN s.submitted_by = ? THEN 1 ELSE 0 END,
 				s.text ASC
 			) AS rank
-		FROM Summaries s
+		FROM "Visible Summaries" s
 		LEFT JOIN (
 			SELECT summary_id, COUNT(*) AS like_count
 			FROM "Summary Likes"
@@ -69,10 +69,14 @@ func GetTopGlobalCatsOptionsFromRequestParams(params url.Values) (*model.TopCatC
 
 	cat_filters_params := params.Get("cats")
 	if cat_filters_params != "" {
-		// Use raw values since the NOT IN clause in .fromCatFilters() needs
-		// this form as well as with spelling variants, so they are added
-		// later in that function.
-		opts.RawCatFilters = strings.Split(cat_filters_params, ",")
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
+		// Raw values are needed for the NOT IN clause in .fromCatFilters()
+		// as well as the MATCH arg with spelling variants
+		opts.RawCatFilters = cat_query.Cats()
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
 	}
 	neutered_params := params.Get("neutered")
 	if neutered_params != "" {
@@ -92,6 +96,14 @@ func GetTopGlobalCatsOptionsFromRequestParams(params url.Values) (*model.TopCatC
 	if url_lacks_params != "" {
 		opts.URLLacks = url_lacks_params
 	}
+	domain_params := params.Get("domain")
+	if domain_params != "" {
+		domain, err := GetLinkDomain(domain_params)
+		if err != nil {
+			return nil, e.ErrInvalidDomain
+		}
+		opts.Domain = domain
+	}
 	period_params := params.Get("period")
 	if period_params != "" {
 		period := model.Period(period_params)
@@ -107,6 +119,12 @@ func GetTopGlobalCatsOptionsFromRequestParams(params url.Values) (*model.TopCatC
 	} else if more_params != "" {
 		return nil, e.ErrInvalidMoreFlag
 	}
+	tree_params := params.Get("tree")
+	if tree_params == "true" {
+		opts.Tree = true
+	} else if tree_params != "false" && tree_params != "" {
+		return nil, e.ErrInvalidTreeParams
+	}
 
 	return opts, nil
 }
@@ -153,7 +171,18 @@ func GetSpellfixOptionsFromRequestParams(params url.Values) (*model.SpellfixMatc
 		// interprets the example above as a literal 19-character string, many
 		// edits away from "test." It's fine to forego normal variation matching
 		// here, spellfix helps with that anyway.
-		opts.CatFilters = strings.Split(cat_filters_params, ",")
+		// (They are still used to MATCH links with the cat filters.)
+		if opts.YouAreAddingCats {
+			// Cats being added, not a cats query
+			opts.CatFilters = strings.Split(cat_filters_params, ",")
+		} else {
+			cat_query, err := parseCatQueryParams(params)
+			if err != nil {
+				return nil, err
+			}
+			opts.CatFilters = cat_query.Cats()
+			opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
+		}
 	}
 	return opts, nil
 }
@@ -163,7 +192,9 @@ func CatsResembleEachOther(a string, b string) bool {
 	// Capitalization variants
 	if a == b ||
 		// Or singular/plural variants
-		a+"s" == b || b+"s" == a || a+"es" == b || b+"es" == a {
+		a+"s" == b || b+"s" == a || a+"es" == b || b+"es" == a ||
+		// Or approved synonyms
+		query.CatsAreSynonyms(a, b) {
 		return true
 	}
 
@@ -266,38 +297,84 @@ func IsOnlyTag(tag_id string) (bool, error) {
 	return true, nil
 }
 
-func CalculateAndSetGlobalCats(link_id string) error {
-	global_cats_sql := query.NewGlobalCatsForLink(link_id)
-	if global_cats_sql.Error != nil {
-		return global_cats_sql.Error
+// Inserts the tag then updates the link's global cats (and their spellfix
+// ranks) to include it
+func SaveNewTag(tx *sql.Tx, tag *model.NewTagRequest, submitted_by string) error {
+	tag.Cats = TidyCats(tag.Cats)
+	if _, err := tx.Exec(
+		"INSERT INTO Tags VALUES(?,?,?,?,?);",
+		tag.ID,
+		tag.LinkID,
+		tag.Cats,
+		submitted_by,
+		tag.LastUpdated,
+	); err != nil {
+		return err
 	}
 
-	var new_global_cats string
-	row, err := global_cats_sql.ValidateAndExecuteRow()
+	return recalculateGlobalCats(tx, tag.LinkID)
+}
+
+func CalculateAndSetGlobalCats(link_id string) error {
+	tx, err := db.Client.Begin()
 	if err != nil {
 		return err
 	}
-	if err = row.Scan(&new_global_cats); err != nil {
+	defer tx.Rollback()
+
+	if err = recalculateGlobalCats(tx, link_id); err != nil {
 		return err
 	}
-	if err = setGlobalCats(link_id, new_global_cats); err != nil {
+
+	return tx.Commit()
+}
+
+// For callers that change a link's tags in tx
+func recalculateGlobalCats(tx *sql.Tx, link_id string) error {
+	global_cats_sql := query.NewGlobalCatsForLink(link_id)
+	if global_cats_sql.Error != nil {
+		return global_cats_sql.Error
+	}
+
+	// (NULL if all of the link's tags are hidden)
+	var new_global_cats sql.NullString
+	if err := tx.QueryRow(
+		global_cats_sql.Text,
+		global_cats_sql.Args...,
+	).Scan(&new_global_cats); err != nil {
 		return err
 	}
 
-	return nil
+	return updateGlobalCats(tx, link_id, new_global_cats.String)
 }
 
 func setGlobalCats(link_id string, new_global_cats string) error {
-	cats_diff, err := getGlobalCatsDiff(link_id, new_global_cats)
+	tx, err := db.Client.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	tx, err := db.Client.Begin()
+	if err = updateGlobalCats(tx, link_id, new_global_cats); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Like setGlobalCats() but in the caller's tx
+func updateGlobalCats(tx *sql.Tx, link_id string, new_global_cats string) error {
+	cats_diff, err := getGlobalCatsDiff(tx, link_id, new_global_cats)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	var is_public bool
+	if err = tx.QueryRow(
+		`SELECT id IN (SELECT id FROM "Public Links") FROM Links WHERE id = ?;`,
+		link_id,
+	).Scan(&is_public); err != nil {
+		return err
+	}
 
 	_, err = tx.Exec(`
 		UPDATE Links 
@@ -308,24 +385,83 @@ func setGlobalCats(link_id string, new_global_cats string) error {
 	if err != nil {
 		return err
 	}
+	if err = setLinkGlobalCats(tx, link_id, new_global_cats); err != nil {
+		return err
+	}
+
+	// (private and hidden links' cats don't count toward spellfix ranks)
+	if is_public {
+		if err = IncrementSpellfixRanksForCats(tx, cats_diff.Added); err != nil {
+			return err
+		}
+		if err = DecrementSpellfixRanksForCats(tx, cats_diff.Removed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	if err = IncrementSpellfixRanksForCats(tx, cats_diff.Added); err != nil {
+// Keeps "Link Global Cats" and "Link Global Cat Pairs" in sync with
+// Links.global_cats, so that cats and cat co-occurrences can be counted
+// without splitting every link's cats
+func setLinkGlobalCats(tx *sql.Tx, link_id string, global_cats string) error {
+	if _, err := tx.Exec(
+		`DELETE FROM "Link Global Cats" WHERE link_id = ?;`,
+		link_id,
+	); err != nil {
 		return err
 	}
-	if err = DecrementSpellfixRanksForCats(tx, cats_diff.Removed); err != nil {
+	if _, err := tx.Exec(
+		`DELETE FROM "Link Global Cat Pairs" WHERE link_id = ?;`,
+		link_id,
+	); err != nil {
 		return err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return err
+	var normalized_cats []string
+	for _, cat := range strings.Split(global_cats, ",") {
+		if cat == "" {
+			continue
+		}
+		normalized_cat := query.GetSingularCat(strings.ToLower(cat))
+		if _, err := tx.Exec(
+			`INSERT OR IGNORE INTO "Link Global Cats" (link_id, cat, normalized_cat)
+			VALUES (?, ?, ?);`,
+			link_id,
+			cat,
+			normalized_cat,
+		); err != nil {
+			return err
+		}
+		if !slices.Contains(normalized_cats, normalized_cat) {
+			normalized_cats = append(normalized_cats, normalized_cat)
+		}
+	}
+
+	// (sorted so that each pair is stored once, as normalized_cat_a <
+	// normalized_cat_b)
+	slices.Sort(normalized_cats)
+	for i, cat_a := range normalized_cats {
+		for _, cat_b := range normalized_cats[i+1:] {
+			if _, err := tx.Exec(
+				`INSERT INTO "Link Global Cat Pairs" (link_id, normalized_cat_a, normalized_cat_b)
+				VALUES (?, ?, ?);`,
+				link_id,
+				cat_a,
+				cat_b,
+			); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func getGlobalCatsDiff(link_id string, new_cats_str string) (*model.GlobalCatsDiff, error) {
+func getGlobalCatsDiff(tx *sql.Tx, link_id string, new_cats_str string) (*model.GlobalCatsDiff, error) {
 	var old_cats_str string
-	err := db.Client.QueryRow(
+	err := tx.QueryRow(
 		"SELECT global_cats FROM Links WHERE id = ?;",
 		link_id,
 	).Scan(&old_cats_str)
@@ -28,11 +28,12 @@ func GetTmapOptionsFromRequestParams(params url.Values) (*model.TmapOptions, err
 
 	cat_filters_params := params.Get("cats")
 	if cat_filters_params != "" {
+		cat_query, err := parseCatQueryParams(params)
+		if err != nil {
+			return nil, err
+		}
 		opts.RawCatFiltersParams = cat_filters_params
-		cat_filters_with_spelling_variants := query.GetCatsOptionalPluralOrSingularForms(
-			strings.Split(cat_filters_params, ","),
-		)
-		opts.CatFiltersWithSpellingVariants = cat_filters_with_spelling_variants
+		opts.CatFiltersWithSpellingVariants = cat_query.MatchArgs()
 	}
 	neutered_cat_filters_params := params.Get("neutered")
 	if neutered_cat_filters_params != "" {
@@ -53,6 +54,11 @@ func GetTmapOptionsFromRequestParams(params url.Values) (*model.TmapOptions, err
 	if summary_contains_params != "" {
 		opts.SummaryContains = summary_contains_params
 	}
+	// (whitespace-only would be an empty FTS5 MATCH, so is ignored)
+	content_contains_params := strings.TrimSpace(params.Get("content_contains"))
+	if content_contains_params != "" {
+		opts.ContentContains = content_contains_params
+	}
 	url_contains_params := params.Get("url_contains")
 	if url_contains_params != "" {
 		opts.URLContains = url_contains_params
@@ -61,6 +67,14 @@ func GetTmapOptionsFromRequestParams(params url.Values) (*model.TmapOptions, err
 	if url_lacks_params != "" {
 		opts.URLLacks = url_lacks_params
 	}
+	domain_params := params.Get("domain")
+	if domain_params != "" {
+		domain, err := GetLinkDomain(domain_params)
+		if err != nil {
+			return nil, e.ErrInvalidDomain
+		}
+		opts.Domain = domain
+	}
 	var nsfw_params string
 	if params.Get("include_nsfw") != "" {
 		nsfw_params = params.Get("include_nsfw")
@@ -70,6 +84,12 @@ func GetTmapOptionsFromRequestParams(params url.Values) (*model.TmapOptions, err
 	} else if nsfw_params != "false" && nsfw_params != "" {
 		return nil, e.ErrInvalidNSFWParams
 	}
+	exclude_dead_params := params.Get("exclude_dead")
+	if exclude_dead_params == "true" {
+		opts.ExcludeDead = true
+	} else if exclude_dead_params != "false" && exclude_dead_params != "" {
+		return nil, e.ErrInvalidExcludeDeadParams
+	}
 	sort_params := params.Get("sort_by")
 	if sort_params != "" {
 		sort_by := model.SortBy(sort_params)
@@ -108,10 +128,32 @@ func GetTmapOptionsFromRequestParams(params url.Values) (*model.TmapOptions, err
 		}
 		opts.Page = page
 	}
+	cursor_params := params.Get("cursor")
+	if cursor_params != "" {
+		if opts.Section == "" {
+			return nil, e.ErrInvalidCursor
+		}
+		cursor, err := query.DecodeLinksCursor(cursor_params, getTmapSortBy(opts))
+		if err != nil {
+			return nil, err
+		}
+		opts.Cursor = cursor
+	}
 
 	return opts, nil
 }
 
+// content_contains without sort_by sorts by relevance
+// (see TmapSubmitted.FromOptions())
+func getTmapSortBy(opts *model.TmapOptions) model.SortBy {
+	if opts.SortBy != "" {
+		return opts.SortBy
+	} else if opts.ContentContains != "" {
+		return model.SortByRelevance
+	}
+	return model.SortByTimesStarred
+}
+
 func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model.TmapOptions) (any, error) {
 	if opts.OwnerLoginName == "" {
 		return nil, e.ErrNoTmapOwnerLoginName
@@ -122,11 +164,15 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 	var nsfw_links_count int
 	nsfw_links_count_opts := &model.TmapNSFWLinksCountOptions{
 		Section:                        opts.Section,
+		IncludePrivate:                 opts.IncludePrivate,
 		CatFiltersWithSpellingVariants: opts.CatFiltersWithSpellingVariants,
 		Period:                         opts.Period,
 		SummaryContains:                opts.SummaryContains,
+		ContentContains:                opts.ContentContains,
 		URLContains:                    opts.URLContains,
 		URLLacks:                       opts.URLLacks,
+		Domain:                         opts.Domain,
+		ExcludeDead:                    opts.ExcludeDead,
 	}
 	nsfw_links_count_sql, err := query.
 		NewTmapNSFWLinksCount(tmap_owner).
@@ -151,7 +197,7 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 			RawCatsParams: opts.RawCatFiltersParams,
 		}
 
-		cat_filters = strings.Split(opts.RawCatFiltersParams, ",")
+		cat_filters = GetCatsFromCatQueryParams(opts.RawCatFiltersParams)
 	}
 
 	if opts.Section != "" {
@@ -177,10 +223,22 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 		cat_counts := getCatCountsFromTmapLinks(links, cat_counts_opts)
 
 		// Pagination
-		links, pages, err := paginateIndividualTmapSection(opts.Page, links)
+		page := opts.Page
+		if opts.Cursor != nil {
+			links = getTmapLinksAfterCursor(links, opts.Cursor)
+			page = 1
+		}
+		links, pages, err := paginateIndividualTmapSection(page, links)
 		if err != nil {
 			return nil, err
 		}
+		var next_cursor string
+		if max(page, 1) < pages {
+			next_cursor = query.EncodeLinksCursor(
+				getTmapSortBy(opts),
+				getTmapLink((*links)[len(*links)-1]),
+			)
+		}
 
 		if has_cat_filter {
 			// Indicate any merged cats
@@ -193,6 +251,8 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 					Links:          links,
 					Cats:           cat_counts,
 					Pages:          pages,
+					NextCursor:     next_cursor,
+					OffsetOnly:     query.SortByIsOffsetOnly(getTmapSortBy(opts)),
 					NSFWLinksCount: nsfw_links_count,
 				},
 				MergedCats: merged_cats,
@@ -202,6 +262,8 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 				Links:          links,
 				Cats:           cat_counts,
 				Pages:          pages,
+				NextCursor:     next_cursor,
+				OffsetOnly:     query.SortByIsOffsetOnly(getTmapSortBy(opts)),
 				NSFWLinksCount: nsfw_links_count,
 			}, nil
 		}
@@ -215,9 +277,16 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 		starred := all_tmap_links.Starred
 		tagged := all_tmap_links.Tagged
 
+		collections, err := GetCollectionsCreatedByUser(tmap_owner, false)
+		if err != nil {
+			return nil, err
+		}
+
 		if len(*submitted)+len(*starred)+len(*tagged) == 0 {
 			return model.TmapPage[T]{
-				TmapSections: &model.TmapSections[T]{},
+				TmapSections: &model.TmapSections[T]{
+					Collections: collections,
+				},
 
 				// There are not necessarily 0 NSFW links if the sections
 				// are all empty: the NSFW links may be hidden
@@ -241,6 +310,7 @@ func BuildTmapFromOptions[T model.TmapLink | model.TmapLinkSignedIn](opts *model
 			},
 		)
 		tmap_sections.Cats = cat_counts
+		tmap_sections.Collections = collections
 
 		if has_cat_filter {
 			// Indicate any merged cats
@@ -308,6 +378,34 @@ func paginateIndividualTmapSection[T model.TmapLink | model.TmapLinkSignedIn](pa
 	return links, pages, nil
 }
 
+// Sections are loaded whole, so this finds the cursor link itself,
+// or if it is no longer in the section, the first link sorted after it
+func getTmapLinksAfterCursor[T model.TmapLink | model.TmapLinkSignedIn](links *[]T, cursor *model.LinksCursor) *[]T {
+	for i, link := range *links {
+		if getTmapLink(link).ID == cursor.ID {
+			after := (*links)[i+1:]
+			return &after
+		}
+	}
+	for i, link := range *links {
+		if query.LinkIsAfterCursor(getTmapLink(link), cursor) {
+			after := (*links)[i:]
+			return &after
+		}
+	}
+	return &[]T{}
+}
+
+func getTmapLink[T model.TmapLink | model.TmapLinkSignedIn](link T) *model.Link {
+	switch l := any(link).(type) {
+	case model.TmapLinkSignedIn:
+		return &l.Link
+	case model.TmapLink:
+		return &l.Link
+	}
+	return nil
+}
+
 func getAllTmapSectionsForOwnerFromOpts[T model.TmapLink | model.TmapLinkSignedIn](tmap_owner_login_name string, opts *model.TmapOptions) (*struct {
 	Submitted *[]T
 	Starred   *[]T
@@ -399,10 +497,15 @@ func scanTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](q *query.Query) (*
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
-				&l.PreviewImgFilename)
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet)
 			if err != nil {
 				return nil, err
 			}
@@ -426,10 +529,15 @@ func scanTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](q *query.Query) (*
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
+				&l.CommentCount,
 				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
 
 				// signed-in only
 				&l.StarsAssigned,
@@ -454,7 +562,9 @@ func getCatCountsFromTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](links
 	// Use raw_cats_params to determine omitted_cats because CatFilters
 	// (from BuildTmapFromOpts) is modified to escape reserved chars
 	if opts != nil && opts.RawCatsParams != "" {
-		omitted_cats = strings.Split(strings.ToLower(opts.RawCatsParams), ",")
+		for _, cat := range GetCatsFromCatQueryParams(opts.RawCatsParams) {
+			omitted_cats = append(omitted_cats, strings.ToLower(cat))
+		}
 	}
 	has_cat_filter := len(omitted_cats) > 0
 
@@ -471,6 +581,7 @@ func getCatCountsFromTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](links
 		}
 
 		link_cats := strings.Split(cats_str, ",")
+		var link_counted_cats []string
 		for i, lc := range link_cats {
 			if strings.TrimSpace(lc) == "" {
 				continue
@@ -498,26 +609,43 @@ func getCatCountsFromTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](links
 			}
 
 			if !skip {
-				// Increment count if existing
-				found := false
-				for _, found_cat := range all_found_cats {
-					if found_cat == lc {
-						found = true
-
-						for i, count := range counts {
-							if count.Category == lc {
-								counts[i].Count++
-								break
-							}
+				link_counted_cats = append(link_counted_cats, lc)
+			}
+		}
+
+		// Roll up to ancestors, e.g., "sqlite" also counts toward
+		// "databases", unless the link already has (or the cat filters
+		// include) the ancestor
+		for _, lc := range link_counted_cats {
+			for _, ancestor := range query.GetCatAncestors(lc) {
+				if !catResemblesAny(ancestor, link_cats) &&
+					!catResemblesAny(ancestor, omitted_cats) &&
+					!catResemblesAny(ancestor, link_counted_cats) {
+					link_counted_cats = append(link_counted_cats, ancestor)
+				}
+			}
+		}
+
+		for _, lc := range link_counted_cats {
+			// Increment count if existing
+			found := false
+			for _, found_cat := range all_found_cats {
+				if found_cat == lc {
+					found = true
+
+					for i, count := range counts {
+						if count.Category == lc {
+							counts[i].Count++
+							break
 						}
 					}
 				}
+			}
 
-				// Or create new count
-				if !found {
-					counts = append(counts, model.CatCount{Category: lc, Count: 1})
-					all_found_cats = append(all_found_cats, lc)
-				}
+			// Or create new count
+			if !found {
+				counts = append(counts, model.CatCount{Category: lc, Count: 1})
+				all_found_cats = append(all_found_cats, lc)
 			}
 		}
 	}
@@ -531,6 +659,12 @@ func getCatCountsFromTmapLinks[T model.TmapLink | model.TmapLinkSignedIn](links
 	return &counts
 }
 
+func catResemblesAny(cat string, others []string) bool {
+	return slices.ContainsFunc(others, func(other string) bool {
+		return CatsResembleEachOther(cat, other)
+	})
+}
+
 func mergeCountsOfCatSpellingVariants(counts *[]model.CatCount) {
 	// Sort first so most-frequent spelling / casing variants are the ones merged into
 	slices.SortFunc(*counts, model.SortCats)
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+	"github.com/julianlk522/modeep/query"
+)
+
+// Defaults to JSON if no format provided
+func GetTmapExportFormatFromRequestParams(params url.Values) (model.TmapExportFormat, error) {
+	format_params := strings.ToLower(params.Get("format"))
+	if format_params == "" {
+		return model.TmapExportFormatJSON, nil
+	}
+
+	format := model.TmapExportFormat(format_params)
+	for _, f := range model.ValidTmapExportFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", e.ErrInvalidTmapExportFormat
+}
+
+// All links in opts.Section (or every section if none), unpaginated
+// (opts.Page is ignored)
+func GetTmapExportLinks(opts *model.TmapOptions) (*[]model.TmapExportLink, error) {
+	if opts.OwnerLoginName == "" {
+		return nil, e.ErrNoTmapOwnerLoginName
+	}
+	tmap_owner := opts.OwnerLoginName
+
+	sections := model.ValidTmapSections[:]
+	if opts.Section != "" {
+		sections = []model.TmapIndividualSectionName{opts.Section}
+	}
+
+	details, err := getTmapExportDetails(tmap_owner)
+	if err != nil {
+		return nil, err
+	}
+
+	export_links := []model.TmapExportLink{}
+	for _, section := range sections {
+		links, err := buildAndScanTmapSectionQuery[model.TmapLink](
+			getTmapQueryBuilderForSectionForOwner(section, tmap_owner),
+			opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range *links {
+			el := model.TmapExportLink{
+				Section:      section,
+				ID:           l.ID,
+				URL:          l.URL,
+				SubmittedBy:  l.SubmittedBy,
+				SubmitDate:   l.SubmitDate,
+				Cats:         l.Cats,
+				CatsFromUser: l.CatsFromUser,
+				Summary:      l.Summary,
+			}
+			if d, ok := details[l.ID]; ok {
+				el.GlobalCats = d.GlobalCats
+				el.StarsAssigned = d.StarsAssigned
+				el.StarredAt = d.StarredAt
+				el.TaggedAt = d.TaggedAt
+			}
+			export_links = append(export_links, el)
+		}
+	}
+
+	return &export_links, nil
+}
+
+// Keyed by link ID
+func getTmapExportDetails(tmap_owner string) (map[string]model.TmapExportLink, error) {
+	rows, err := query.NewTmapExportDetails(tmap_owner).ValidateAndExecuteRows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details := map[string]model.TmapExportLink{}
+	for rows.Next() {
+		var d model.TmapExportLink
+		if err := rows.Scan(
+			&d.ID,
+			&d.GlobalCats,
+			&d.StarsAssigned,
+			&d.StarredAt,
+			&d.TaggedAt,
+		); err != nil {
+			return nil, err
+		}
+		details[d.ID] = d
+	}
+
+	return details, rows.Err()
+}
+
+func WriteTmapExport(w io.Writer, links *[]model.TmapExportLink, format model.TmapExportFormat, tmap_owner string) error {
+	switch format {
+	case model.TmapExportFormatNetscape:
+		return writeTmapExportNetscape(w, links, tmap_owner)
+	case model.TmapExportFormatJSON:
+		return json.NewEncoder(w).Encode(links)
+	case model.TmapExportFormatCSV:
+		return writeTmapExportCSV(w, links)
+	default:
+		return e.ErrInvalidTmapExportFormat
+	}
+}
+
+var TMAP_EXPORT_CSV_HEADER = []string{
+	"section",
+	"id",
+	"url",
+	"submitted_by",
+	"submit_date",
+	"cats",
+	"cats_from_user",
+	"global_cats",
+	"summary",
+	"stars_assigned",
+	"starred_at",
+	"tagged_at",
+}
+
+func writeTmapExportCSV(w io.Writer, links *[]model.TmapExportLink) error {
+	csv_writer := csv.NewWriter(w)
+	if err := csv_writer.Write(TMAP_EXPORT_CSV_HEADER); err != nil {
+		return err
+	}
+
+	for _, l := range *links {
+		if err := csv_writer.Write([]string{
+			string(l.Section),
+			l.ID,
+			l.URL,
+			l.SubmittedBy,
+			l.SubmitDate,
+			l.Cats,
+			strconv.FormatBool(l.CatsFromUser),
+			l.GlobalCats,
+			l.Summary,
+			strconv.Itoa(l.StarsAssigned),
+			l.StarredAt,
+			l.TaggedAt,
+		}); err != nil {
+			return err
+		}
+	}
+
+	csv_writer.Flush()
+	return csv_writer.Error()
+}
+
+// Flat list with no folders, so that sections are not mistaken for cats
+// when the file is imported elsewhere (or back into Modeep: see
+// parseNetscapeBookmarks). Cats are written as TAGS and summaries as <DD>.
+func writeTmapExportNetscape(w io.Writer, links *[]model.TmapExportLink, tmap_owner string) error {
+	title := html.EscapeString(tmap_owner + "'s Treasure Map")
+	if _, err := fmt.Fprintf(
+		w,
+		"<!DOCTYPE NETSCAPE-Bookmark-file-1>\n"+
+			"<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n"+
+			"<TITLE>%s</TITLE>\n"+
+			"<H1>%s</H1>\n"+
+			"<DL><p>\n",
+		title,
+		title,
+	); err != nil {
+		return err
+	}
+
+	for _, l := range *links {
+		add_date := ""
+		if t, err := mutil.ParseSubmitDate(l.SubmitDate); err == nil {
+			add_date = fmt.Sprintf(" ADD_DATE=\"%d\"", t.Unix())
+		}
+		if _, err := fmt.Fprintf(
+			w,
+			"    <DT><A HREF=\"%s\"%s TAGS=\"%s\">%s</A>\n",
+			html.EscapeString(l.URL),
+			add_date,
+			html.EscapeString(l.Cats),
+			html.EscapeString(l.URL),
+		); err != nil {
+			return err
+		}
+		if l.Summary != "" {
+			if _, err := fmt.Fprintf(w, "    <DD>%s\n", html.EscapeString(l.Summary)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, "</DL><p>\n")
+	return err
+}
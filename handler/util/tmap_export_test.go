@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestGetTmapExportFormatFromRequestParams(t *testing.T) {
+	var test_params = []struct {
+		Format         string
+		ExpectedFormat model.TmapExportFormat
+		Valid          bool
+	}{
+		{"", model.TmapExportFormatJSON, true},
+		{"netscape", model.TmapExportFormatNetscape, true},
+		{"CSV", model.TmapExportFormatCSV, true},
+		{"json", model.TmapExportFormatJSON, true},
+		{"xml", "", false},
+	}
+
+	for _, tp := range test_params {
+		params := url.Values{}
+		if tp.Format != "" {
+			params.Set("format", tp.Format)
+		}
+		format, err := GetTmapExportFormatFromRequestParams(params)
+		if tp.Valid && err != nil {
+			t.Fatalf("unexpected error for %q: %s", tp.Format, err)
+		} else if !tp.Valid && err == nil {
+			t.Fatalf("expected error for %q", tp.Format)
+		} else if format != tp.ExpectedFormat {
+			t.Fatalf("expected format %s for %q, got %s", tp.ExpectedFormat, tp.Format, format)
+		}
+	}
+}
+
+func TestGetTmapExportLinks(t *testing.T) {
+	if _, err := GetTmapExportLinks(&model.TmapOptions{}); err == nil {
+		t.Fatal("expected error for missing owner login name")
+	}
+
+	opts := &model.TmapOptions{OwnerLoginName: TEST_LOGIN_NAME}
+	links, err := GetTmapExportLinks(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Must match unpaginated sections
+	all_sections, err := getAllTmapSectionsForOwnerFromOpts[model.TmapLink](TEST_LOGIN_NAME, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected_count := len(*all_sections.Submitted) + len(*all_sections.Starred) + len(*all_sections.Tagged)
+	if len(*links) != expected_count {
+		t.Fatalf("expected %d links, got %d", expected_count, len(*links))
+	}
+
+	for _, l := range *links {
+		switch l.Section {
+		case model.TmapSectionSubmitted:
+			if l.SubmittedBy != TEST_LOGIN_NAME {
+				t.Fatalf("link %s in submitted section not submitted by %s", l.ID, TEST_LOGIN_NAME)
+			}
+		case model.TmapSectionStarred:
+			if l.StarsAssigned == 0 || l.StarredAt == "" {
+				t.Fatalf("link %s in starred section missing owner's stars: %+v", l.ID, l)
+			} else if stars := GetUsersStarsForLink(TEST_USER_ID, l.ID); int(stars) != l.StarsAssigned {
+				t.Fatalf("link %s: expected %d stars assigned, got %d", l.ID, stars, l.StarsAssigned)
+			}
+		case model.TmapSectionTagged:
+			if l.TaggedAt == "" || !l.CatsFromUser {
+				t.Fatalf("link %s in tagged section missing owner's tag: %+v", l.ID, l)
+			}
+		default:
+			t.Fatalf("unexpected section %q", l.Section)
+		}
+	}
+
+	// Individual section
+	opts = &model.TmapOptions{
+		OwnerLoginName: TEST_LOGIN_NAME,
+		Section:        model.TmapSectionSubmitted,
+	}
+	links, err = GetTmapExportLinks(opts)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(*links) != len(*all_sections.Submitted) {
+		t.Fatalf("expected %d submitted links, got %d", len(*all_sections.Submitted), len(*links))
+	}
+	for _, l := range *links {
+		if l.Section != model.TmapSectionSubmitted {
+			t.Fatalf("expected only submitted links, got %+v", l)
+		}
+	}
+}
+
+func TestWriteTmapExport(t *testing.T) {
+	links := &[]model.TmapExportLink{
+		{
+			Section:     model.TmapSectionStarred,
+			ID:          "1",
+			URL:         "https://example.com/a?b=1&c=2",
+			SubmittedBy: "someone",
+			// (as scanned)
+			SubmitDate:    "2025-01-01T00:00:00Z",
+			Cats:          "flowers,garden",
+			CatsFromUser:  true,
+			GlobalCats:    "flowers",
+			Summary:       `"quoted" & summary`,
+			StarsAssigned: 3,
+			StarredAt:     "2025-01-02 00:00:00",
+		},
+		{
+			Section:     model.TmapSectionSubmitted,
+			ID:          "2",
+			URL:         "https://example.com/b",
+			SubmittedBy: TEST_LOGIN_NAME,
+			SubmitDate:  "2025-02-01 00:00:00",
+			Cats:        "go",
+			GlobalCats:  "go",
+		},
+	}
+
+	// Netscape: can be imported back
+	var buf bytes.Buffer
+	if err := WriteTmapExport(&buf, links, model.TmapExportFormatNetscape, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	for _, add_date := range []string{`ADD_DATE="1735689600"`, `ADD_DATE="1738368000"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(add_date)) {
+			t.Fatalf("expected %s in export:\n%s", add_date, buf.String())
+		}
+	}
+	bookmarks, err := ParseBookmarks(buf.Bytes(), model.ImportFormatNetscape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected_bookmarks := []model.ImportedBookmark{
+		{URL: (*links)[0].URL, Cats: (*links)[0].Cats, Summary: (*links)[0].Summary},
+		{URL: (*links)[1].URL, Cats: (*links)[1].Cats},
+	}
+	if len(bookmarks) != len(expected_bookmarks) {
+		t.Fatalf("expected %d bookmarks, got %d: %+v", len(expected_bookmarks), len(bookmarks), bookmarks)
+	}
+	for i := range expected_bookmarks {
+		if bookmarks[i] != expected_bookmarks[i] {
+			t.Fatalf("bookmark %d: expected %+v, got %+v", i, expected_bookmarks[i], bookmarks[i])
+		}
+	}
+
+	// JSON
+	buf.Reset()
+	if err := WriteTmapExport(&buf, links, model.TmapExportFormatJSON, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []model.TmapExportLink
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	} else if len(decoded) != len(*links) || decoded[0] != (*links)[0] || decoded[1] != (*links)[1] {
+		t.Fatalf("expected %+v, got %+v", *links, decoded)
+	}
+
+	// CSV
+	buf.Reset()
+	if err := WriteTmapExport(&buf, links, model.TmapExportFormatCSV, TEST_LOGIN_NAME); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != len(*links)+1 {
+		t.Fatalf("expected %d records (incl. header), got %d", len(*links)+1, len(records))
+	}
+	if records[1][2] != (*links)[0].URL || records[1][8] != (*links)[0].Summary || records[1][9] != "3" {
+		t.Fatalf("unexpected CSV record: %v", records[1])
+	}
+
+	if err := WriteTmapExport(&buf, links, "xml", TEST_LOGIN_NAME); err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+}
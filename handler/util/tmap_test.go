@@ -3,6 +3,7 @@ package handler
 import (
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -24,6 +25,7 @@ func TestGetTmapOptionsFromRequestParams(t *testing.T) {
 				"url_contains":     []string{"test"},
 				"url_lacks":        []string{"test"},
 				"include_nsfw":     []string{"true"},
+				"exclude_dead":     []string{"true"},
 				"sort_by":          []string{"newest"},
 				"section":          []string{"starred"},
 				"page":             []string{"1"},
@@ -44,6 +46,13 @@ func TestGetTmapOptionsFromRequestParams(t *testing.T) {
 			},
 			Valid: false,
 		},
+		{
+			Params: url.Values{
+				// nor this
+				"exclude_dead": []string{"zombie"},
+			},
+			Valid: false,
+		},
 		{
 			Params: url.Values{
 				// nor this
@@ -65,6 +74,55 @@ func TestGetTmapOptionsFromRequestParams(t *testing.T) {
 			},
 			Valid: false,
 		},
+		{
+			Params: url.Values{
+				// cursors are only for individual sections
+				"cursor": []string{query.EncodeLinksCursor("", &model.Link{ID: "1"})},
+			},
+			Valid: false,
+		},
+		{
+			Params: url.Values{
+				"section": []string{"submitted"},
+				"sort_by": []string{"newest"},
+				"cursor":  []string{query.EncodeLinksCursor(model.SortByNewest, &model.Link{ID: "1"})},
+			},
+			Valid: true,
+		},
+		{
+			Params: url.Values{
+				// cursor for a different sort
+				"section": []string{"submitted"},
+				"cursor":  []string{query.EncodeLinksCursor(model.SortByNewest, &model.Link{ID: "1"})},
+			},
+			Valid: false,
+		},
+		{
+			Params: url.Values{
+				"cats": []string{"umvc3 AND (flowers OR coding) NOT test"},
+			},
+			Valid: true,
+		},
+		{
+			Params: url.Values{
+				// nor this
+				"cats": []string{"umvc3 AND (flowers"},
+			},
+			Valid: false,
+		},
+		{
+			Params: url.Values{
+				"domain": []string{"gist.GitHub.com"},
+			},
+			Valid: true,
+		},
+		{
+			Params: url.Values{
+				// nor this
+				"domain": []string{"git hub.com"},
+			},
+			Valid: false,
+		},
 	}
 
 	for _, tp := range test_params {
@@ -335,6 +393,56 @@ func TestPaginateIndividualTmapSection(t *testing.T) {
 	}
 }
 
+func TestGetTmapLinksAfterCursor(t *testing.T) {
+	// sorted by times starred
+	links := &[]model.TmapLink{}
+	for i, times_starred := range []int64{5, 3, 3, 0} {
+		l := model.TmapLink{}
+		l.ID = strconv.Itoa(9 - i)
+		l.SubmitDate = "2025-01-01 00:00:00"
+		l.TimesStarred = times_starred
+		*links = append(*links, l)
+	}
+
+	for i := 0; i < len(*links)-1; i++ {
+		cursor, err := query.DecodeLinksCursor(
+			query.EncodeLinksCursor("", &(*links)[i].Link),
+			model.SortByTimesStarred,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		links_after := getTmapLinksAfterCursor(links, cursor)
+		if len(*links_after) != len(*links)-i-1 || (*links_after)[0].ID != (*links)[i+1].ID {
+			t.Fatalf("expected links after %s to start with %s", (*links)[i].ID, (*links)[i+1].ID)
+		}
+	}
+
+	// Cursor link no longer in section: compared by sort keys
+	missing_link := (*links)[0].Link
+	missing_link.ID = "zzz-not-in-section"
+	cursor, err := query.DecodeLinksCursor(
+		query.EncodeLinksCursor("", &missing_link),
+		model.SortByTimesStarred,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links_after := getTmapLinksAfterCursor(links, cursor)
+	if len(*links_after) != len(*links) {
+		t.Fatalf("expected all %d links after cursor, got %d", len(*links), len(*links_after))
+	}
+
+	// Last link
+	cursor, _ = query.DecodeLinksCursor(
+		query.EncodeLinksCursor("", &(*links)[len(*links)-1].Link),
+		model.SortByTimesStarred,
+	)
+	if links_after := getTmapLinksAfterCursor(links, cursor); len(*links_after) != 0 {
+		t.Fatalf("expected no links after last link, got %d", len(*links_after))
+	}
+}
+
 func TestGetAllTmapSectionsForOwnerFromOpts(t *testing.T) {
 	if _, err := getAllTmapSectionsForOwnerFromOpts[model.TmapLink](
 		TEST_LOGIN_NAME,
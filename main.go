@@ -12,8 +12,11 @@ import (
 	"github.com/go-chi/httprate"
 	"github.com/go-chi/jwtauth/v5"
 
+	"github.com/julianlk522/modeep/db"
 	h "github.com/julianlk522/modeep/handler"
+	util "github.com/julianlk522/modeep/handler/util"
 	m "github.com/julianlk522/modeep/middleware"
+	"github.com/julianlk522/modeep/query"
 )
 
 const API_URL = "api.modeep.org:1999"
@@ -36,6 +39,25 @@ func main() {
 		}
 	}()
 
+	// MIGRATIONS
+	if err := db.Migrate(db.Client); err != nil {
+		log.Fatal(err)
+	}
+
+	// CAT SYNONYMS AND HIERARCHY
+	// (cached for cat filter expansion and comparisons; if they can't be
+	// loaded, cat filters just aren't expanded)
+	if err := query.LoadCatSynonyms(); err != nil {
+		log.Printf("Could not load cat synonyms, continuing without them: %s", err)
+	}
+	if err := query.LoadCatParents(); err != nil {
+		log.Printf("Could not load cat parents, continuing without them: %s", err)
+	}
+
+	// BACKGROUND JOBS
+	go util.RunLinkHealthChecker()
+	util.ResumeImportJobs()
+
 	// ROUTER-WIDE MIDDLEWARE
 	// LOGGER
 	// should go before any other middleware that may change
@@ -81,9 +103,22 @@ func main() {
 
 	r.Get("/pic/preview/{file_name}", h.GetPreviewImg)
 	r.Get("/cats", h.GetTopGlobalCats)
+	// (takes precedence over the wildcard route below)
+	r.Get("/cats/graph", h.GetCatGraph)
 	r.Get("/cats/*", h.GetSpellfixMatchesForSnippet)
+	r.
+		With(m.Pagination).
+		Get("/cat/{cat}/revisions", h.GetCatDescriptionRevisions)
+	r.
+		With(m.Pagination).
+		Get("/synonyms", h.GetCatSynonyms)
 	r.Get("/contributors", h.GetTopContributors)
+	r.Get("/domains", h.GetTopDomains)
 	r.Get("/totals", h.GetTotals)
+	r.Get("/links.atom", h.GetTopLinksAtomFeed)
+	r.Get("/links.rss", h.GetTopLinksRSSFeed)
+	r.Get("/map/{login_name}/feed.atom", h.GetTreasureMapAtomFeed)
+	r.Get("/map/{login_name}/feed.rss", h.GetTreasureMapRSSFeed)
 
 	// CD webhook: application update and refresh
 	r.Post("/ghwh", h.HandleGitHubWebhook)
@@ -97,12 +132,21 @@ func main() {
 		r.Use(m.JWTContext)
 
 		r.Get("/map/{login_name}", h.GetTreasureMap)
+		r.Get("/map/{login_name}/export", h.ExportTreasureMap)
 		r.Get("/summaries/{link_id}", h.GetSummaryPage)
 		r.Get("/tags/{link_id}", h.GetTagPage)
+		r.Get("/links/{link_id}/related", h.GetRelatedLinks)
+		r.Get("/links/{link_id}/clicks", h.GetLinkClicks)
+		r.Get("/archive/{link_id}", h.GetArchiveSnapshot)
+		r.Get("/collections/{collection_id}", h.GetCollection)
+		r.Get("/cat/{cat}", h.GetCatPage)
 
 		r.
 			With(m.Pagination).
 			Get("/links", h.GetTopLinks)
+		r.
+			With(m.Pagination).
+			Get("/links/{link_id}/comments", h.GetComments)
 
 		r.
 			With(httprate.Limit(
@@ -136,18 +180,62 @@ func main() {
 		// Links
 		r.Post("/links", h.AddLink)
 		r.Delete("/links", h.DeleteLink)
+		r.Post("/links/{link_id}/refresh", h.RefreshLinkMetadata)
+		r.Put("/links/{link_id}/visibility", h.EditLinkVisibility)
+		r.Post("/links/import", h.ImportLinks)
+		r.Get("/links/import/{job_id}", h.GetImportJob)
 		r.Post("/links/star", h.StarLink)
 		r.Delete("/links/star", h.UnstarLink)
+		r.Get("/links/recommended", h.GetRecommendedLinks)
 
 		// Tags
 		r.Post("/tags", h.AddTag)
 		r.Put("/tags", h.EditTag)
 		r.Delete("/tags", h.DeleteTag)
 
+		// Collections
+		r.Get("/collections", h.GetMyCollections)
+		r.Post("/collections", h.AddCollection)
+		r.Put("/collections/{collection_id}", h.EditCollection)
+		r.Delete("/collections/{collection_id}", h.DeleteCollection)
+		r.Post("/collections/{collection_id}/links", h.AddCollectionLink)
+		r.Put("/collections/{collection_id}/links/{link_id}", h.EditCollectionLink)
+		r.Delete("/collections/{collection_id}/links/{link_id}", h.DeleteCollectionLink)
+		r.Put("/collections/{collection_id}/order", h.ReorderCollection)
+
+		// Comments
+		r.Post("/links/{link_id}/comments", h.AddComment)
+		r.Put("/comments/{comment_id}", h.EditComment)
+		r.Delete("/comments/{comment_id}", h.DeleteComment)
+
 		// Summaries
 		r.Post("/summaries", h.AddSummary)
 		r.Delete("/summaries", h.DeleteSummary)
 		r.Post("/summaries/{summary_id}/like", h.LikeSummary)
 		r.Delete("/summaries/{summary_id}/like", h.UnlikeSummary)
+
+		// Reports
+		r.Post("/reports", h.AddReport)
+
+		// Cat pages
+		r.Put("/cat/{cat}/description", h.EditCatDescription)
+
+		// Cat synonyms
+		r.Post("/synonyms", h.AddCatSynonym)
+
+		// Admin
+		r.
+			With(m.Pagination).
+			Get("/admin/clicks", h.GetRawClicks)
+		r.
+			With(m.Pagination).
+			Get("/admin/reports", h.GetReports)
+		r.Put("/admin/reports/{report_id}", h.ModerateReport)
+		r.
+			With(m.Pagination).
+			Get("/admin/synonyms", h.GetProposedCatSynonyms)
+		r.Put("/admin/synonyms/{synonym_id}", h.ReviewCatSynonym)
+		r.Put("/admin/cat-parents", h.SetCatParent)
+		r.Delete("/admin/cat-parents/{cat}", h.RemoveCatParent)
 	})
 }
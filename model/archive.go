@@ -0,0 +1,10 @@
+package model
+
+type ArchiveSnapshot struct {
+	LinkID      string
+	Hash        string // SHA-256 of content; also the file name
+	ContentType string
+	SizeBytes   int
+	Truncated   bool
+	ArchivedAt  string
+}
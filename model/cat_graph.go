@@ -0,0 +1,24 @@
+package model
+
+// Nodes are cats with the number of links that have them, and edges are
+// pairs of nodes with the number of links that have both (Weight)
+type CatGraph struct {
+	Nodes []CatCount
+	Edges []CatGraphEdge
+}
+
+type CatGraphEdge struct {
+	Source string
+	Target string
+	Weight int32
+}
+
+// OPTIONS
+type CatGraphOptions struct {
+	CatFiltersWithSpellingVariants []string
+	NeuteredCatFilters             []string
+	Period                         Period
+	IncludeNSFW                    bool
+	// Edges with lower weights are omitted
+	MinWeight int
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+// Everything about 1 cat (and its plural/singular variants and synonyms)
+type CatPage[T Link | LinkSignedIn] struct {
+	Cat string
+	// nil if never written
+	Description       *CatDescription
+	TopLinks          *LinksPage[T]
+	TopContributors   *[]Contributor
+	CoOccurringCats   *[]CatCount
+	MonthlyLinkCounts []MonthlyLinkCount
+}
+
+// Latest revision
+type CatDescription struct {
+	Text          string
+	LastEditedBy  string
+	LastEditedAt  string
+	RevisionCount int
+}
+
+type CatDescriptionRevision struct {
+	ID       string
+	Cat      string
+	Text     string
+	EditedBy string
+	EditedAt string
+}
+
+type CatDescriptionRevisionsPage struct {
+	Revisions []CatDescriptionRevision
+	NextPage  int
+}
+
+type MonthlyLinkCount struct {
+	// YYYY-MM
+	Month string
+	Count int
+}
+
+// OPTIONS
+type CatDescriptionRevisionsOptions struct {
+	Page uint
+}
+
+// REQUESTS
+type EditCatDescriptionRequest struct {
+	ID       string
+	Text     string `json:"text"`
+	EditedAt string
+}
+
+func (ecdr *EditCatDescriptionRequest) Bind(r *http.Request) error {
+	ecdr.Text = strings.TrimSpace(ecdr.Text)
+	if ecdr.Text == "" {
+		return e.ErrNoCatDescriptionText
+	} else if len(ecdr.Text) > util.CAT_DESCRIPTION_CHAR_LIMIT {
+		return e.CatDescriptionLengthExceedsLimit(util.CAT_DESCRIPTION_CHAR_LIMIT)
+	}
+
+	ecdr.ID = uuid.New().String()
+	ecdr.EditedAt = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
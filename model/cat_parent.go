@@ -0,0 +1,41 @@
+package model
+
+import (
+	"net/http"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+// Counts roll up: a cat's count includes links tagged only with its
+// descendants
+type CatCountTree struct {
+	CatCount
+	Children []CatCountTree
+}
+
+// REQUESTS
+type SetCatParentRequest struct {
+	Cat    string `json:"cat"`
+	Parent string `json:"parent"`
+	SetAt  string
+}
+
+func (scpr *SetCatParentRequest) Bind(r *http.Request) error {
+	scpr.Cat = strings.ToLower(util.TrimExcessAndTrailingSpaces(scpr.Cat))
+	scpr.Parent = strings.ToLower(util.TrimExcessAndTrailingSpaces(scpr.Parent))
+
+	switch {
+	case scpr.Cat == "" || scpr.Parent == "":
+		return e.ErrNoCatParentCats
+	case strings.Contains(scpr.Cat, ",") || strings.Contains(scpr.Parent, ","):
+		return e.ErrCatParentHasMultipleCats
+	case len(scpr.Cat) > util.CAT_CHAR_LIMIT || len(scpr.Parent) > util.CAT_CHAR_LIMIT:
+		return e.CatCharsExceedLimit(util.CAT_CHAR_LIMIT)
+	}
+
+	scpr.SetAt = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+type CatSynonymStatus string
+
+const (
+	CatSynonymPending  CatSynonymStatus = "pending"
+	CatSynonymApproved CatSynonymStatus = "approved"
+	CatSynonymRejected CatSynonymStatus = "rejected"
+)
+
+var ValidCatSynonymStatuses = [3]CatSynonymStatus{
+	CatSynonymPending,
+	CatSynonymApproved,
+	CatSynonymRejected,
+}
+
+// Admin actions on pending synonyms
+type CatSynonymAction string
+
+const (
+	CatSynonymActionApprove CatSynonymAction = "approve"
+	CatSynonymActionReject  CatSynonymAction = "reject"
+)
+
+var cat_synonym_statuses_by_action = map[CatSynonymAction]CatSynonymStatus{
+	CatSynonymActionApprove: CatSynonymApproved,
+	CatSynonymActionReject:  CatSynonymRejected,
+}
+
+func (csa CatSynonymAction) Status() CatSynonymStatus {
+	return cat_synonym_statuses_by_action[csa]
+}
+
+// Cat is merged with SynonymOf (and its other synonyms) wherever
+// plural/singular variants are, e.g., "js" => "javascript"
+type CatSynonym struct {
+	ID         string
+	Cat        string
+	SynonymOf  string
+	ProposedBy string
+	ProposedAt string
+	Status     CatSynonymStatus
+	ReviewedBy string
+	ReviewedAt string
+}
+
+type CatSynonymsPage struct {
+	Synonyms []CatSynonym
+	NextPage int
+}
+
+// OPTIONS
+type CatSynonymsOptions struct {
+	Status CatSynonymStatus
+	Page   uint
+}
+
+// REQUESTS
+type NewCatSynonymRequest struct {
+	ID         string
+	Cat        string `json:"cat"`
+	SynonymOf  string `json:"synonym_of"`
+	ProposedAt string
+}
+
+func (ncsr *NewCatSynonymRequest) Bind(r *http.Request) error {
+	ncsr.Cat = strings.ToLower(util.TrimExcessAndTrailingSpaces(ncsr.Cat))
+	ncsr.SynonymOf = strings.ToLower(util.TrimExcessAndTrailingSpaces(ncsr.SynonymOf))
+
+	switch {
+	case ncsr.Cat == "" || ncsr.SynonymOf == "":
+		return e.ErrNoCatSynonymCats
+	case strings.Contains(ncsr.Cat, ",") || strings.Contains(ncsr.SynonymOf, ","):
+		return e.ErrCatSynonymHasMultipleCats
+	case len(ncsr.Cat) > util.CAT_CHAR_LIMIT || len(ncsr.SynonymOf) > util.CAT_CHAR_LIMIT:
+		return e.CatCharsExceedLimit(util.CAT_CHAR_LIMIT)
+	}
+
+	ncsr.ID = uuid.New().String()
+	ncsr.ProposedAt = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
+
+type ReviewCatSynonymRequest struct {
+	Action CatSynonymAction `json:"action"`
+}
+
+func (rcsr *ReviewCatSynonymRequest) Bind(r *http.Request) error {
+	if _, ok := cat_synonym_statuses_by_action[rcsr.Action]; !ok {
+		return e.ErrInvalidCatSynonymAction
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package model
+
+// Where on the frontend a link was clicked, sent optionally with
+// NewClickRequest
+type ClickSurface string
+
+const (
+	ClickSurfaceTopLinks ClickSurface = "top_links"
+	ClickSurfaceTmap     ClickSurface = "tmap"
+	ClickSurfaceCatPage  ClickSurface = "cat_page"
+	// Clicks recorded without a surface
+	ClickSurfaceUnknown ClickSurface = "unknown"
+)
+
+var ValidClickSurfaces = [3]ClickSurface{
+	ClickSurfaceTopLinks,
+	ClickSurfaceTmap,
+	ClickSurfaceCatPage,
+}
+
+// Valid: day, week
+type ClickBucket string
+
+const (
+	ClickBucketDay  ClickBucket = "day"
+	ClickBucketWeek ClickBucket = "week"
+)
+
+var ValidClickBuckets = [2]ClickBucket{
+	ClickBucketDay,
+	ClickBucketWeek,
+}
+
+// Why a recorded click is not counted
+// (see GetClickDisqualification)
+type ClickDisqualification string
+
+const (
+	ClickQualified ClickDisqualification = ""
+	// Same user or IP already clicked the link recently
+	ClickDuplicate ClickDisqualification = "duplicate"
+	// No user agent, or a known crawler / HTTP library
+	ClickBotUserAgent ClickDisqualification = "bot_user_agent"
+	// Too many clicks on any links from the same user or IP
+	ClickBotRate ClickDisqualification = "bot_rate"
+)
+
+// OPTIONS
+type LinkClicksOptions struct {
+	LinkID string
+	Bucket ClickBucket
+	Period Period
+}
+
+type RawClicksOptions struct {
+	LinkID           string
+	DisqualifiedOnly bool
+	Page             uint
+}
+
+// STATS
+// (qualified clicks only)
+type LinkClickStats struct {
+	LinkID string
+	Bucket ClickBucket
+	Period Period
+	LinkClickCounts
+	Buckets  []LinkClickBucketCounts
+	Surfaces []LinkClickSurfaceCount
+}
+
+// Signed-in users and anonymous IPs are counted separately
+// since anonymous clicks have no user ID
+type LinkClickCounts struct {
+	Clicks       int
+	UniqueUsers  int
+	AnonymousIPs int
+}
+
+type LinkClickBucketCounts struct {
+	// First day of the bucket (weeks start on Monday)
+	Start string
+	LinkClickCounts
+}
+
+type LinkClickSurfaceCount struct {
+	Surface ClickSurface
+	Clicks  int
+}
+
+// RAW
+type RawClick struct {
+	ID                 string
+	LinkID             string
+	UserID             string
+	IPAddr             string
+	UserAgent          string
+	Timestamp          string
+	Surface            ClickSurface
+	DisqualifiedReason ClickDisqualification
+}
+
+type RawClicksPage struct {
+	Clicks   []RawClick
+	NextPage int
+}
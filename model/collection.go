@@ -0,0 +1,157 @@
+package model
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+type CollectionVisibility string
+
+const (
+	// Listed on the owner's Treasure Map
+	CollectionPublic CollectionVisibility = "public"
+	// Viewable by anyone with the ID but not listed
+	CollectionUnlisted CollectionVisibility = "unlisted"
+	// Owner only
+	CollectionPrivate CollectionVisibility = "private"
+)
+
+var ValidCollectionVisibilities = [3]CollectionVisibility{
+	CollectionPublic,
+	CollectionUnlisted,
+	CollectionPrivate,
+}
+
+type Collection struct {
+	ID          string
+	Title       string
+	Description string
+	Visibility  CollectionVisibility
+	CreatedBy   string
+	CreatedAt   string
+	LastUpdated string
+	LinkCount   int
+}
+
+// LINKS
+type CollectionLink struct {
+	Link
+	Position int
+	Note     string
+}
+
+type CollectionLinkSignedIn struct {
+	LinkSignedIn
+	Position int
+	Note     string
+}
+
+type CollectionPage[T CollectionLink | CollectionLinkSignedIn] struct {
+	*Collection
+	Links *[]T
+}
+
+// REQUESTS
+type NewCollectionRequest struct {
+	ID          string
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Visibility  CollectionVisibility `json:"visibility"`
+	CreatedAt   string
+}
+
+func (ncr *NewCollectionRequest) Bind(r *http.Request) error {
+	if err := validateCollectionFields(
+		&ncr.Title,
+		ncr.Description,
+		&ncr.Visibility,
+	); err != nil {
+		return err
+	}
+
+	ncr.ID = uuid.New().String()
+	ncr.CreatedAt = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
+
+type EditCollectionRequest struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Visibility  CollectionVisibility `json:"visibility"`
+}
+
+func (ecr *EditCollectionRequest) Bind(r *http.Request) error {
+	return validateCollectionFields(
+		&ecr.Title,
+		ecr.Description,
+		&ecr.Visibility,
+	)
+}
+
+// Visibility defaults to public
+func validateCollectionFields(title *string, description string, visibility *CollectionVisibility) error {
+	*title = strings.TrimSpace(*title)
+	if *title == "" {
+		return e.ErrNoCollectionTitle
+	} else if len(*title) > util.COLLECTION_TITLE_CHAR_LIMIT {
+		return e.CollectionTitleLengthExceedsLimit(util.COLLECTION_TITLE_CHAR_LIMIT)
+	}
+
+	if len(description) > util.COLLECTION_DESCRIPTION_CHAR_LIMIT {
+		return e.CollectionDescriptionLengthExceedsLimit(util.COLLECTION_DESCRIPTION_CHAR_LIMIT)
+	}
+
+	if *visibility == "" {
+		*visibility = CollectionPublic
+	} else if !slices.Contains(ValidCollectionVisibilities[:], *visibility) {
+		return e.ErrInvalidCollectionVisibility
+	}
+
+	return nil
+}
+
+type AddCollectionLinkRequest struct {
+	LinkID string `json:"link_id"`
+	Note   string `json:"note"`
+}
+
+func (aclr *AddCollectionLinkRequest) Bind(r *http.Request) error {
+	if aclr.LinkID == "" {
+		return e.ErrNoLinkID
+	} else if len(aclr.Note) > util.COLLECTION_NOTE_CHAR_LIMIT {
+		return e.CollectionNoteLengthExceedsLimit(util.COLLECTION_NOTE_CHAR_LIMIT)
+	}
+
+	return nil
+}
+
+type EditCollectionLinkRequest struct {
+	Note string `json:"note"`
+}
+
+func (eclr *EditCollectionLinkRequest) Bind(r *http.Request) error {
+	if len(eclr.Note) > util.COLLECTION_NOTE_CHAR_LIMIT {
+		return e.CollectionNoteLengthExceedsLimit(util.COLLECTION_NOTE_CHAR_LIMIT)
+	}
+
+	return nil
+}
+
+// Every link in the collection, in the new order
+type ReorderCollectionRequest struct {
+	LinkIDs []string `json:"link_ids"`
+}
+
+func (rcr *ReorderCollectionRequest) Bind(r *http.Request) error {
+	if len(rcr.LinkIDs) == 0 {
+		return e.ErrInvalidCollectionOrder
+	}
+
+	return nil
+}
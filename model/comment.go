@@ -0,0 +1,79 @@
+package model
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+// Text and SubmittedBy of deleted comments
+const DELETED_COMMENT_PLACEHOLDER = "[deleted]"
+
+type Comment struct {
+	ID          string
+	SubmittedBy string
+	Text        string
+	SubmitDate  string
+	LastUpdated string
+	IsDeleted   bool
+}
+
+// Top-level comment and its replies, oldest first
+type CommentThread struct {
+	Comment
+	Replies []Comment
+}
+
+type CommentsPage struct {
+	Comments []CommentThread
+	NextPage int
+}
+
+// OPTIONS
+type CommentsOptions struct {
+	LinkID string
+	Page   uint
+}
+
+// REQUESTS
+type NewCommentRequest struct {
+	ID     string
+	LinkID string
+	// Omitted for top-level comments
+	ParentID   string `json:"parent_id"`
+	Text       string `json:"text"`
+	SubmitDate string
+}
+
+func (ncr *NewCommentRequest) Bind(r *http.Request) error {
+	if err := validateCommentText(&ncr.Text); err != nil {
+		return err
+	}
+
+	ncr.ID = uuid.New().String()
+	ncr.SubmitDate = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
+
+type EditCommentRequest struct {
+	Text string `json:"text"`
+}
+
+func (ecr *EditCommentRequest) Bind(r *http.Request) error {
+	return validateCommentText(&ecr.Text)
+}
+
+func validateCommentText(text *string) error {
+	*text = strings.TrimSpace(*text)
+	if *text == "" {
+		return e.ErrNoCommentText
+	} else if len(*text) > util.COMMENT_CHAR_LIMIT {
+		return e.CommentLengthExceedsLimit(util.COMMENT_CHAR_LIMIT)
+	}
+
+	return nil
+}
@@ -10,7 +10,9 @@ type TopContributorsOptions struct {
 	CatFiltersWithSpellingVariants []string
 	NeuteredCatFilters             []string
 	SummaryContains                string
+	ContentContains                string
 	URLContains                    string
 	URLLacks                       string
+	Domain                         string
 	Period                         Period
 }
@@ -0,0 +1,13 @@
+package model
+
+type DomainCount struct {
+	Domain string
+	Count  int
+}
+
+// OPTIONS
+type TopDomainsOptions struct {
+	CatFiltersWithSpellingVariants []string
+	NeuteredCatFilters             []string
+	Period                         Period
+}
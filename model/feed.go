@@ -0,0 +1,29 @@
+package model
+
+// Syndication formats for GET /links.{atom,rss} and
+// GET /map/{login_name}/feed.{atom,rss}
+type FeedFormat string
+
+const (
+	FeedFormatAtom FeedFormat = "atom"
+	FeedFormatRSS  FeedFormat = "rss"
+)
+
+type Feed struct {
+	Title string
+	// Frontend page showing the same links
+	Link string
+	// URL the feed itself was requested from
+	SelfLink string
+	Entries  []FeedEntry
+}
+
+type FeedEntry struct {
+	LinkID      string
+	URL         string
+	SubmittedBy string
+	SubmitDate  string
+	Cats        string
+	// Always the global summary, even in Treasure Map feeds
+	Summary string
+}
@@ -0,0 +1,73 @@
+package model
+
+// Bookmark export formats accepted by POST /links/import
+type ImportFormat string
+
+const (
+	ImportFormatNetscape ImportFormat = "netscape"
+	ImportFormatPinboard ImportFormat = "pinboard"
+	ImportFormatPocket   ImportFormat = "pocket"
+)
+
+var ValidImportFormats = [3]ImportFormat{
+	ImportFormatNetscape,
+	ImportFormatPinboard,
+	ImportFormatPocket,
+}
+
+type ImportJobStatus string
+
+const (
+	ImportJobQueued   ImportJobStatus = "queued"
+	ImportJobRunning  ImportJobStatus = "running"
+	ImportJobFinished ImportJobStatus = "finished"
+	// stopped by an error before all items were processed
+	ImportJobFailed ImportJobStatus = "failed"
+)
+
+type ImportItemStatus string
+
+const (
+	ImportItemPending ImportItemStatus = "pending"
+	// new link submitted by the importing user
+	ImportItemAdded ImportItemStatus = "added"
+	// link already existed: starred and/or tagged instead
+	ImportItemExisting ImportItemStatus = "existing"
+	// link already existed and was already submitted, starred and tagged
+	// by the importing user
+	ImportItemSkipped ImportItemStatus = "skipped"
+	ImportItemFailed  ImportItemStatus = "failed"
+)
+
+// A bookmark parsed from an export file, before it is processed
+type ImportedBookmark struct {
+	URL     string
+	Cats    string
+	Summary string
+}
+
+type ImportJob struct {
+	ID          string
+	SubmittedBy string
+	Format      ImportFormat
+	Status      ImportJobStatus
+	CreatedAt   string
+	FinishedAt  string
+	// why the job failed, if it did
+	Error      string
+	ItemsCount int
+	// count per item status
+	Counts map[ImportItemStatus]int
+	Items  []ImportJobItem
+}
+
+type ImportJobItem struct {
+	Position int
+	URL      string
+	Cats     string
+	Status   ImportItemStatus
+	LinkID   string
+	Starred  bool
+	Tagged   bool
+	Error    string
+}
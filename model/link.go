@@ -2,6 +2,7 @@ package model
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	e "github.com/julianlk522/modeep/error"
@@ -18,11 +19,21 @@ type TopLinksOptions struct {
 	GlobalSummaryContains          string
 	URLContains                    string
 	URLLacks                       string
+	Domain                         string
+	ContentContains                string
 	IncludeNSFW                    bool
+	ExcludeDead                    bool
 	SortBy                         SortBy
 	Period                         Period
 	AsSignedInUser                 string
 	Page                           uint
+	// Takes precedence over Page
+	Cursor *LinksCursor
+}
+
+type RelatedLinksOptions struct {
+	IncludeNSFW    bool
+	AsSignedInUser string
 }
 
 // LINKS
@@ -33,25 +44,52 @@ type HasCats interface {
 }
 
 type Link struct {
-	ID                 string
-	URL                string
-	SubmittedBy        string
-	SubmitDate         string
-	Cats               string
-	Summary            string
-	SummaryCount       int
-	TimesStarred       int64
-	AvgStars           float32
-	EarliestStarrers   string
-	ClickCount         int64
-	TagCount           int
+	ID           string
+	URL          string
+	SubmittedBy  string
+	SubmitDate   string
+	Cats         string
+	Summary      string
+	SummaryCount int
+	TimesStarred int64
+	AvgStars     float32
+	// Bayesian average of stars: pulled toward the mean of all stars
+	// when a link has few of its own (0 if unstarred)
+	Rating           float32
+	EarliestStarrers string
+	ClickCount       int64
+	TagCount         int
+	// Not including deleted comments
+	CommentCount       int
 	PreviewImgFilename string
+	Health             LinkHealth
+	LastCheckedAt      string
+	// Highlighted excerpt of page content (content_contains searches only)
+	ContentSnippet string
 }
 
 func (l Link) GetCats() string {
 	return l.Cats
 }
 
+// Set by the background link health checker
+// (see handler/util/link_health.go)
+type LinkHealth string
+
+const (
+	LinkHealthUnchecked LinkHealth = ""
+	LinkHealthAlive     LinkHealth = "alive"
+	LinkHealthDead      LinkHealth = "dead"
+)
+
+type LinkHealthCheck struct {
+	LinkID     string
+	StatusCode int
+	FinalURL   string
+	LatencyMS  int64
+	CheckedAt  string
+}
+
 type LinkSignedIn struct {
 	Link
 	StarsAssigned uint8
@@ -69,7 +107,33 @@ type LinksPage[T Link | LinkSignedIn] struct {
 	Links          *[]T
 	NSFWLinksCount int
 	MergedCats     []string
-	Pages          int
+	// Counted from the cursor if one was used
+	Pages      int
+	NextCursor string
+	// Sorts without a cursor (relevance, hot) can only be paginated by
+	// page number, so results can shift between pages as links are added
+	// or (for hot) as scores decay
+	OffsetOnly bool
+}
+
+// RECOMMENDED
+type RecommendedLink struct {
+	LinkSignedIn
+	// e.g., "because you starred https://go.dev" or "because you like go"
+	Reason    string
+	ReasonCat string
+	// Empty if the user hasn't starred a link with ReasonCat
+	ReasonStarredLinkID string
+}
+
+// How much a user likes a cat, based on the links they have starred,
+// tagged and clicked
+type CatAffinity struct {
+	Cat    string
+	Weight float64
+	// Their highest-starred link with Cat, if any
+	StarredLinkID  string
+	StarredLinkURL string
 }
 
 // REQUESTS
@@ -81,15 +145,41 @@ type NewLink struct {
 	PreviewImgFilename string
 }
 
+type LinkVisibility string
+
+const (
+	LinkPublic LinkVisibility = "public"
+	// Submitter only, on their own Treasure Map
+	LinkPrivate LinkVisibility = "private"
+)
+
+var ValidLinkVisibilities = [2]LinkVisibility{
+	LinkPublic,
+	LinkPrivate,
+}
+
 type NewLinkRequest struct {
 	URL        string
 	Cats       string
 	Summary    string
+	Visibility LinkVisibility
 	LinkID     string `json:"ID"`
 	SubmitDate string
 }
 
 func (nlr *NewLinkRequest) Bind(r *http.Request) error {
+	if err := nlr.Validate(); err != nil {
+		return err
+	}
+
+	nlr.LinkID = uuid.New().String()
+	nlr.SubmitDate = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
+
+// Separate from Bind for imported bookmarks, which have no request
+func (nlr *NewLinkRequest) Validate() error {
 	if nlr.URL == "" {
 		return e.ErrNoURL
 	} else if len(nlr.URL) > util.URL_CHAR_LIMIT {
@@ -115,8 +205,11 @@ func (nlr *NewLinkRequest) Bind(r *http.Request) error {
 		nlr.Summary = strings.ReplaceAll(nlr.Summary, "\"", "'")
 	}
 
-	nlr.LinkID = uuid.New().String()
-	nlr.SubmitDate = util.NEW_LONG_TIMESTAMP()
+	if nlr.Visibility == "" {
+		nlr.Visibility = LinkPublic
+	} else if !slices.Contains(ValidLinkVisibilities[:], nlr.Visibility) {
+		return e.ErrInvalidLinkVisibility
+	}
 
 	return nil
 }
@@ -124,6 +217,12 @@ func (nlr *NewLinkRequest) Bind(r *http.Request) error {
 type LinkExtraMetadata struct {
 	AutoSummary   string
 	PreviewImgURL string
+	CanonicalURL  string
+	// Raw page body, for archiving (successful HTML/text responses only)
+	PageContent     []byte `json:"-"`
+	PageContentType string `json:"-"`
+	// Whether PageContent was cut off at MAX_ARCHIVE_SNAPSHOT_BYTES
+	PageContentTruncated bool `json:"-"`
 }
 
 type YTVideoMetadata struct {
@@ -156,6 +255,18 @@ func (dlr *DeleteLinkRequest) Bind(r *http.Request) error {
 	return nil
 }
 
+type EditLinkVisibilityRequest struct {
+	Visibility LinkVisibility `json:"visibility"`
+}
+
+func (elvr *EditLinkVisibilityRequest) Bind(r *http.Request) error {
+	if !slices.Contains(ValidLinkVisibilities[:], elvr.Visibility) {
+		return e.ErrInvalidLinkVisibility
+	}
+
+	return nil
+}
+
 type UnstarLinkRequest struct {
 	LinkID string `json:"link_id"`
 }
@@ -186,7 +297,8 @@ func (sl *StarLinkRequest) Bind(r *http.Request) error {
 }
 
 type NewClickRequest struct {
-	LinkID    string `json:"link_id"`
+	LinkID    string       `json:"link_id"`
+	Surface   ClickSurface `json:"surface,omitempty"`
 	IPAddr    string
 	Timestamp string
 }
@@ -196,6 +308,19 @@ func (ncr *NewClickRequest) Bind(r *http.Request) error {
 		return e.ErrNoLinkID
 	}
 
+	if ncr.Surface != "" {
+		found := false
+		for _, s := range ValidClickSurfaces {
+			if ncr.Surface == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return e.ErrInvalidClickSurface
+		}
+	}
+
 	ncr.Timestamp = util.NEW_LONG_TIMESTAMP()
 
 	return nil
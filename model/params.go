@@ -21,7 +21,9 @@ var ValidPeriodsInDays = map[Period]uint16{
 }
 
 // SORT BY
-// Valid: times_starred, avg_stars, newest, oldest, clicks
+// Valid: times_starred, avg_stars, newest, oldest, clicks, relevance, hot,
+// rating
+// (relevance only has an effect alongside content_contains)
 type SortBy string
 
 const (
@@ -30,14 +32,31 @@ const (
 	SortByNewest       SortBy = "newest"
 	SortByOldest       SortBy = "oldest"
 	SortByClicks       SortBy = "clicks"
+	SortByRelevance    SortBy = "relevance"
+	SortByHot          SortBy = "hot"
+	SortByRating       SortBy = "rating"
 )
 
-var ValidSortBys = [5]SortBy{
+var ValidSortBys = [8]SortBy{
 	SortByTimesStarred,
 	SortByAverageStars,
 	SortByNewest,
 	SortByOldest,
 	SortByClicks,
+	SortByRelevance,
+	SortByHot,
+	SortByRating,
+}
+
+// CURSOR
+// Keyset pagination position, passed around as an opaque token (see
+// query/cursor.go): the SortBy it was issued for, the values of that
+// sort's ORDER BY keys for the last link on the previous page, and
+// that link's ID
+type LinksCursor struct {
+	SortBy SortBy `json:"s"`
+	Keys   []any  `json:"k"`
+	ID     string `json:"id"`
 }
 
 // TREASURE MAP SECTION
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	e "github.com/julianlk522/modeep/error"
+	util "github.com/julianlk522/modeep/model/util"
+)
+
+type ReportContentType string
+
+const (
+	ReportedLink    ReportContentType = "link"
+	ReportedSummary ReportContentType = "summary"
+	ReportedTag     ReportContentType = "tag"
+)
+
+var ValidReportContentTypes = [3]ReportContentType{
+	ReportedLink,
+	ReportedSummary,
+	ReportedTag,
+}
+
+type ReportReason string
+
+const (
+	ReportSpam    ReportReason = "spam"
+	ReportBroken  ReportReason = "broken"
+	ReportNSFW    ReportReason = "nsfw"
+	ReportAbusive ReportReason = "abusive"
+)
+
+var ValidReportReasons = [4]ReportReason{
+	ReportSpam,
+	ReportBroken,
+	ReportNSFW,
+	ReportAbusive,
+}
+
+type ReportStatus string
+
+const (
+	ReportOpen ReportStatus = "open"
+	// Handled without hiding the content, e.g., a broken link was fixed
+	ReportResolved  ReportStatus = "resolved"
+	ReportHidden    ReportStatus = "hidden"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+var ValidReportStatuses = [4]ReportStatus{
+	ReportOpen,
+	ReportResolved,
+	ReportHidden,
+	ReportDismissed,
+}
+
+// Admin actions on open reports, each closing them with the matching status
+type ReportAction string
+
+const (
+	ReportActionResolve ReportAction = "resolve"
+	ReportActionHide    ReportAction = "hide"
+	ReportActionDismiss ReportAction = "dismiss"
+)
+
+var report_statuses_by_action = map[ReportAction]ReportStatus{
+	ReportActionResolve: ReportResolved,
+	ReportActionHide:    ReportHidden,
+	ReportActionDismiss: ReportDismissed,
+}
+
+func (ra ReportAction) Status() ReportStatus {
+	return report_statuses_by_action[ra]
+}
+
+type Report struct {
+	ID          string
+	ContentType ReportContentType
+	ContentID   string
+	Reason      ReportReason
+	Details     string
+	ReportedBy  string
+	ReportedAt  string
+	Status      ReportStatus
+	ResolvedBy  string
+	ResolvedAt  string
+	// Open reports on the same content, including this one
+	OpenReportCount int
+}
+
+type ReportsPage struct {
+	Reports  []Report
+	NextPage int
+}
+
+// OPTIONS
+type ReportsOptions struct {
+	Status ReportStatus
+	Page   uint
+}
+
+// REQUESTS
+type NewReportRequest struct {
+	ID          string
+	ContentType ReportContentType `json:"content_type"`
+	ContentID   string            `json:"content_id"`
+	Reason      ReportReason      `json:"reason"`
+	Details     string            `json:"details"`
+	ReportedAt  string
+}
+
+func (nrr *NewReportRequest) Bind(r *http.Request) error {
+	if !slices.Contains(ValidReportContentTypes[:], nrr.ContentType) {
+		return e.ErrInvalidReportContentType
+	} else if nrr.ContentID == "" {
+		return e.ErrNoReportContentID
+	} else if !slices.Contains(ValidReportReasons[:], nrr.Reason) {
+		return e.ErrInvalidReportReason
+	} else if len(nrr.Details) > util.REPORT_DETAILS_CHAR_LIMIT {
+		return e.ReportDetailsLengthExceedsLimit(util.REPORT_DETAILS_CHAR_LIMIT)
+	}
+
+	nrr.ID = uuid.New().String()
+	nrr.ReportedAt = util.NEW_LONG_TIMESTAMP()
+
+	return nil
+}
+
+type ModerateReportRequest struct {
+	Action ReportAction `json:"action"`
+}
+
+func (mrr *ModerateReportRequest) Bind(r *http.Request) error {
+	if _, ok := report_statuses_by_action[mrr.Action]; !ok {
+		return e.ErrInvalidReportAction
+	}
+
+	return nil
+}
@@ -39,13 +39,18 @@ type CatCount struct {
 }
 
 type TopCatCountsOptions struct {
-	RawCatFilters      []string
-	NeuteredCatFilters []string
-	SummaryContains    string
-	URLContains        string
-	URLLacks           string
-	Period             Period
-	More               bool
+	// Cats from the cats query, omitted from counts
+	RawCatFilters                  []string
+	CatFiltersWithSpellingVariants []string
+	NeuteredCatFilters             []string
+	SummaryContains                string
+	URLContains                    string
+	URLLacks                       string
+	Domain                         string
+	Period                         Period
+	More                           bool
+	// Counts roll up to ancestor cats, to be returned as a tree
+	Tree bool
 }
 
 func SortCats(i, j CatCount) int {
@@ -60,8 +65,10 @@ func SortCats(i, j CatCount) int {
 // SPELLFIX
 type SpellfixMatchesOptions struct {
 	IsTmapAndOwnerIs string
-	CatFilters       []string
-	YouAreAddingCats bool
+	// Cats from the cats query (or being added), omitted from matches
+	CatFilters                     []string
+	CatFiltersWithSpellingVariants []string
+	YouAreAddingCats               bool
 }
 
 // for CalculateAndSetGlobalCats()
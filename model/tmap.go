@@ -20,23 +20,33 @@ type TmapOptions struct {
 	NeuteredCatFiltersWithSpellingVariants []string
 	AsSignedInUser                         string
 	IncludeNSFW                            bool
+	IncludePrivate                         bool
+	ExcludeDead                            bool
 	SortBy                                 SortBy
 	Period                                 Period
 	SummaryContains                        string
 	URLContains                            string
 	URLLacks                               string
+	Domain                                 string
+	ContentContains                        string
 	Section                                TmapIndividualSectionName
 	Page                                   int
+	// Individual sections only; takes precedence over Page
+	Cursor *LinksCursor
 }
 
 type TmapNSFWLinksCountOptions struct {
 	Section                                TmapIndividualSectionName
+	IncludePrivate                         bool
 	CatFiltersWithSpellingVariants         []string
 	NeuteredCatFiltersWithSpellingVariants []string
 	Period                                 Period
 	SummaryContains                        string
 	URLContains                            string
 	URLLacks                               string
+	Domain                                 string
+	ContentContains                        string
+	ExcludeDead                            bool
 }
 
 type TmapCatCountsOptions struct {
@@ -54,6 +64,39 @@ type TmapLinkSignedIn struct {
 	CatsFromUser bool
 }
 
+// EXPORT
+// Formats accepted by GET /map/{login_name}/export
+type TmapExportFormat string
+
+const (
+	TmapExportFormatNetscape TmapExportFormat = "netscape"
+	TmapExportFormatJSON     TmapExportFormat = "json"
+	TmapExportFormatCSV      TmapExportFormat = "csv"
+)
+
+var ValidTmapExportFormats = [3]TmapExportFormat{
+	TmapExportFormatNetscape,
+	TmapExportFormatJSON,
+	TmapExportFormatCSV,
+}
+
+// Every link in a Treasure Map, unpaginated. Stars and dates are the
+// Treasure Map owner's, not the requesting user's.
+type TmapExportLink struct {
+	Section       TmapIndividualSectionName
+	ID            string
+	URL           string
+	SubmittedBy   string
+	SubmitDate    string
+	Cats          string
+	CatsFromUser  bool
+	GlobalCats    string
+	Summary       string
+	StarsAssigned int
+	StarredAt     string
+	TaggedAt      string
+}
+
 // SECTIONS
 type TmapSections[T TmapLink | TmapLinkSignedIn] struct {
 	Submitted        *[]T
@@ -61,6 +104,8 @@ type TmapSections[T TmapLink | TmapLinkSignedIn] struct {
 	Tagged           *[]T
 	SectionsWithMore []string
 	Cats             *[]CatCount
+	// Public only; unaffected by filters
+	Collections *[]Collection
 }
 
 // Individual section of Treasure Map links:
@@ -72,7 +117,10 @@ type TmapIndividualSectionPage[T TmapLink | TmapLinkSignedIn] struct {
 	// Individual sections can be paginated for thorough searches,
 	// though main Treasure Map page just has the first few links
 	// from each section as an overview
-	Pages int
+	Pages      int
+	NextCursor string
+	// See LinksPage.OffsetOnly
+	OffsetOnly bool
 }
 
 type TmapIndividualSectionWithCatFiltersPage[T TmapLink | TmapLinkSignedIn] struct {
@@ -19,3 +19,17 @@ const EARLIEST_STARRERS_LIMIT = 10
 // Tag
 const CATS_PER_LINK_LIMIT = 20
 const CAT_CHAR_LIMIT = 30
+
+// Cat page
+const CAT_DESCRIPTION_CHAR_LIMIT = 2000
+
+// Collection
+const COLLECTION_TITLE_CHAR_LIMIT = 100
+const COLLECTION_DESCRIPTION_CHAR_LIMIT = 1000
+const COLLECTION_NOTE_CHAR_LIMIT = 400
+
+// Comment
+const COMMENT_CHAR_LIMIT = 2000
+
+// Report
+const REPORT_DETAILS_CHAR_LIMIT = 500
@@ -12,3 +12,12 @@ var (
 	NEW_LONG_TIMESTAMP  = func() string { return time.Now().Format("2006-01-02 15:04:05") }
 	NEW_SHORT_TIMESTAMP = func() string { return time.Now().Format("2006-01-02") }
 )
+
+// Submit dates are scanned as RFC 3339 but stored as long timestamps
+func ParseSubmitDate(submit_date string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, submit_date)
+	if err != nil {
+		return time.Parse("2006-01-02 15:04:05", submit_date)
+	}
+	return t, nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSubmitDate(t *testing.T) {
+	want := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	for _, submit_date := range []string{
+		"2025-01-01T12:30:00Z",
+		"2025-01-01 12:30:00",
+	} {
+		got, err := ParseSubmitDate(submit_date)
+		if err != nil {
+			t.Fatal(err)
+		} else if !got.Equal(want) {
+			t.Fatalf("expected %s for %s, got %s", want, submit_date, got)
+		}
+	}
+
+	if _, err := ParseSubmitDate("yesterday"); err == nil {
+		t.Fatal("expected error for invalid date")
+	}
+}
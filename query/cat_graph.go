@@ -0,0 +1,199 @@
+package query
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+// CAT CO-OCCURRENCE GRAPH
+// Counted from "Link Global Cats" and "Link Global Cat Pairs" (kept in
+// sync with Links.global_cats) rather than by splitting every link's
+// global_cats. Plural/singular
+// variants are counted together and shown with their most common spelling.
+type CatGraph struct {
+	*Query
+	// so filters know where their args go (before the LIMIT, etc. args
+	// of the selected nodes or edges)
+	filterArgsCount int
+	// so .includeNSFW() knows whether to leave a WHERE for the conditions
+	// after the NSFW one
+	hasAndAfterWhere bool
+}
+
+func NewCatGraphNodes() *CatGraph {
+	return &CatGraph{
+		Query: &Query{
+			Text: CAT_GRAPH_CTES + "\n" + CAT_GRAPH_NODES,
+			Args: []any{CAT_GRAPH_NODES_LIMIT},
+		},
+	}
+}
+
+// min_weight is the fewest links 2 cats must share to have an edge
+func NewCatGraphEdges(min_weight int) *CatGraph {
+	return &CatGraph{
+		Query: &Query{
+			Text: CAT_GRAPH_CTES + "\n" + CAT_GRAPH_EDGES,
+			Args: []any{CAT_GRAPH_NODES_LIMIT, min_weight, CAT_GRAPH_EDGES_LIMIT},
+		},
+	}
+}
+
+const CAT_GRAPH_NO_NSFW_CATS_WHERE = `
+	WHERE l.id NOT IN (
+		SELECT link_id FROM global_cats_fts WHERE global_cats MATCH 'NSFW'
+	)`
+
+const CAT_GRAPH_LINKS_END = `
+),
+GraphCats AS (`
+
+const CAT_GRAPH_CTES = `WITH GraphLinks AS (
+	SELECT l.id
+	FROM "Public Links" l` + CAT_GRAPH_NO_NSFW_CATS_WHERE + CAT_GRAPH_LINKS_END + `
+	SELECT lgc.link_id, lgc.cat, lgc.normalized_cat
+	FROM "Link Global Cats" lgc
+	INNER JOIN GraphLinks gl ON gl.id = lgc.link_id
+),
+Nodes AS (
+	SELECT
+		gc1.normalized_cat,
+		(
+			SELECT gc2.cat
+			FROM GraphCats gc2
+			WHERE gc2.normalized_cat = gc1.normalized_cat
+			GROUP BY gc2.cat
+			ORDER BY count(*) DESC, length(gc2.cat) DESC, gc2.cat DESC
+			LIMIT 1
+		) AS cat,
+		count(*) AS count
+	FROM GraphCats gc1
+	GROUP BY gc1.normalized_cat
+	ORDER BY count DESC, gc1.normalized_cat ASC
+	LIMIT ?
+)`
+
+const CAT_GRAPH_NODES = `SELECT cat, count
+FROM Nodes
+ORDER BY count DESC, cat ASC;`
+
+// (pairs are stored once, as normalized_cat_a < normalized_cat_b)
+const CAT_GRAPH_EDGES = `SELECT
+	na.cat AS source,
+	nb.cat AS target,
+	count(*) AS weight
+FROM "Link Global Cat Pairs" p
+INNER JOIN GraphLinks gl ON gl.id = p.link_id
+INNER JOIN Nodes na ON na.normalized_cat = p.normalized_cat_a
+INNER JOIN Nodes nb ON nb.normalized_cat = p.normalized_cat_b
+GROUP BY p.normalized_cat_a, p.normalized_cat_b
+HAVING weight >= ?
+ORDER BY weight DESC, source ASC, target ASC
+LIMIT ?;`
+
+func (cg *CatGraph) FromOptions(opts *model.CatGraphOptions) (*CatGraph, error) {
+	if len(opts.CatFiltersWithSpellingVariants) > 0 {
+		cg = cg.fromCatFilters(opts.CatFiltersWithSpellingVariants)
+	}
+	if len(opts.NeuteredCatFilters) > 0 {
+		cg = cg.fromNeuteredCatFilters(opts.NeuteredCatFilters)
+	}
+	if opts.Period != "" {
+		cg = cg.duringPeriod(opts.Period)
+	}
+	// (after other filters so it can tell whether to leave a WHERE)
+	if opts.IncludeNSFW {
+		cg = cg.includeNSFW()
+	}
+	if cg.Error != nil {
+		return nil, cg.Error
+	}
+
+	return cg, nil
+}
+
+func (cg *CatGraph) fromCatFilters(cat_filters []string) *CatGraph {
+	// (spelling variations already added in .FromRequestParams())
+	return cg.withLinksCondition(
+		`l.id IN (
+		SELECT link_id FROM global_cats_fts WHERE global_cats MATCH ?
+	)`,
+		strings.Join(cat_filters, " AND "),
+	)
+}
+
+func (cg *CatGraph) fromNeuteredCatFilters(neutered_cat_filters []string) *CatGraph {
+	// Since we use IN, not FTS MATCH, spelling variants are not needed
+	// (and casing matters)
+	args := make([]any, len(neutered_cat_filters))
+	for i, cat := range neutered_cat_filters {
+		args[i] = strings.ToLower(cat)
+	}
+
+	return cg.withLinksCondition(
+		`l.id NOT IN (
+		SELECT link_id FROM "Link Global Cats" WHERE LOWER(cat) IN (?`+
+			strings.Repeat(", ?", len(neutered_cat_filters)-1)+`)
+	)`,
+		args...,
+	)
+}
+
+func (cg *CatGraph) duringPeriod(period model.Period) *CatGraph {
+	if period == "all" {
+		return cg
+	}
+
+	period_clause, err := getPeriodClause(period)
+	if err != nil {
+		cg.Error = err
+		return cg
+	}
+
+	return cg.withLinksCondition(
+		strings.Replace(period_clause, "submit_date", "l.submit_date", 1),
+	)
+}
+
+func (cg *CatGraph) includeNSFW() *CatGraph {
+	// e.g.,
+	// WHERE l.id NOT IN ( ... )
+	// AND ...
+	if cg.hasAndAfterWhere {
+		cg.Text = strings.Replace(
+			cg.Text,
+			CAT_GRAPH_NO_NSFW_CATS_WHERE+"\n\tAND",
+			"\n\tWHERE",
+			1,
+		)
+	} else {
+		cg.Text = strings.Replace(
+			cg.Text,
+			CAT_GRAPH_NO_NSFW_CATS_WHERE,
+			"",
+			1,
+		)
+	}
+
+	return cg
+}
+
+// Adds a condition that links must meet to count toward nodes and edges
+func (cg *CatGraph) withLinksCondition(condition string, args ...any) *CatGraph {
+	cg.Text = strings.Replace(
+		cg.Text,
+		CAT_GRAPH_LINKS_END,
+		"\n\tAND "+condition+CAT_GRAPH_LINKS_END,
+		1,
+	)
+	cg.hasAndAfterWhere = true
+
+	// old: [filter args..., CAT_GRAPH_NODES_LIMIT, ...]
+	// new: [filter args..., args..., CAT_GRAPH_NODES_LIMIT, ...]
+	cg.Args = slices.Insert(cg.Args, cg.filterArgsCount, args...)
+	cg.filterArgsCount += len(args)
+
+	return cg
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestCatGraph(t *testing.T) {
+	for _, l := range []struct {
+		ID         string
+		SubmitDate string
+		GlobalCats string
+	}{
+		{"cat-graph-test-1", "2025-01-01", "catgraphtestgo,catgraphtestdatabases"},
+		{"cat-graph-test-2", "", "catgraphtestgo,catgraphtestdatabase,NSFW"},
+		{"cat-graph-test-3", "", "catgraphtestgo,catgraphtestrust"},
+	} {
+		submit_date := l.SubmitDate
+		if submit_date == "" {
+			submit_date = "datetime('now')"
+		} else {
+			submit_date = "'" + submit_date + "'"
+		}
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, 'jlk', `+submit_date+`, ?);`,
+			l.ID,
+			"https://"+l.ID+".com",
+			l.GlobalCats,
+		); err != nil {
+			t.Fatal(err)
+		}
+		var normalized_cats []string
+		for _, cat := range strings.Split(l.GlobalCats, ",") {
+			normalized_cat := GetSingularCat(strings.ToLower(cat))
+			if _, err := TestClient.Exec(
+				`INSERT INTO "Link Global Cats" (link_id, cat, normalized_cat)
+				VALUES (?, ?, ?);`,
+				l.ID,
+				cat,
+				normalized_cat,
+			); err != nil {
+				t.Fatal(err)
+			}
+			normalized_cats = append(normalized_cats, normalized_cat)
+		}
+		slices.Sort(normalized_cats)
+		for i, cat_a := range normalized_cats {
+			for _, cat_b := range normalized_cats[i+1:] {
+				if _, err := TestClient.Exec(
+					`INSERT INTO "Link Global Cat Pairs" (link_id, normalized_cat_a, normalized_cat_b)
+					VALUES (?, ?, ?);`,
+					l.ID,
+					cat_a,
+					cat_b,
+				); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	defer func() {
+		TestClient.Exec("DELETE FROM Links WHERE id LIKE 'cat-graph-test-%';")
+		TestClient.Exec(`DELETE FROM "Link Global Cats" WHERE link_id LIKE 'cat-graph-test-%';`)
+		TestClient.Exec(`DELETE FROM "Link Global Cat Pairs" WHERE link_id LIKE 'cat-graph-test-%';`)
+	}()
+
+	go_filter := GetCatsOptionalPluralOrSingularForms([]string{"catgraphtestgo"})
+	var test_options = []struct {
+		Options       *model.CatGraphOptions
+		ExpectedNodes map[string]int32
+		// "source-target" => weight
+		ExpectedEdges map[string]int32
+	}{
+		{
+			&model.CatGraphOptions{
+				CatFiltersWithSpellingVariants: go_filter,
+				MinWeight:                      1,
+			},
+			map[string]int32{"catgraphtestgo": 2, "catgraphtestdatabases": 1, "catgraphtestrust": 1},
+			map[string]int32{"catgraphtestdatabases-catgraphtestgo": 1, "catgraphtestgo-catgraphtestrust": 1},
+		},
+		{
+			&model.CatGraphOptions{
+				CatFiltersWithSpellingVariants: go_filter,
+				IncludeNSFW:                    true,
+				MinWeight:                      2,
+			},
+			map[string]int32{"catgraphtestgo": 3, "catgraphtestdatabases": 2, "catgraphtestrust": 1, "NSFW": 1},
+			map[string]int32{"catgraphtestdatabases-catgraphtestgo": 2},
+		},
+		{
+			&model.CatGraphOptions{
+				CatFiltersWithSpellingVariants: go_filter,
+				Period:                         "week",
+				IncludeNSFW:                    true,
+				MinWeight:                      1,
+			},
+			map[string]int32{"catgraphtestgo": 2, "catgraphtestdatabase": 1, "catgraphtestrust": 1, "NSFW": 1},
+			map[string]int32{
+				"catgraphtestdatabase-catgraphtestgo": 1,
+				"catgraphtestdatabase-NSFW":           1,
+				"catgraphtestgo-catgraphtestrust":     1,
+				"catgraphtestgo-NSFW":                 1,
+			},
+		},
+		{
+			&model.CatGraphOptions{
+				CatFiltersWithSpellingVariants: go_filter,
+				NeuteredCatFilters:             []string{"CatGraphTestRust"},
+				MinWeight:                      1,
+			},
+			map[string]int32{"catgraphtestgo": 1, "catgraphtestdatabases": 1},
+			map[string]int32{"catgraphtestdatabases-catgraphtestgo": 1},
+		},
+	}
+
+	for _, to := range test_options {
+		nodes_sql, err := NewCatGraphNodes().FromOptions(to.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := nodes_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes := map[string]int32{}
+		for rows.Next() {
+			var cat string
+			var count int32
+			if err := rows.Scan(&cat, &count); err != nil {
+				t.Fatal(err)
+			}
+			nodes[cat] = count
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+
+		if len(nodes) != len(to.ExpectedNodes) {
+			t.Fatalf("options %+v: expected nodes %v, got %v", to.Options, to.ExpectedNodes, nodes)
+		}
+		for cat, count := range to.ExpectedNodes {
+			if nodes[cat] != count {
+				t.Fatalf("options %+v: expected nodes %v, got %v", to.Options, to.ExpectedNodes, nodes)
+			}
+		}
+
+		edges_sql, err := NewCatGraphEdges(to.Options.MinWeight).FromOptions(to.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err = edges_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		edges := map[string]int32{}
+		for rows.Next() {
+			var source, target string
+			var weight int32
+			if err := rows.Scan(&source, &target, &weight); err != nil {
+				t.Fatal(err)
+			}
+			edges[source+"-"+target] = weight
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+
+		if len(edges) != len(to.ExpectedEdges) {
+			t.Fatalf("options %+v: expected edges %v, got %v", to.Options, to.ExpectedEdges, edges)
+		}
+		for edge, weight := range to.ExpectedEdges {
+			if edges[edge] != weight {
+				t.Fatalf("options %+v: expected edges %v, got %v", to.Options, to.ExpectedEdges, edges)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+// CAT DESCRIPTION
+// (latest revision, with the number of revisions so far)
+func NewCatDescription(cat string) *Query {
+	return &Query{
+		Text: CAT_DESCRIPTION,
+		Args: []any{GetSingularCat(strings.ToLower(cat))},
+	}
+}
+
+const CAT_DESCRIPTION = `SELECT
+	text,
+	edited_by,
+	edited_at,
+	count(*) OVER () AS revision_count
+FROM "Cat Description Revisions"
+WHERE cat = ?
+ORDER BY edited_at DESC, id DESC
+LIMIT 1;`
+
+// Newest first
+type CatDescriptionRevisions struct {
+	*Query
+}
+
+func NewCatDescriptionRevisions(cat string) *CatDescriptionRevisions {
+	return &CatDescriptionRevisions{
+		&Query{
+			Text: CAT_DESCRIPTION_REVISIONS,
+			// 1 extra row is queried to tell if there is a next page
+			Args: []any{
+				GetSingularCat(strings.ToLower(cat)),
+				CAT_DESCRIPTION_REVISIONS_PAGE_LIMIT + 1,
+			},
+		},
+	}
+}
+
+const CAT_DESCRIPTION_REVISIONS = `SELECT
+	id,
+	cat,
+	text,
+	edited_by,
+	edited_at
+FROM "Cat Description Revisions"
+WHERE cat = ?
+ORDER BY edited_at DESC, id DESC
+LIMIT ?;`
+
+func (cdr *CatDescriptionRevisions) FromOptions(opts *model.CatDescriptionRevisionsOptions) (*CatDescriptionRevisions, error) {
+	if opts.Page > 1 {
+		cdr.page(opts.Page)
+	}
+
+	return cdr, nil
+}
+
+func (cdr *CatDescriptionRevisions) page(page uint) *CatDescriptionRevisions {
+	cdr.Text = strings.Replace(
+		cdr.Text,
+		"LIMIT ?;",
+		"LIMIT ? OFFSET ?;",
+		1,
+	)
+	cdr.Args = append(cdr.Args, (page-1)*CAT_DESCRIPTION_REVISIONS_PAGE_LIMIT)
+
+	return cdr
+}
+
+// MONTHLY LINK COUNTS
+// Public links with the cat (or its spelling variants or synonyms) by
+// month submitted, oldest first. Months with no links are omitted.
+func NewMonthlyLinkCountsForCat(cat string) *Query {
+	return &Query{
+		Text: MONTHLY_LINK_COUNTS_FOR_CAT,
+		Args: []any{withOptionalPluralOrSingularForm(cat)},
+	}
+}
+
+const MONTHLY_LINK_COUNTS_FOR_CAT = `SELECT
+	substr(l.submit_date, 1, 7) AS month,
+	count(*) AS count
+FROM "Public Links" l
+WHERE l.id IN (
+	SELECT link_id
+	FROM global_cats_fts
+	WHERE global_cats MATCH ?
+)
+GROUP BY month
+ORDER BY month ASC;`
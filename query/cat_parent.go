@@ -0,0 +1,116 @@
+package query
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/julianlk522/modeep/db"
+)
+
+// CAT HIERARCHY
+// Cached like cat synonyms. LoadCatParents() must be called at startup
+// and again whenever a parent is set or removed.
+var (
+	cat_parents_mu sync.RWMutex
+	// singular cat => parent
+	cat_parents = map[string]string{}
+	// singular parent => child cats
+	cat_children = map[string][]string{}
+)
+
+func LoadCatParents() error {
+	rows, err := db.Client.Query(`SELECT cat, parent FROM "Cat Parents" ORDER BY cat;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	parents := map[string]string{}
+	children := map[string][]string{}
+	for rows.Next() {
+		var cat, parent string
+		if err := rows.Scan(&cat, &parent); err != nil {
+			return err
+		}
+		parents[cat] = parent
+		singular_parent := GetSingularCat(parent)
+		children[singular_parent] = append(children[singular_parent], cat)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	cat_parents_mu.Lock()
+	cat_parents = parents
+	cat_children = children
+	cat_parents_mu.Unlock()
+
+	return nil
+}
+
+func GetCatParent(cat string) (string, bool) {
+	cat_parents_mu.RLock()
+	defer cat_parents_mu.RUnlock()
+
+	parent, ok := cat_parents[GetSingularCat(strings.ToLower(cat))]
+	return parent, ok
+}
+
+// Parent first, root last
+func GetCatAncestors(cat string) []string {
+	cat_parents_mu.RLock()
+	defer cat_parents_mu.RUnlock()
+
+	var ancestors []string
+	current := GetSingularCat(strings.ToLower(cat))
+	seen := map[string]bool{current: true}
+	for {
+		parent, ok := cat_parents[current]
+		if !ok {
+			break
+		}
+		current = GetSingularCat(parent)
+		// (cycles are rejected when parents are set, but just in case)
+		if seen[current] {
+			break
+		}
+		seen[current] = true
+		ancestors = append(ancestors, parent)
+	}
+
+	return ancestors
+}
+
+// Children, grandchildren, etc.
+func GetCatDescendants(cat string) []string {
+	cat_parents_mu.RLock()
+	defer cat_parents_mu.RUnlock()
+
+	var descendants []string
+	singular_cat := GetSingularCat(strings.ToLower(cat))
+	seen := map[string]bool{singular_cat: true}
+	queue := []string{singular_cat}
+	for len(queue) > 0 {
+		for _, child := range cat_children[queue[0]] {
+			if !seen[child] {
+				seen[child] = true
+				descendants = append(descendants, child)
+				queue = append(queue, child)
+			}
+		}
+		queue = queue[1:]
+	}
+
+	return descendants
+}
+
+func CatIsDescendantOf(cat string, ancestor string) bool {
+	singular_ancestor := GetSingularCat(strings.ToLower(ancestor))
+	for _, a := range GetCatAncestors(cat) {
+		if GetSingularCat(a) == singular_ancestor {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestCatParents(t *testing.T) {
+	for cat, parent := range map[string]string{
+		"cattreetestsqlite":   "cattreetestdatabases",
+		"cattreetestpostgre":  "cattreetestdatabases",
+		"cattreetestdatabase": "cattreetestprogramming",
+	} {
+		if _, err := TestClient.Exec(
+			`INSERT INTO "Cat Parents" (cat, parent, set_by, set_at)
+			VALUES (?, ?, 'jlk', '2025-01-01 00:00:00');`,
+			cat,
+			parent,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		TestClient.Exec(`DELETE FROM "Cat Parents" WHERE cat LIKE 'cattreetest%';`)
+		LoadCatParents()
+	}()
+	if err := LoadCatParents(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetCatAncestors("CatTreeTestSQLite"); !slices.Equal(
+		got,
+		[]string{"cattreetestdatabases", "cattreetestprogramming"},
+	) {
+		t.Fatalf("got ancestors %v", got)
+	}
+	// (children, then grandchildren, alphabetically)
+	if got := GetCatDescendants("cattreetestprogramming"); !slices.Equal(
+		got,
+		[]string{"cattreetestdatabase", "cattreetestpostgre", "cattreetestsqlite"},
+	) {
+		t.Fatalf("got descendants %v", got)
+	}
+	if !CatIsDescendantOf("cattreetestsqlites", "cattreetestprogramming") ||
+		CatIsDescendantOf("cattreetestprogramming", "cattreetestsqlite") {
+		t.Fatal("expected sqlite (not programming) to be a descendant")
+	}
+
+	expected := `("cattreetestdatabases" OR "cattreetestdatabaseses" OR "cattreetestdatabase"` +
+		` OR "cattreetestpostgre" OR "cattreetestpostgres" OR "cattreetestsqlite" OR "cattreetestsqlites")`
+	cat_query, err := ParseCatQuery("cattreetestdatabases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cat_query.IncludeDescendants().MatchArgs()[0]; got != expected {
+		t.Fatalf("got %s, want %s", got, expected)
+	}
+
+	// Rolled-up counts: a link tagged only sqlite counts toward databases
+	// and programming
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+		VALUES ('cat-tree-test', 'https://cat-tree-test.com', 'jlk', '2025-01-01', 'cattreetestsqlite');`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Links WHERE id = 'cat-tree-test';")
+
+	counts_sql, err := NewTopGlobalCatCounts().FromOptions(&model.TopCatCountsOptions{
+		RawCatFilters:                  []string{"cattreetestsqlite"},
+		CatFiltersWithSpellingVariants: GetCatsOptionalPluralOrSingularForms([]string{"cattreetestsqlite"}),
+		Tree:                           true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := counts_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var cat string
+		var count int
+		if err := rows.Scan(&cat, &count); err != nil {
+			t.Fatal(err)
+		}
+		counts[cat] = count
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, cat := range []string{"cattreetestdatabases", "cattreetestprogramming"} {
+		if counts[cat] != 1 {
+			t.Fatalf("expected %s count 1, got %d (counts: %v)", cat, counts[cat], counts)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	e "github.com/julianlk522/modeep/error"
+)
+
+// CAT QUERIES
+// Cat filters ("cats" params) are parsed as a small boolean query language:
+//
+//	go AND (databases OR sqlite) NOT orm
+//	"machine learning", data*
+//
+// - AND, OR and NOT must be uppercase; lowercase "and" etc. are cat words
+// (e.g., "rock and roll")
+// - commas are the same as AND, so plain comma-separated cats work as before
+// - precedence is the same as FTS5: NOT, then AND, then OR
+// - NOT is binary ("a NOT b"): a query can't consist only of exclusions
+// - adjacent words form one multi-word cat
+// - quoted phrases are matched exactly (no plural/singular variants) and
+// may contain keywords or parentheses
+// - a trailing * matches any cat starting with the preceding text
+//
+// Queries compile to FTS5 MATCH args, with plural/singular variants added
+// to (unquoted, non-prefix) cats like GetCatsOptionalPluralOrSingularForms().
+type CatQuery struct {
+	root catQueryNode
+}
+
+type catQueryNode interface {
+	matchArg() string
+	cats() []string
+	includeDescendants()
+}
+
+type catQueryTerm struct {
+	Cat    string
+	Prefix bool
+	Quoted bool
+	// Also match descendants in the cat hierarchy, e.g., "sqlite" for
+	// "databases"
+	Descendants bool
+}
+
+type catQueryAnd struct {
+	Operands []catQueryNode
+}
+
+type catQueryOr struct {
+	Operands []catQueryNode
+}
+
+type catQueryNot struct {
+	Include catQueryNode
+	Exclude catQueryNode
+}
+
+func ParseCatQuery(input string) (*CatQuery, error) {
+	tokens, err := lexCatQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, e.InvalidCatQuery(1, "empty query")
+	}
+
+	p := &catQueryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.Kind != cat_query_token_end {
+		return nil, e.InvalidCatQuery(t.Pos, "unexpected "+t.describe())
+	}
+
+	return &CatQuery{root}, nil
+}
+
+// MATCH args to be joined with " AND ": 1 per top-level AND operand, so
+// comma-separated cats produce the same args as
+// GetCatsOptionalPluralOrSingularForms()
+func (cq *CatQuery) MatchArgs() []string {
+	if and, ok := cq.root.(*catQueryAnd); ok {
+		args := make([]string, len(and.Operands))
+		for i, o := range and.Operands {
+			args[i] = o.matchArg()
+		}
+		return args
+	}
+
+	return []string{cq.root.matchArg()}
+}
+
+// Unquoted, non-prefix cats also match their descendants, e.g.,
+// "databases" matches links tagged only "sqlite"
+func (cq *CatQuery) IncludeDescendants() *CatQuery {
+	cq.root.includeDescendants()
+	return cq
+}
+
+// Cats searched for as-is, e.g., to omit from cat counts and spellfix
+// matches since they are already being filtered for.
+// Prefixes and cats after NOT are omitted.
+func (cq *CatQuery) Cats() []string {
+	return cq.root.cats()
+}
+
+func (t *catQueryTerm) matchArg() string {
+	lc_cat := strings.ToLower(t.Cat)
+	if t.Prefix {
+		return getCatSurroundedInDoubleQuotes(lc_cat) + "*"
+	} else if t.Quoted {
+		return getCatSurroundedInDoubleQuotes(lc_cat)
+	} else if t.Descendants {
+		return withOptionalPluralOrSingularFormAndDescendants(lc_cat)
+	}
+	return withOptionalPluralOrSingularForm(lc_cat)
+}
+
+func (t *catQueryTerm) includeDescendants() {
+	t.Descendants = true
+}
+
+func (t *catQueryTerm) cats() []string {
+	if t.Prefix {
+		return nil
+	}
+	return []string{t.Cat}
+}
+
+func (a *catQueryAnd) matchArg() string {
+	args := make([]string, len(a.Operands))
+	for i, o := range a.Operands {
+		args[i] = o.matchArg()
+	}
+	return "(" + strings.Join(args, " AND ") + ")"
+}
+
+func (a *catQueryAnd) includeDescendants() {
+	for _, o := range a.Operands {
+		o.includeDescendants()
+	}
+}
+
+func (a *catQueryAnd) cats() []string {
+	var cats []string
+	for _, o := range a.Operands {
+		cats = append(cats, o.cats()...)
+	}
+	return cats
+}
+
+func (o *catQueryOr) matchArg() string {
+	args := make([]string, len(o.Operands))
+	for i, op := range o.Operands {
+		args[i] = op.matchArg()
+	}
+	return "(" + strings.Join(args, " OR ") + ")"
+}
+
+func (o *catQueryOr) includeDescendants() {
+	for _, op := range o.Operands {
+		op.includeDescendants()
+	}
+}
+
+func (o *catQueryOr) cats() []string {
+	var cats []string
+	for _, op := range o.Operands {
+		cats = append(cats, op.cats()...)
+	}
+	return cats
+}
+
+func (n *catQueryNot) matchArg() string {
+	return "(" + n.Include.matchArg() + " NOT " + n.Exclude.matchArg() + ")"
+}
+
+func (n *catQueryNot) cats() []string {
+	return n.Include.cats()
+}
+
+func (n *catQueryNot) includeDescendants() {
+	n.Include.includeDescendants()
+	n.Exclude.includeDescendants()
+}
+
+// LEXER
+type catQueryTokenKind int
+
+const (
+	cat_query_token_end catQueryTokenKind = iota
+	cat_query_token_word
+	cat_query_token_phrase
+	cat_query_token_and
+	cat_query_token_or
+	cat_query_token_not
+	cat_query_token_comma
+	cat_query_token_lparen
+	cat_query_token_rparen
+)
+
+type catQueryToken struct {
+	Kind catQueryTokenKind
+	Text string
+	// 1-based, in characters
+	Pos int
+}
+
+func (t catQueryToken) describe() string {
+	switch t.Kind {
+	case cat_query_token_end:
+		return "end of query"
+	case cat_query_token_comma:
+		return `","`
+	case cat_query_token_lparen:
+		return `"("`
+	case cat_query_token_rparen:
+		return `")"`
+	case cat_query_token_phrase:
+		return `"\"` + t.Text + `\""`
+	default:
+		return `"` + t.Text + `"`
+	}
+}
+
+func lexCatQuery(input string) ([]catQueryToken, error) {
+	var tokens []catQueryToken
+	chars := []rune(input)
+
+	for i := 0; i < len(chars); {
+		c := chars[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == ',':
+			tokens = append(tokens, catQueryToken{cat_query_token_comma, ",", pos})
+			i++
+		case c == '(':
+			tokens = append(tokens, catQueryToken{cat_query_token_lparen, "(", pos})
+			i++
+		case c == ')':
+			tokens = append(tokens, catQueryToken{cat_query_token_rparen, ")", pos})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(chars) && chars[end] != '"' {
+				end++
+			}
+			if end == len(chars) {
+				return nil, e.InvalidCatQuery(pos, "unterminated quoted phrase")
+			}
+			phrase := strings.Join(strings.Fields(string(chars[i+1:end])), " ")
+			if phrase == "" {
+				return nil, e.InvalidCatQuery(pos, "empty quoted phrase")
+			}
+			tokens = append(tokens, catQueryToken{cat_query_token_phrase, phrase, pos})
+			i = end + 1
+		default:
+			end := i
+			for end < len(chars) &&
+				!unicode.IsSpace(chars[end]) &&
+				!strings.ContainsRune(`,()"`, chars[end]) {
+				end++
+			}
+			word := string(chars[i:end])
+			if star := strings.IndexRune(word, '*'); star != -1 && star != len(word)-1 {
+				return nil, e.InvalidCatQuery(pos, "* is only allowed at the end of a cat")
+			}
+
+			kind := cat_query_token_word
+			switch word {
+			case "AND":
+				kind = cat_query_token_and
+			case "OR":
+				kind = cat_query_token_or
+			case "NOT":
+				kind = cat_query_token_not
+			}
+			tokens = append(tokens, catQueryToken{kind, word, pos})
+			i = end
+		}
+	}
+
+	return append(tokens, catQueryToken{cat_query_token_end, "", len(chars) + 1}), nil
+}
+
+// PARSER
+// (recursive descent)
+//
+//	or      = and { "OR" and }
+//	and     = not { ("AND" | ",") not }
+//	not     = primary { "NOT" primary }
+//	primary = "(" or ")" | phrase | word { word }
+type catQueryParser struct {
+	tokens []catQueryToken
+	i      int
+}
+
+func (p *catQueryParser) peek() catQueryToken {
+	return p.tokens[p.i]
+}
+
+func (p *catQueryParser) next() catQueryToken {
+	t := p.tokens[p.i]
+	if t.Kind != cat_query_token_end {
+		p.i++
+	}
+	return t
+}
+
+func (p *catQueryParser) parseOr() (catQueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []catQueryNode{first}
+	for p.peek().Kind == cat_query_token_or {
+		p.next()
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &catQueryOr{operands}, nil
+}
+
+func (p *catQueryParser) parseAnd() (catQueryNode, error) {
+	first, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []catQueryNode{first}
+	for p.peek().Kind == cat_query_token_and || p.peek().Kind == cat_query_token_comma {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &catQueryAnd{operands}, nil
+}
+
+func (p *catQueryParser) parseNot() (catQueryNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().Kind == cat_query_token_not {
+		p.next()
+		exclude, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		node = &catQueryNot{node, exclude}
+	}
+
+	return node, nil
+}
+
+func (p *catQueryParser) parsePrimary() (catQueryNode, error) {
+	t := p.next()
+
+	switch t.Kind {
+	case cat_query_token_lparen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != cat_query_token_rparen {
+			return nil, e.InvalidCatQuery(closing.Pos, `expected ")" to close "(" at position `+strconv.Itoa(t.Pos)+", got "+closing.describe())
+		}
+		return node, nil
+	case cat_query_token_phrase:
+		return &catQueryTerm{Cat: t.Text, Quoted: true}, nil
+	case cat_query_token_word:
+		words := []string{t.Text}
+		for p.peek().Kind == cat_query_token_word {
+			if strings.HasSuffix(words[len(words)-1], "*") {
+				break
+			}
+			words = append(words, p.next().Text)
+		}
+		cat := strings.Join(words, " ")
+
+		// Another word after a prefix, e.g., "data* science"
+		if strings.HasSuffix(cat, "*") && p.peek().Kind == cat_query_token_word {
+			return nil, e.InvalidCatQuery(p.peek().Pos, "* is only allowed at the end of a cat")
+		}
+		if prefix, ok := strings.CutSuffix(cat, "*"); ok {
+			if prefix == "" {
+				return nil, e.InvalidCatQuery(t.Pos, "* must follow a cat")
+			}
+			return &catQueryTerm{Cat: prefix, Prefix: true}, nil
+		}
+		return &catQueryTerm{Cat: cat}, nil
+	case cat_query_token_not:
+		return nil, e.InvalidCatQuery(t.Pos, `NOT must follow a cat, e.g., "go NOT orm"`)
+	default:
+		return nil, e.InvalidCatQuery(t.Pos, "expected a cat, got "+t.describe())
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCatQuery(t *testing.T) {
+	var test_queries = []struct {
+		Query             string
+		ExpectedMatchArgs []string
+		ExpectedCats      []string
+	}{
+		// Same as GetCatsOptionalPluralOrSingularForms()
+		{
+			"go,Databases",
+			GetCatsOptionalPluralOrSingularForms([]string{"go", "Databases"}),
+			[]string{"go", "Databases"},
+		},
+		{
+			" go , machine  learning ",
+			[]string{`("go" OR "gos")`, `("machine learning" OR "machine learnings")`},
+			[]string{"go", "machine learning"},
+		},
+		// lowercase keywords are cat words
+		{
+			"rock and roll",
+			[]string{`("rock and roll" OR "rock and rolls")`},
+			[]string{"rock and roll"},
+		},
+		{
+			"go AND (databases OR sqlite) NOT orm",
+			[]string{
+				`("go" OR "gos")`,
+				`((("databases" OR "databaseses" OR "database") OR ("sqlite" OR "sqlites")) NOT ("orm" OR "orms"))`,
+			},
+			[]string{"go", "databases", "sqlite"},
+		},
+		// OR has lowest precedence
+		{
+			"go AND sqlite OR rust",
+			[]string{`((("go" OR "gos") AND ("sqlite" OR "sqlites")) OR ("rust" OR "rusts"))`},
+			[]string{"go", "sqlite", "rust"},
+		},
+		// quoted phrases are exact
+		{
+			`"Machine Learning" OR "rock AND roll"`,
+			[]string{`("machine learning" OR "rock and roll")`},
+			[]string{"Machine Learning", "rock AND roll"},
+		},
+		// prefixes
+		{
+			"data*, go",
+			[]string{`"data"*`, `("go" OR "gos")`},
+			[]string{"go"},
+		},
+		{
+			"machine lear* NOT deep*",
+			[]string{`("machine lear"* NOT "deep"*)`},
+			nil,
+		},
+	}
+
+	for _, tq := range test_queries {
+		cq, err := ParseCatQuery(tq.Query)
+		if err != nil {
+			t.Fatalf("query %q: %s", tq.Query, err)
+		}
+		if got := cq.MatchArgs(); !slices.Equal(got, tq.ExpectedMatchArgs) {
+			t.Fatalf("query %q: expected match args %q, got %q", tq.Query, tq.ExpectedMatchArgs, got)
+		}
+		if got := cq.Cats(); !slices.Equal(got, tq.ExpectedCats) {
+			t.Fatalf("query %q: expected cats %q, got %q", tq.Query, tq.ExpectedCats, got)
+		}
+	}
+}
+
+func TestParseCatQueryErrors(t *testing.T) {
+	var test_queries = []struct {
+		Query       string
+		ExpectedPos string
+	}{
+		{"", "position 1"},
+		{"   ", "position 1"},
+		{"go,", "position 4"},
+		{",go", "position 1"},
+		{"go,,sqlite", "position 4"},
+		{"go AND", "position 7"},
+		{"OR go", "position 1"},
+		{"NOT orm", "position 1"},
+		{"go NOT NOT orm", "position 8"},
+		{"(go OR sqlite", "position 14"},
+		{"go OR sqlite)", "position 13"},
+		{"go (sqlite)", "position 4"},
+		{"()", "position 2"},
+		{`go, "sqlite`, "position 5"},
+		{`go, ""`, "position 5"},
+		{"da*ta", "position 1"},
+		{"data* science", "position 7"},
+		{"*", "position 1"},
+	}
+
+	for _, tq := range test_queries {
+		_, err := ParseCatQuery(tq.Query)
+		if err == nil {
+			t.Fatalf("expected error for query %q", tq.Query)
+		} else if !strings.Contains(err.Error(), tq.ExpectedPos) {
+			t.Fatalf("query %q: expected error at %s, got %q", tq.Query, tq.ExpectedPos, err)
+		}
+	}
+}
+
+func TestCatQueryMatchArgs(t *testing.T) {
+	has := func(global_cats string, cat string) bool {
+		return strings.Contains(strings.ToLower(global_cats), cat)
+	}
+	var test_queries = []struct {
+		Query   string
+		Matches func(global_cats string) bool
+	}{
+		{
+			"umvc3, flowers",
+			func(gc string) bool { return has(gc, "umvc3") && has(gc, "flower") },
+		},
+		{
+			"umvc3 OR coding",
+			func(gc string) bool { return has(gc, "umvc3") || has(gc, "coding") },
+		},
+		{
+			"(umvc3 OR coding) NOT flowers",
+			func(gc string) bool { return (has(gc, "umvc3") || has(gc, "coding")) && !has(gc, "flower") },
+		},
+		{
+			"flow*",
+			func(gc string) bool { return has(gc, "flow") },
+		},
+	}
+
+	for _, tq := range test_queries {
+		cq, err := ParseCatQuery(tq.Query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := TestClient.Query(
+			`SELECT global_cats FROM global_cats_fts WHERE global_cats MATCH ?;`,
+			strings.Join(cq.MatchArgs(), " AND "),
+		)
+		if err != nil {
+			t.Fatalf("query %q: %s", tq.Query, err)
+		}
+
+		var found bool
+		for rows.Next() {
+			var global_cats string
+			if err := rows.Scan(&global_cats); err != nil {
+				t.Fatal(err)
+			} else if !tq.Matches(global_cats) {
+				t.Fatalf("query %q: unexpected match %q", tq.Query, global_cats)
+			}
+			found = true
+		}
+		rows.Close()
+
+		if !found {
+			t.Fatalf("query %q: no matches", tq.Query)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/julianlk522/modeep/db"
+	"github.com/julianlk522/modeep/model"
+)
+
+// CAT SYNONYMS
+// Approved synonym groups are cached so that cat filter expansion and
+// cat comparisons don't query the DB for every cat. LoadCatSynonyms()
+// must be called at startup and again whenever a synonym is approved.
+var (
+	cat_synonyms_mu sync.RWMutex
+	// member or canonical form => canonical form
+	cat_synonym_canonicals = map[string]string{}
+	// canonical form => members, e.g., "javascript" => ["ecmascript", "javascript", "js"]
+	cat_synonym_groups = map[string][]string{}
+)
+
+func LoadCatSynonyms() error {
+	rows, err := db.Client.Query(`SELECT cat, canonical FROM "Approved Cat Synonyms";`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	canonicals := map[string]string{}
+	groups := map[string][]string{}
+	for rows.Next() {
+		var cat, canonical string
+		if err := rows.Scan(&cat, &canonical); err != nil {
+			return err
+		}
+		canonicals[cat] = canonical
+		canonicals[canonical] = canonical
+		groups[canonical] = append(groups[canonical], cat)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, members := range groups {
+		slices.Sort(members)
+	}
+
+	cat_synonyms_mu.Lock()
+	cat_synonym_canonicals = canonicals
+	cat_synonym_groups = groups
+	cat_synonyms_mu.Unlock()
+
+	return nil
+}
+
+// Other members of cat's approved synonym group, not including cat or
+// its plural/singular variants
+func GetCatSynonyms(cat string) []string {
+	lc_cat := strings.ToLower(cat)
+	canonical, ok := getCatSynonymCanonical(lc_cat)
+	if !ok {
+		return nil
+	}
+
+	cat_synonyms_mu.RLock()
+	defer cat_synonyms_mu.RUnlock()
+
+	var synonyms []string
+	for _, member := range cat_synonym_groups[canonical] {
+		if GetSingularCat(member) != GetSingularCat(lc_cat) {
+			synonyms = append(synonyms, member)
+		}
+	}
+
+	return synonyms
+}
+
+func CatsAreSynonyms(a string, b string) bool {
+	canonical_a, ok := getCatSynonymCanonical(strings.ToLower(a))
+	if !ok {
+		return false
+	}
+	canonical_b, ok := getCatSynonymCanonical(strings.ToLower(b))
+
+	return ok && canonical_a == canonical_b
+}
+
+// Whether cat (or a plural/singular variant) is the cat that others in
+// its approved synonym group are synonyms of
+func CatHasSynonyms(cat string) bool {
+	lc_cat := strings.ToLower(cat)
+	canonical, ok := getCatSynonymCanonical(lc_cat)
+	return ok && GetSingularCat(lc_cat) == canonical
+}
+
+// Whether cat (or a plural/singular variant) is an approved synonym of
+// another cat
+func CatIsSynonymOfAnother(cat string) bool {
+	lc_cat := strings.ToLower(cat)
+	canonical, ok := getCatSynonymCanonical(lc_cat)
+	return ok && GetSingularCat(lc_cat) != canonical
+}
+
+// Plural/singular variants of members are matched too, e.g., "golangs"
+// if "golang" is a synonym of "go"
+func getCatSynonymCanonical(lc_cat string) (string, bool) {
+	cat_synonyms_mu.RLock()
+	defer cat_synonyms_mu.RUnlock()
+
+	if canonical, ok := cat_synonym_canonicals[lc_cat]; ok {
+		return canonical, true
+	}
+	canonical, ok := cat_synonym_canonicals[GetSingularCat(lc_cat)]
+	return canonical, ok
+}
+
+// Same as singularCatSQL()
+func GetSingularCat(lc_cat string) string {
+	if strings.HasSuffix(lc_cat, "sses") {
+		return strings.TrimSuffix(lc_cat, "es")
+	} else if strings.HasSuffix(lc_cat, "s") && !strings.HasSuffix(lc_cat, "ss") {
+		return strings.TrimSuffix(lc_cat, "s")
+	}
+	return lc_cat
+}
+
+// e.g., "Tests" => "test"
+func singularCatSQL(col string) string {
+	return fmt.Sprintf(`CASE
+			WHEN LOWER(%[1]s) LIKE '%%sses' THEN substr(LOWER(%[1]s), 1, length(LOWER(%[1]s)) - 2)
+			WHEN LOWER(%[1]s) LIKE '%%s' AND NOT LOWER(%[1]s) LIKE '%%ss' THEN substr(LOWER(%[1]s), 1, length(LOWER(%[1]s)) - 1)
+			ELSE LOWER(%[1]s)
+		END`, col)
+}
+
+// Canonical form if an approved synonym, else singular form
+// e.g., "JS" => "javascript", "Tests" => "test"
+func normalizedCatSQL(col string) string {
+	singular := singularCatSQL(col)
+	return fmt.Sprintf(`COALESCE(
+		(
+			SELECT acs.canonical
+			FROM "Approved Cat Synonyms" acs
+			WHERE acs.cat IN (LOWER(%s), %s)
+			LIMIT 1
+		),
+		%s
+	)`, col, singular, singular)
+}
+
+// Proposed synonyms awaiting review by default, oldest first
+type CatSynonyms struct {
+	*Query
+}
+
+func NewCatSynonyms() *CatSynonyms {
+	return &CatSynonyms{
+		&Query{
+			Text: CAT_SYNONYMS,
+			// 1 extra row is queried to tell if there is a next page
+			Args: []any{model.CatSynonymPending, CAT_SYNONYMS_PAGE_LIMIT + 1},
+		},
+	}
+}
+
+const CAT_SYNONYMS = `SELECT
+	id,
+	cat,
+	synonym_of,
+	proposed_by,
+	proposed_at,
+	status,
+	reviewed_by,
+	reviewed_at
+FROM "Cat Synonyms"
+WHERE status = ?
+ORDER BY proposed_at ASC, id ASC
+LIMIT ?;`
+
+func (cs *CatSynonyms) FromOptions(opts *model.CatSynonymsOptions) (*CatSynonyms, error) {
+	if opts.Status != "" {
+		cs.withStatus(opts.Status)
+	}
+	if opts.Page > 1 {
+		cs.page(opts.Page)
+	}
+
+	return cs, nil
+}
+
+// Reviewed synonyms are ordered by synonym_of, then cat
+func (cs *CatSynonyms) withStatus(status model.CatSynonymStatus) *CatSynonyms {
+	cs.Args[0] = status
+	if status != model.CatSynonymPending {
+		cs.Text = strings.Replace(
+			cs.Text,
+			"ORDER BY proposed_at ASC, id ASC",
+			"ORDER BY synonym_of ASC, cat ASC",
+			1,
+		)
+	}
+
+	return cs
+}
+
+func (cs *CatSynonyms) page(page uint) *CatSynonyms {
+	cs.Text = strings.Replace(
+		cs.Text,
+		"LIMIT ?;",
+		"LIMIT ? OFFSET ?;",
+		1,
+	)
+	cs.Args = append(cs.Args, (page-1)*CAT_SYNONYMS_PAGE_LIMIT)
+
+	return cs
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCatSynonyms(t *testing.T) {
+	for _, s := range [][2]string{
+		{"synonymtestjs", "synonymtestjavascript"},
+		{"synonymtestecmascript", "synonymtestjavascript"},
+		{"zzyzx", "synonymtestjavascript"},
+		{"golang", "go"},
+	} {
+		if _, err := TestClient.Exec(
+			`INSERT INTO "Cat Synonyms" (id, cat, synonym_of, proposed_by, proposed_at, status)
+			VALUES (?, ?, ?, 'jlk', '2025-01-01 00:00:00', 'approved');`,
+			"synonym-test-"+s[0],
+			s[0],
+			s[1],
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		TestClient.Exec(`DELETE FROM "Cat Synonyms" WHERE id LIKE 'synonym-test-%';`)
+		LoadCatSynonyms()
+	}()
+	if err := LoadCatSynonyms(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Filter expansion
+	for _, tc := range []struct {
+		Cat            string
+		ExpectedResult string
+	}{
+		{"golang", `("golang" OR "golangs" OR "go" OR "gos")`},
+		{"Go", `("go" OR "gos" OR "golang" OR "golangs")`},
+		{"music", `("music" OR "musics")`},
+	} {
+		if got := withOptionalPluralOrSingularForm(tc.Cat); got != tc.ExpectedResult {
+			t.Fatalf("got %s, want %s", got, tc.ExpectedResult)
+		}
+	}
+
+	for _, tc := range []struct {
+		CatA           string
+		CatB           string
+		ExpectedResult bool
+	}{
+		{"synonymtestjs", "synonymtestjavascript", true},
+		{"synonymtestjs", "synonymtestecmascript", true},
+		{"SynonymTestJavascripts", "synonymtestecmascript", true},
+		{"golang", "synonymtestjs", false},
+		{"music", "musics", false},
+	} {
+		if got := CatsAreSynonyms(tc.CatA, tc.CatB); got != tc.ExpectedResult {
+			t.Fatalf("expected %t, got %t for cats %s and %s", tc.ExpectedResult, got, tc.CatA, tc.CatB)
+		}
+	}
+
+	if got := GetCatSynonyms("synonymtestjs"); !slices.Equal(
+		got,
+		[]string{"synonymtestecmascript", "synonymtestjavascript", "zzyzx"},
+	) {
+		t.Fatalf("got synonyms %v", got)
+	}
+	if !CatHasSynonyms("gos") || CatHasSynonyms("golang") {
+		t.Fatal("expected go (not golang) to have synonyms")
+	} else if !CatIsSynonymOfAnother("golangs") || CatIsSynonymOfAnother("go") {
+		t.Fatal("expected golang (not go) to be a synonym of another cat")
+	}
+
+	// Spellfix suggestions: synonyms' ranks combine under the most used,
+	// even if it isn't a close spelling
+	for word, rank := range map[string]int{
+		"zzyzx":                 2,
+		"synonymtestjavascript": 5,
+	} {
+		if _, err := TestClient.Exec(
+			"INSERT INTO global_cats_spellfix (word, rank) VALUES (?, ?);",
+			word,
+			rank,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer TestClient.Exec("DELETE FROM global_cats_spellfix WHERE word IN ('zzyzx', 'synonymtestjavascript');")
+
+	rows, err := NewSpellfixMatchesForSnippet("zzyzx").ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var found_javascript bool
+	for rows.Next() {
+		var word string
+		var rank int
+		if err := rows.Scan(&word, &rank); err != nil {
+			t.Fatal(err)
+		}
+
+		switch word {
+		case "zzyzx":
+			t.Fatal("expected zzyzx to be merged into synonymtestjavascript")
+		case "synonymtestjavascript":
+			found_javascript = true
+			if rank != 7 {
+				t.Fatalf("expected combined rank 7, got %d", rank)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	} else if !found_javascript {
+		t.Fatal("expected synonymtestjavascript to be suggested for zzyzx")
+	}
+}
@@ -0,0 +1,263 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+// LINK CLICKS
+// Only qualified clicks (see GetClickDisqualification) are counted.
+// Anonymous clicks are stored with user_id 'anonymous', and older ones
+// with ip_addr as "host:port" (or "[host]:port" for IPv6), so any port
+// is removed before counting distinct IPs.
+const LINK_CLICKS_CTE = `WITH LinkClicks AS (
+	SELECT
+		timestamp,
+		NULLIF(user_id, 'anonymous') AS user_id,
+		CASE WHEN user_id = 'anonymous' THEN
+			CASE
+				WHEN ip_addr LIKE '[%]:%'
+					THEN substr(ip_addr, 2, instr(ip_addr, ']') - 2)
+				WHEN length(ip_addr) - length(replace(ip_addr, ':', '')) = 1
+					THEN substr(ip_addr, 1, instr(ip_addr, ':') - 1)
+				ELSE ip_addr
+			END
+		END AS anonymous_ip,
+		surface
+	FROM Clicks
+	WHERE link_id = ?
+	AND disqualified_reason = ''
+)`
+
+const LINK_CLICKS_PERIOD_AND = `
+	AND timestamp >= date('now', '-%d days')`
+
+func applyLinkClicksPeriod(q *Query, period model.Period) {
+	if period == "" || period == model.PeriodAll {
+		return
+	}
+
+	days, ok := model.ValidPeriodsInDays[period]
+	if !ok {
+		q.Error = e.ErrInvalidPeriod
+		return
+	}
+
+	q.Text = strings.Replace(
+		q.Text,
+		"WHERE link_id = ?",
+		"WHERE link_id = ?"+fmt.Sprintf(LINK_CLICKS_PERIOD_AND, days),
+		1,
+	)
+}
+
+// BUCKETS
+type LinkClickBuckets struct {
+	*Query
+}
+
+func NewLinkClickBuckets(link_id string) *LinkClickBuckets {
+	return &LinkClickBuckets{
+		&Query{
+			Text: LINK_CLICKS_CTE + LINK_CLICK_BUCKETS,
+			Args: []any{link_id},
+		},
+	}
+}
+
+const LINK_CLICK_BUCKETS = `
+SELECT
+	date(timestamp) AS bucket_start,
+	COUNT(*) AS clicks,
+	COUNT(DISTINCT user_id) AS unique_users,
+	COUNT(DISTINCT anonymous_ip) AS anonymous_ips
+FROM LinkClicks
+GROUP BY bucket_start
+ORDER BY bucket_start ASC;`
+
+// Weeks start on Monday: 'weekday 0' advances to the next Sunday
+// (or stays if already Sunday), then back 6 days
+var LINK_CLICK_BUCKET_EXPRESSIONS = map[model.ClickBucket]string{
+	model.ClickBucketDay:  "date(timestamp)",
+	model.ClickBucketWeek: "date(timestamp, 'weekday 0', '-6 days')",
+}
+
+func (lcb *LinkClickBuckets) FromOptions(opts *model.LinkClicksOptions) (*LinkClickBuckets, error) {
+	if opts.Bucket != "" {
+		lcb.byBucket(opts.Bucket)
+	}
+	applyLinkClicksPeriod(lcb.Query, opts.Period)
+
+	if lcb.Error != nil {
+		return nil, lcb.Error
+	}
+	return lcb, nil
+}
+
+func (lcb *LinkClickBuckets) byBucket(bucket model.ClickBucket) *LinkClickBuckets {
+	expression, ok := LINK_CLICK_BUCKET_EXPRESSIONS[bucket]
+	if !ok {
+		lcb.Error = e.ErrInvalidClickBucket
+		return lcb
+	}
+
+	lcb.Text = strings.Replace(
+		lcb.Text,
+		"date(timestamp) AS bucket_start",
+		expression+" AS bucket_start",
+		1,
+	)
+
+	return lcb
+}
+
+// TOTALS
+// (unique users and IPs can't be summed from buckets)
+type LinkClickCounts struct {
+	*Query
+}
+
+func NewLinkClickCounts(link_id string) *LinkClickCounts {
+	return &LinkClickCounts{
+		&Query{
+			Text: LINK_CLICKS_CTE + LINK_CLICK_COUNTS,
+			Args: []any{link_id},
+		},
+	}
+}
+
+const LINK_CLICK_COUNTS = `
+SELECT
+	COUNT(*) AS clicks,
+	COUNT(DISTINCT user_id) AS unique_users,
+	COUNT(DISTINCT anonymous_ip) AS anonymous_ips
+FROM LinkClicks;`
+
+func (lcc *LinkClickCounts) FromOptions(opts *model.LinkClicksOptions) (*LinkClickCounts, error) {
+	applyLinkClicksPeriod(lcc.Query, opts.Period)
+
+	if lcc.Error != nil {
+		return nil, lcc.Error
+	}
+	return lcc, nil
+}
+
+// SURFACES
+type LinkClickSurfaces struct {
+	*Query
+}
+
+func NewLinkClickSurfaces(link_id string) *LinkClickSurfaces {
+	return &LinkClickSurfaces{
+		&Query{
+			Text: LINK_CLICKS_CTE + LINK_CLICK_SURFACES,
+			Args: []any{link_id, model.ClickSurfaceUnknown},
+		},
+	}
+}
+
+const LINK_CLICK_SURFACES = `
+SELECT
+	CASE WHEN surface = '' THEN ? ELSE surface END AS surface,
+	COUNT(*) AS clicks
+FROM LinkClicks
+GROUP BY 1
+ORDER BY clicks DESC, surface ASC;`
+
+func (lcs *LinkClickSurfaces) FromOptions(opts *model.LinkClicksOptions) (*LinkClickSurfaces, error) {
+	applyLinkClicksPeriod(lcs.Query, opts.Period)
+
+	if lcs.Error != nil {
+		return nil, lcs.Error
+	}
+	return lcs, nil
+}
+
+// RAW CLICKS
+// Every recorded click including disqualified ones, newest first
+// (admin only)
+type RawClicks struct {
+	*Query
+}
+
+func NewRawClicks() *RawClicks {
+	return &RawClicks{
+		&Query{
+			Text: RAW_CLICKS,
+			Args: []any{RAW_CLICKS_PAGE_LIMIT + 1},
+		},
+	}
+}
+
+const RAW_CLICKS = `SELECT
+	id,
+	link_id,
+	user_id,
+	COALESCE(ip_addr, '') AS ip_addr,
+	user_agent,
+	timestamp,
+	surface,
+	disqualified_reason
+FROM Clicks
+ORDER BY timestamp DESC, id ASC
+LIMIT ?;`
+
+func (rc *RawClicks) FromOptions(opts *model.RawClicksOptions) (*RawClicks, error) {
+	if opts.LinkID != "" {
+		rc.forLink(opts.LinkID)
+	}
+	if opts.DisqualifiedOnly {
+		rc.disqualifiedOnly()
+	}
+	if opts.Page > 1 {
+		rc.page(opts.Page)
+	}
+
+	return rc, nil
+}
+
+func (rc *RawClicks) forLink(link_id string) *RawClicks {
+	rc.addCondition("link_id = ?")
+
+	// prepend arg
+	rc.Args = append([]any{link_id}, rc.Args...)
+	return rc
+}
+
+func (rc *RawClicks) disqualifiedOnly() *RawClicks {
+	rc.addCondition("disqualified_reason != ''")
+	return rc
+}
+
+func (rc *RawClicks) addCondition(condition string) {
+	if strings.Contains(rc.Text, "\nWHERE ") {
+		rc.Text = strings.Replace(
+			rc.Text,
+			"\nORDER BY",
+			"\nAND "+condition+"\nORDER BY",
+			1,
+		)
+	} else {
+		rc.Text = strings.Replace(
+			rc.Text,
+			"\nORDER BY",
+			"\nWHERE "+condition+"\nORDER BY",
+			1,
+		)
+	}
+}
+
+func (rc *RawClicks) page(page uint) *RawClicks {
+	rc.Text = strings.Replace(
+		rc.Text,
+		"LIMIT ?;",
+		"LIMIT ? OFFSET ?;",
+		1,
+	)
+	rc.Args = append(rc.Args, (page-1)*RAW_CLICKS_PAGE_LIMIT)
+
+	return rc
+}
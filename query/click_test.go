@@ -0,0 +1,262 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestLinkClicks(t *testing.T) {
+	test_link_id := "link-clicks-test"
+	test_clicks := []struct {
+		UserID    string
+		IPAddr    string
+		Timestamp string
+		Surface   model.ClickSurface
+	}{
+		// Monday
+		{"u1", "", "2025-03-03 10:00:00", model.ClickSurfaceTopLinks},
+		{"u1", "", "2025-03-03 11:00:00", model.ClickSurfaceTopLinks},
+		// same IP, different port: 1 anonymous IP
+		{"anonymous", "1.2.3.4:1000", "2025-03-03 12:00:00", model.ClickSurfaceTmap},
+		{"anonymous", "1.2.3.4:2000", "2025-03-04 12:00:00", ""},
+		// Sunday, same week
+		{"u2", "", "2025-03-09 09:00:00", model.ClickSurfaceTopLinks},
+		// Next Monday
+		{"anonymous", "[2001:db8::1]:3000", "2025-03-10 09:00:00", model.ClickSurfaceCatPage},
+	}
+	for i, c := range test_clicks {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp, surface)
+			VALUES (?, ?, ?, ?, ?, ?);`,
+			test_link_id+string(rune('a'+i)),
+			test_link_id,
+			c.UserID,
+			c.IPAddr,
+			c.Timestamp,
+			c.Surface,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not counted
+	if _, err := TestClient.Exec(
+		`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp, disqualified_reason)
+		VALUES (?, ?, 'anonymous', '9.9.9.9', '2025-03-03 13:00:00', ?);`,
+		test_link_id+"-disqualified",
+		test_link_id,
+		model.ClickBotUserAgent,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Clicks WHERE link_id = ?;", test_link_id)
+
+	opts := &model.LinkClicksOptions{LinkID: test_link_id}
+
+	// Totals
+	counts_sql, err := NewLinkClickCounts(test_link_id).FromOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts model.LinkClickCounts
+	row, err := counts_sql.ValidateAndExecuteRow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := row.Scan(&counts.Clicks, &counts.UniqueUsers, &counts.AnonymousIPs); err != nil {
+		t.Fatal(err)
+	}
+	expected_counts := model.LinkClickCounts{Clicks: 6, UniqueUsers: 2, AnonymousIPs: 2}
+	if counts != expected_counts {
+		t.Fatalf("expected counts %+v, got %+v", expected_counts, counts)
+	}
+
+	// Buckets
+	var test_buckets = []struct {
+		Bucket          model.ClickBucket
+		ExpectedBuckets []model.LinkClickBucketCounts
+	}{
+		{
+			model.ClickBucketDay,
+			[]model.LinkClickBucketCounts{
+				{Start: "2025-03-03", LinkClickCounts: model.LinkClickCounts{Clicks: 3, UniqueUsers: 1, AnonymousIPs: 1}},
+				{Start: "2025-03-04", LinkClickCounts: model.LinkClickCounts{Clicks: 1, UniqueUsers: 0, AnonymousIPs: 1}},
+				{Start: "2025-03-09", LinkClickCounts: model.LinkClickCounts{Clicks: 1, UniqueUsers: 1, AnonymousIPs: 0}},
+				{Start: "2025-03-10", LinkClickCounts: model.LinkClickCounts{Clicks: 1, UniqueUsers: 0, AnonymousIPs: 1}},
+			},
+		},
+		{
+			model.ClickBucketWeek,
+			[]model.LinkClickBucketCounts{
+				{Start: "2025-03-03", LinkClickCounts: model.LinkClickCounts{Clicks: 5, UniqueUsers: 2, AnonymousIPs: 1}},
+				{Start: "2025-03-10", LinkClickCounts: model.LinkClickCounts{Clicks: 1, UniqueUsers: 0, AnonymousIPs: 1}},
+			},
+		},
+	}
+
+	for _, tb := range test_buckets {
+		opts.Bucket = tb.Bucket
+		buckets_sql, err := NewLinkClickBuckets(test_link_id).FromOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := buckets_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buckets []model.LinkClickBucketCounts
+		for rows.Next() {
+			var b model.LinkClickBucketCounts
+			if err := rows.Scan(&b.Start, &b.Clicks, &b.UniqueUsers, &b.AnonymousIPs); err != nil {
+				t.Fatal(err)
+			}
+			buckets = append(buckets, b)
+		}
+		rows.Close()
+
+		if len(buckets) != len(tb.ExpectedBuckets) {
+			t.Fatalf("bucket %s: expected %d buckets, got %d: %+v", tb.Bucket, len(tb.ExpectedBuckets), len(buckets), buckets)
+		}
+		for i := range buckets {
+			if buckets[i] != tb.ExpectedBuckets[i] {
+				t.Fatalf("bucket %s: expected %+v, got %+v", tb.Bucket, tb.ExpectedBuckets[i], buckets[i])
+			}
+		}
+	}
+
+	// Surfaces
+	surfaces_sql, err := NewLinkClickSurfaces(test_link_id).FromOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := surfaces_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	expected_surfaces := []model.LinkClickSurfaceCount{
+		{Surface: model.ClickSurfaceTopLinks, Clicks: 3},
+		{Surface: model.ClickSurfaceCatPage, Clicks: 1},
+		{Surface: model.ClickSurfaceTmap, Clicks: 1},
+		{Surface: model.ClickSurfaceUnknown, Clicks: 1},
+	}
+	var i int
+	for ; rows.Next(); i++ {
+		var s model.LinkClickSurfaceCount
+		if err := rows.Scan(&s.Surface, &s.Clicks); err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(expected_surfaces) || s != expected_surfaces[i] {
+			t.Fatalf("surface %d: expected %+v, got %+v", i, expected_surfaces, s)
+		}
+	}
+	if i != len(expected_surfaces) {
+		t.Fatalf("expected %d surfaces, got %d", len(expected_surfaces), i)
+	}
+
+	// Period: all test clicks are older than a day
+	opts.Period = model.PeriodDay
+	counts_sql, err = NewLinkClickCounts(test_link_id).FromOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err = counts_sql.ValidateAndExecuteRow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := row.Scan(&counts.Clicks, &counts.UniqueUsers, &counts.AnonymousIPs); err != nil {
+		t.Fatal(err)
+	} else if counts.Clicks != 0 {
+		t.Fatalf("expected 0 clicks during period %s, got %d", opts.Period, counts.Clicks)
+	}
+
+	// Invalid options
+	if _, err = NewLinkClickBuckets(test_link_id).FromOptions(
+		&model.LinkClicksOptions{Bucket: "month"},
+	); err == nil {
+		t.Fatal("expected error for invalid bucket")
+	}
+	if _, err = NewLinkClickCounts(test_link_id).FromOptions(
+		&model.LinkClicksOptions{Period: "decade"},
+	); err == nil {
+		t.Fatal("expected error for invalid period")
+	}
+}
+
+func TestNewRawClicks(t *testing.T) {
+	test_link_id := "raw-clicks-test"
+	for i, reason := range []model.ClickDisqualification{
+		model.ClickQualified,
+		model.ClickDuplicate,
+		model.ClickBotRate,
+	} {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Clicks (id, link_id, user_id, ip_addr, timestamp, disqualified_reason)
+			VALUES (?, ?, 'anonymous', '9.9.9.9', ?, ?);`,
+			fmt.Sprintf("%s-%d", test_link_id, i),
+			test_link_id,
+			fmt.Sprintf("2025-04-0%d 00:00:00", i+1),
+			reason,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer TestClient.Exec("DELETE FROM Clicks WHERE link_id = ?;", test_link_id)
+
+	var test_opts = []struct {
+		Opts        *model.RawClicksOptions
+		ExpectedIDs []string
+	}{
+		{
+			&model.RawClicksOptions{LinkID: test_link_id},
+			[]string{test_link_id + "-2", test_link_id + "-1", test_link_id + "-0"},
+		},
+		{
+			&model.RawClicksOptions{LinkID: test_link_id, DisqualifiedOnly: true},
+			[]string{test_link_id + "-2", test_link_id + "-1"},
+		},
+		// past last page
+		{
+			&model.RawClicksOptions{LinkID: test_link_id, Page: 2},
+			nil,
+		},
+	}
+
+	for _, to := range test_opts {
+		raw_clicks_sql, err := NewRawClicks().FromOptions(to.Opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := raw_clicks_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		for rows.Next() {
+			var c model.RawClick
+			if err := rows.Scan(
+				&c.ID,
+				&c.LinkID,
+				&c.UserID,
+				&c.IPAddr,
+				&c.UserAgent,
+				&c.Timestamp,
+				&c.Surface,
+				&c.DisqualifiedReason,
+			); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, c.ID)
+		}
+		rows.Close()
+
+		if !slices.Equal(ids, to.ExpectedIDs) {
+			t.Fatalf("opts %+v: expected %v, got %v", to.Opts, to.ExpectedIDs, ids)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"strings"
+
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Every link in a collection, in order. Unlike top links, NSFW links are
+// not hidden since the collection's owner chose them. Private links are,
+// even from the owner, since collections can be shared.
+type CollectionLinks struct {
+	Query
+}
+
+func NewCollectionLinks(collection_id string) *CollectionLinks {
+	return &CollectionLinks{
+		Query: Query{
+			Text: collection_links_base_query,
+			Args: []any{
+				mutil.EARLIEST_STARRERS_LIMIT,
+				collection_id,
+			},
+		},
+	}
+}
+
+func (cl *CollectionLinks) AsSignedInUser(req_user_id string) *CollectionLinks {
+	auth_replacer := strings.NewReplacer(
+		LINKS_BASE_CTES, LINKS_BASE_CTES+LINKS_AUTH_CTE,
+		LINKS_BASE_FIELDS, LINKS_BASE_FIELDS+LINKS_AUTH_FIELD,
+		LINKS_BASE_JOINS, LINKS_BASE_JOINS+LINKS_AUTH_JOIN,
+	)
+	cl.Text = auth_replacer.Replace(cl.Text)
+
+	// old: [EARLIEST_STARRERS_LIMIT, collection_id]
+	// new: [EARLIEST_STARRERS_LIMIT, req_user_id, collection_id]
+	cl.Args = []any{cl.Args[0], req_user_id, cl.Args[1]}
+
+	return cl
+}
+
+const COLLECTION_LINKS_FIELDS = `,
+	ci.position,
+	ci.note`
+
+const COLLECTION_LINKS_FROM = `
+FROM "Collection Items" ci
+INNER JOIN "Public Links" l ON l.id = ci.link_id`
+
+const COLLECTION_LINKS_WHERE = `
+WHERE ci.collection_id = ?
+ORDER BY ci.position ASC;`
+
+var collection_links_base_query = LINKS_BASE_CTES +
+	LINKS_BASE_FIELDS +
+	COLLECTION_LINKS_FIELDS +
+	COLLECTION_LINKS_FROM +
+	LINKS_BASE_JOINS +
+	LINKS_CONTENT_MATCHES_JOIN +
+	COLLECTION_LINKS_WHERE
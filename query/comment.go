@@ -0,0 +1,77 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+// Top-level comments on a link, oldest first, each followed by its
+// replies. Deleted replies are omitted, as are deleted top-level comments
+// with no remaining replies.
+type Comments struct {
+	*Query
+}
+
+func NewComments(link_id string) *Comments {
+	return &Comments{
+		&Query{
+			Text: COMMENTS,
+			// 1 extra thread is queried to tell if there is a next page
+			Args: []any{link_id, COMMENTS_PAGE_LIMIT + 1},
+		},
+	}
+}
+
+const COMMENTS = `WITH Threads AS (
+	SELECT c.id, c.submit_date
+	FROM Comments c
+	WHERE c.link_id = ?
+	AND c.parent_id IS NULL
+	AND (
+		c.deleted = 0
+		OR EXISTS (
+			SELECT 1 FROM Comments r
+			WHERE r.parent_id = c.id AND r.deleted = 0
+		)
+	)
+	ORDER BY c.submit_date ASC, c.id ASC
+	LIMIT ?
+)
+SELECT
+	c.id,
+	COALESCE(c.parent_id, '') AS parent_id,
+	c.submitted_by,
+	c.text,
+	c.submit_date,
+	c.last_updated,
+	c.deleted
+FROM Comments c
+JOIN Threads t ON t.id = COALESCE(c.parent_id, c.id)
+WHERE c.parent_id IS NULL OR c.deleted = 0
+ORDER BY
+	t.submit_date ASC,
+	t.id ASC,
+	c.parent_id IS NOT NULL,
+	c.submit_date ASC,
+	c.id ASC;`
+
+func (c *Comments) FromOptions(opts *model.CommentsOptions) (*Comments, error) {
+	if opts.Page > 1 {
+		c.page(opts.Page)
+	}
+
+	return c, nil
+}
+
+func (c *Comments) page(page uint) *Comments {
+	c.Text = strings.Replace(
+		c.Text,
+		"LIMIT ?\n)",
+		"LIMIT ? OFFSET ?\n)",
+		1,
+	)
+	c.Args = append(c.Args, (page-1)*COMMENTS_PAGE_LIMIT)
+
+	return c
+}
@@ -4,9 +4,55 @@ const (
 	// Link
 	LINKS_PAGE_LIMIT = 10
 
+	// Hot sort: points / (age in hours + 2) ^ gravity
+	// Higher gravity sinks older links faster
+	// (override with $MODEEP_HOT_GRAVITY)
+	HOT_GRAVITY              = 1.5
+	HOT_AVG_STARS_WEIGHT     = 0.5
+	HOT_CLICK_WEIGHT         = 0.1
+	HOT_SUMMARY_LIKES_WEIGHT = 0.5
+
+	// Rating sort: number of "phantom" stars at the mean of all stars
+	// added to each link's own (see AVERAGE_STARS_CTE)
+	RATING_PRIOR_WEIGHT = 5
+
+	// Related links: up to this many links sharing the most cats and this
+	// many most co-starred links are scanned and then re-ranked
+	// (see handler/util.ScanRelatedLinks())
+	RELATED_LINKS_CANDIDATES_LIMIT = 200
+
+	// Recommended links: highest rated links with any of a user's top
+	// affinity cats are scanned and then re-ranked
+	// (see handler/util.ScanRecommendedLinks())
+	RECOMMENDED_LINKS_CANDIDATES_LIMIT = 200
+
+	// Cat graph
+	// (edges are only between the top nodes)
+	CAT_GRAPH_NODES_LIMIT = 100
+	CAT_GRAPH_EDGES_LIMIT = 500
+
+	// Cat page
+	CAT_DESCRIPTION_REVISIONS_PAGE_LIMIT = 20
+
+	// Cat synonym
+	CAT_SYNONYMS_PAGE_LIMIT = 50
+
+	// Click
+	RAW_CLICKS_PAGE_LIMIT = 100
+
+	// Comment
+	// (top-level comments per page, each with all of its replies)
+	COMMENTS_PAGE_LIMIT = 20
+
 	// Contributor
 	CONTRIBUTORS_PAGE_LIMIT = 10
 
+	// Domain
+	DOMAINS_PAGE_LIMIT = 20
+
+	// Report
+	REPORTS_PAGE_LIMIT = 50
+
 	// Summary
 	SUMMARIES_PAGE_LIMIT = 20
 
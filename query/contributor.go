@@ -24,7 +24,7 @@ func NewTopContributors() *Contributors {
 
 const CONTRIBUTORS_BASE = `SELECT
 count(l.id) as count, l.submitted_by
-FROM Links l
+FROM "Public Links" l
 GROUP BY l.submitted_by
 ORDER BY count DESC, l.submitted_by ASC
 LIMIT ?;`
@@ -39,12 +39,18 @@ func (c *Contributors) FromOptions(opts *model.TopContributorsOptions) (*Contrib
 	if opts.SummaryContains != "" {
 		c = c.whereGlobalSummaryContains(opts.SummaryContains)
 	}
+	if opts.ContentContains != "" {
+		c = c.whereContentContains(opts.ContentContains)
+	}
 	if opts.URLContains != "" {
 		c = c.whereURLContains(opts.URLContains)
 	}
 	if opts.URLLacks != "" {
 		c = c.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		c = c.whereDomain(opts.Domain)
+	}
 	if opts.Period != "" {
 		c = c.duringPeriod(opts.Period)
 	}
@@ -65,8 +71,8 @@ func (c *Contributors) fromCatFilters(cat_filters []string) *Contributors {
 	// Add JOIN
 	c.Text = strings.Replace(
 		c.Text,
-		"FROM Links l",
-		"FROM Links l"+"\n"+CONTRIBUTORS_CAT_FILTERS_JOIN,
+		`FROM "Public Links" l`,
+		`FROM "Public Links" l`+"\n"+CONTRIBUTORS_CAT_FILTERS_JOIN,
 		1,
 	)
 
@@ -185,6 +191,31 @@ func (c *Contributors) whereGlobalSummaryContains(snippet string) *Contributors
 	return c
 }
 
+func (c *Contributors) whereContentContains(snippet string) *Contributors {
+	clause_keyword := "WHERE"
+	if c.hasWhereAfterFrom {
+		clause_keyword = "AND"
+	} else {
+		c.hasWhereAfterFrom = true
+	}
+	c.Text = strings.Replace(
+		c.Text,
+		"GROUP BY l.submitted_by",
+		clause_keyword+" "+CONTRIBUTORS_CONTENT_MATCHES_CONDITION+"\nGROUP BY l.submitted_by",
+		1,
+	)
+
+	// Add arg in 2nd-to-last position before LIMIT
+	last_arg := c.Args[len(c.Args)-1]
+	c.Args = append(c.Args[:len(c.Args)-1], getContentMatchArg(snippet), last_arg)
+
+	return c
+}
+
+const CONTRIBUTORS_CONTENT_MATCHES_CONDITION = `l.id IN (
+	SELECT link_id FROM page_content_fts WHERE page_content_fts MATCH ?
+)`
+
 func (c *Contributors) whereURLContains(snippet string) *Contributors {
 	clause_keyword := "WHERE"
 	if c.hasWhereAfterFrom {
@@ -231,6 +262,27 @@ func (c *Contributors) whereURLLacks(snippet string) *Contributors {
 	return c
 }
 
+func (c *Contributors) whereDomain(domain string) *Contributors {
+	clause_keyword := "WHERE"
+	if c.hasWhereAfterFrom {
+		clause_keyword = "AND"
+	} else {
+		c.hasWhereAfterFrom = true
+	}
+	c.Text = strings.Replace(
+		c.Text,
+		"GROUP BY l.submitted_by",
+		clause_keyword+" l.domain = ?\nGROUP BY l.submitted_by",
+		1,
+	)
+
+	// Add arg in 2nd-to-last position before LIMIT
+	last_arg := c.Args[len(c.Args)-1]
+	c.Args = append(c.Args[:len(c.Args)-1], domain, last_arg)
+
+	return c
+}
+
 func (c *Contributors) duringPeriod(period model.Period) *Contributors {
 	if period == "all" {
 		return c
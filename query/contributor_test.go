@@ -222,6 +222,56 @@ func TestTopContributorsWhereURLContains(t *testing.T) {
 	}
 }
 
+func TestTopContributorsWhereContentContains(t *testing.T) {
+	if _, err := TestClient.Exec(
+		`INSERT INTO page_content_fts (link_id, content)
+		SELECT id, 'notes on the axolotl'
+		FROM Links
+		WHERE id IN ('1', '2');`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM page_content_fts;")
+
+	contributors_sql := NewTopContributors().
+		whereContentContains("Axolotl").
+		duringPeriod("all")
+	rows, err := contributors_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var contributors []model.Contributor
+	for rows.Next() {
+		var c model.Contributor
+		if err := rows.Scan(&c.LinksSubmitted, &c.LoginName); err != nil {
+			t.Fatal(err)
+		}
+		contributors = append(contributors, c)
+	}
+
+	if len(contributors) == 0 {
+		t.Fatal("no contributors")
+	}
+
+	// verify counts
+	for _, c := range contributors {
+		var count int
+		if err := TestClient.QueryRow(`SELECT count(id) as count 
+		FROM LINKS 
+		WHERE id IN ('1', '2')
+		AND submitted_by = ?`,
+			c.LoginName,
+		).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if c.LinksSubmitted != count {
+			t.Fatalf("expected %d, got %d", c.LinksSubmitted, count)
+		}
+	}
+}
+
 func TestTopContributorsDuringPeriod(t *testing.T) {
 	var test_periods = []struct {
 		Period model.Period
@@ -0,0 +1,243 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	"github.com/julianlk522/modeep/model"
+)
+
+// CURSORS
+// Keyset pagination: a cursor holds the ORDER BY key values of the last
+// link on a page (+ its ID as the final tiebreaker) so that the next page
+// starts right after that link even if links have since been added or
+// re-ranked above it. Relevance has no cursor since content_rank depends
+// on the search and isn't returned with links, and neither does hot since
+// scores decay between requests.
+type cursorKey struct {
+	// Used in TopLinks WHERE clauses, where result column aliases like
+	// times_starred would resolve to the (nullable) joined CTE columns
+	Expr  string
+	Desc  bool
+	Value func(l *model.Link) any
+}
+
+// By ORDER BY column (without table alias), with Desc set from the
+// clause each is read from (see getCursorKeysFromOrderByClause)
+var cursor_keys_by_column = map[string]cursorKey{
+	"times_starred": {
+		Expr:  "COALESCE(ts.times_starred, 0)",
+		Value: func(l *model.Link) any { return float64(l.TimesStarred) },
+	},
+	// avg_stars and rating are rounded to 2 places in SQL but scanned
+	// into float32s, so are rounded again here to compare equal
+	"avg_stars": {
+		Expr:  "COALESCE(avs.avg_stars, 0)",
+		Value: func(l *model.Link) any { return math.Round(float64(l.AvgStars)*100) / 100 },
+	},
+	"rating": {
+		Expr:  RATING_FIELD,
+		Value: func(l *model.Link) any { return math.Round(float64(l.Rating)*100) / 100 },
+	},
+	"click_count": {
+		Expr:  "COALESCE(clc.click_count, 0)",
+		Value: func(l *model.Link) any { return float64(l.ClickCount) },
+	},
+	"tag_count": {
+		Expr:  "COALESCE(tc.tag_count, 0)",
+		Value: func(l *model.Link) any { return float64(l.TagCount) },
+	},
+	"summary_count": {
+		Expr:  "COALESCE(sc.summary_count, 0)",
+		Value: func(l *model.Link) any { return float64(l.SummaryCount) },
+	},
+	"submit_date": {
+		Expr:  "l.submit_date",
+		Value: func(l *model.Link) any { return l.SubmitDate },
+	},
+}
+
+// Read off links_order_by_clauses so they can't drift apart
+// (Treasure Map sections sort the same way: see TestTmapOrderByClausesMatchCursorKeys)
+var links_cursor_keys = getLinksCursorKeys()
+
+func getLinksCursorKeys() map[model.SortBy][]cursorKey {
+	keys := map[model.SortBy][]cursorKey{}
+	for sort_by, order_by_clause := range links_order_by_clauses {
+		if sort_by_keys, ok := getCursorKeysFromOrderByClause(order_by_clause); ok {
+			keys[sort_by] = sort_by_keys
+		}
+	}
+
+	return keys
+}
+
+// Keys for each column before the final l.id tiebreaker. Not ok if any
+// column isn't a plain link field (e.g., relevance's content_rank or hot's
+// score).
+func getCursorKeysFromOrderByClause(order_by_clause string) ([]cursorKey, bool) {
+	order_by_clause = strings.TrimSpace(order_by_clause)
+	order_by_clause = strings.TrimSuffix(order_by_clause, ";")
+	order_by_clause = strings.TrimPrefix(order_by_clause, "ORDER BY")
+
+	var keys []cursorKey
+	for term := range strings.SplitSeq(order_by_clause, ",") {
+		fields := strings.Fields(term)
+		if len(fields) != 2 {
+			return nil, false
+		}
+
+		column, direction := fields[0], fields[1]
+		if column == "l.id" {
+			return keys, true
+		}
+		if i := strings.LastIndex(column, "."); i != -1 {
+			column = column[i+1:]
+		}
+
+		key, ok := cursor_keys_by_column[column]
+		if !ok {
+			return nil, false
+		}
+		key.Desc = direction == "DESC"
+		keys = append(keys, key)
+	}
+
+	// no l.id tiebreaker
+	return nil, false
+}
+
+// Relevance and hot pages can only be requested by number
+func SortByIsOffsetOnly(sort_by model.SortBy) bool {
+	if sort_by == "" {
+		sort_by = model.SortByTimesStarred
+	}
+	_, ok := links_cursor_keys[sort_by]
+	return !ok
+}
+
+// Returns "" if sort_by has no cursor (relevance, hot)
+func EncodeLinksCursor(sort_by model.SortBy, link *model.Link) string {
+	if sort_by == "" {
+		sort_by = model.SortByTimesStarred
+	}
+	keys, ok := links_cursor_keys[sort_by]
+	if !ok {
+		return ""
+	}
+
+	cursor := model.LinksCursor{
+		SortBy: sort_by,
+		Keys:   make([]any, len(keys)),
+		ID:     link.ID,
+	}
+	for i, k := range keys {
+		cursor.Keys[i] = k.Value(link)
+	}
+
+	cursor_json, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor_json)
+}
+
+// sort_by must match the one the cursor was issued for, otherwise
+// its keys would be compared against the wrong columns
+func DecodeLinksCursor(token string, sort_by model.SortBy) (*model.LinksCursor, error) {
+	if sort_by == "" {
+		sort_by = model.SortByTimesStarred
+	}
+	keys, ok := links_cursor_keys[sort_by]
+	if !ok {
+		return nil, e.ErrInvalidCursor
+	}
+
+	cursor_json, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, e.ErrInvalidCursor
+	}
+	var cursor model.LinksCursor
+	if err := json.Unmarshal(cursor_json, &cursor); err != nil {
+		return nil, e.ErrInvalidCursor
+	}
+
+	if cursor.SortBy != sort_by ||
+		cursor.ID == "" ||
+		len(cursor.Keys) != len(keys) {
+		return nil, e.ErrInvalidCursor
+	}
+	for i, k := range keys {
+		switch k.Value(&model.Link{}).(type) {
+		case string:
+			if _, ok := cursor.Keys[i].(string); !ok {
+				return nil, e.ErrInvalidCursor
+			}
+		case float64:
+			if _, ok := cursor.Keys[i].(float64); !ok {
+				return nil, e.ErrInvalidCursor
+			}
+		}
+	}
+
+	return &cursor, nil
+}
+
+// For links already sorted in Go (e.g., Treasure Map sections)
+func LinkIsAfterCursor(link *model.Link, cursor *model.LinksCursor) bool {
+	for i, k := range links_cursor_keys[cursor.SortBy] {
+		var cmp int
+		switch v := k.Value(link).(type) {
+		case string:
+			cmp = strings.Compare(v, cursor.Keys[i].(string))
+		case float64:
+			cmp = compareFloats(v, cursor.Keys[i].(float64))
+		}
+
+		if cmp != 0 {
+			return (cmp < 0) == k.Desc
+		}
+	}
+
+	// l.id DESC
+	return link.ID < cursor.ID
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// e.g., for keys (a DESC, b ASC):
+// (a < ? OR (a = ? AND (b > ? OR (b = ? AND l.id < ?))))
+func getCursorCondition(cursor *model.LinksCursor) (string, []any) {
+	keys := links_cursor_keys[cursor.SortBy]
+
+	condition := "l.id < ?"
+	args := []any{cursor.ID}
+	for i := len(keys) - 1; i >= 0; i-- {
+		op := ">"
+		if keys[i].Desc {
+			op = "<"
+		}
+		condition = fmt.Sprintf(
+			"(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s))",
+			keys[i].Expr,
+			op,
+			condition,
+		)
+		args = append([]any{cursor.Keys[i], cursor.Keys[i]}, args...)
+	}
+
+	return condition, args
+}
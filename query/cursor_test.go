@@ -0,0 +1,154 @@
+package query
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestEncodeAndDecodeLinksCursor(t *testing.T) {
+	link := &model.Link{
+		ID:           "test-link",
+		SubmitDate:   "2025-01-01 00:00:00",
+		TimesStarred: 3,
+		AvgStars:     3.67,
+		ClickCount:   10,
+		TagCount:     2,
+		SummaryCount: 1,
+	}
+
+	for sort_by, keys := range links_cursor_keys {
+		token := EncodeLinksCursor(sort_by, link)
+		cursor, err := DecodeLinksCursor(token, sort_by)
+		if err != nil {
+			t.Fatalf("sort_by %s: %s", sort_by, err)
+		} else if cursor.SortBy != sort_by || cursor.ID != link.ID || len(cursor.Keys) != len(keys) {
+			t.Fatalf("sort_by %s: unexpected cursor %+v", sort_by, cursor)
+		}
+		for i, k := range keys {
+			if cursor.Keys[i] != k.Value(link) {
+				t.Fatalf("sort_by %s: expected key %d to be %v, got %v", sort_by, i, k.Value(link), cursor.Keys[i])
+			}
+		}
+
+		// wrong sort_by
+		if _, err := DecodeLinksCursor(token, model.SortByRelevance); err == nil {
+			t.Fatalf("sort_by %s: expected error decoding with relevance", sort_by)
+		}
+	}
+
+	// default sort_by
+	if _, err := DecodeLinksCursor(EncodeLinksCursor("", link), model.SortByTimesStarred); err != nil {
+		t.Fatal(err)
+	}
+	if token := EncodeLinksCursor(model.SortByRelevance, link); token != "" {
+		t.Fatalf("expected no cursor for relevance, got %s", token)
+	}
+
+	var invalid_tokens = []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		// missing ID
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"clicks","k":[1,1,1,1,1]}`)),
+		// wrong number of keys
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"clicks","k":[1],"id":"1"}`)),
+		// wrong key type
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"newest","k":[1,1,1,1,1,1],"id":"1"}`)),
+	}
+	for _, token := range invalid_tokens {
+		sort_by := model.SortByClicks
+		if token == invalid_tokens[len(invalid_tokens)-1] {
+			sort_by = model.SortByNewest
+		}
+		if _, err := DecodeLinksCursor(token, sort_by); err == nil {
+			t.Fatalf("expected error for token %s", token)
+		}
+	}
+}
+
+func TestSortByIsOffsetOnly(t *testing.T) {
+	var test_sorts = []struct {
+		SortBy     model.SortBy
+		OffsetOnly bool
+	}{
+		{"", false},
+		{model.SortByTimesStarred, false},
+		{model.SortByNewest, false},
+		{model.SortByClicks, false},
+		{model.SortByRelevance, true},
+		{model.SortByHot, true},
+	}
+
+	for _, ts := range test_sorts {
+		if got := SortByIsOffsetOnly(ts.SortBy); got != ts.OffsetOnly {
+			t.Fatalf("sort_by %q: expected %t, got %t", ts.SortBy, ts.OffsetOnly, got)
+		}
+	}
+}
+
+func TestLinkIsAfterCursor(t *testing.T) {
+	new_link := func(id string, submit_date string, times_starred int64) *model.Link {
+		return &model.Link{ID: id, SubmitDate: submit_date, TimesStarred: times_starred}
+	}
+	cursor_link := new_link("5", "2025-02-01 00:00:00", 3)
+
+	var test_cases = []struct {
+		SortBy        model.SortBy
+		Link          *model.Link
+		ExpectedAfter bool
+	}{
+		{model.SortByTimesStarred, new_link("9", "2025-01-01 00:00:00", 2), true},
+		{model.SortByTimesStarred, new_link("1", "2025-03-01 00:00:00", 4), false},
+		// tied until submit_date
+		{model.SortByTimesStarred, new_link("9", "2025-01-01 00:00:00", 3), true},
+		// tied until ID
+		{model.SortByTimesStarred, new_link("4", "2025-02-01 00:00:00", 3), true},
+		{model.SortByTimesStarred, new_link("6", "2025-02-01 00:00:00", 3), false},
+		{model.SortByTimesStarred, cursor_link, false},
+		{model.SortByNewest, new_link("1", "2025-01-01 00:00:00", 9), true},
+		{model.SortByOldest, new_link("1", "2025-01-01 00:00:00", 9), false},
+		{model.SortByOldest, new_link("1", "2025-03-01 00:00:00", 0), true},
+	}
+
+	for _, tc := range test_cases {
+		cursor, err := DecodeLinksCursor(EncodeLinksCursor(tc.SortBy, cursor_link), tc.SortBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := LinkIsAfterCursor(tc.Link, cursor); got != tc.ExpectedAfter {
+			t.Fatalf("sort_by %s: expected %t for link %+v, got %t", tc.SortBy, tc.ExpectedAfter, tc.Link, got)
+		}
+	}
+}
+
+func TestTmapOrderByClausesMatchCursorKeys(t *testing.T) {
+	for _, sort_by := range []model.SortBy{model.SortByRelevance, model.SortByHot} {
+		if _, ok := links_cursor_keys[sort_by]; ok {
+			t.Fatalf("expected no cursor keys for sort_by %s", sort_by)
+		}
+	}
+
+	for sort_by, order_by_clause := range tmap_order_by_clauses {
+		tmap_keys, ok := getCursorKeysFromOrderByClause(order_by_clause)
+		links_keys, links_ok := links_cursor_keys[sort_by]
+		if ok != links_ok {
+			t.Fatalf("sort_by %s: expected cursor %t, got %t", sort_by, links_ok, ok)
+		} else if len(tmap_keys) != len(links_keys) {
+			t.Fatalf("sort_by %s: expected %d cursor keys, got %d", sort_by, len(links_keys), len(tmap_keys))
+		}
+		for i := range tmap_keys {
+			if tmap_keys[i].Expr != links_keys[i].Expr || tmap_keys[i].Desc != links_keys[i].Desc {
+				t.Fatalf(
+					"sort_by %s: expected key %d to be %s (desc %t), got %s (desc %t)",
+					sort_by,
+					i,
+					links_keys[i].Expr,
+					links_keys[i].Desc,
+					tmap_keys[i].Expr,
+					tmap_keys[i].Desc,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+// Links with no domain (not yet backfilled, see cmd/backfill_link_domains)
+// are not counted
+type TopDomains struct {
+	*Query
+}
+
+func NewTopDomains() *TopDomains {
+	return (&TopDomains{
+		Query: &Query{
+			Text: DOMAINS_BASE,
+			Args: []any{DOMAINS_PAGE_LIMIT},
+		},
+	})
+}
+
+const DOMAINS_BASE = `SELECT
+count(l.id) as count, l.domain
+FROM "Public Links" l
+WHERE l.domain IS NOT NULL
+GROUP BY l.domain
+ORDER BY count DESC, l.domain ASC
+LIMIT ?;`
+
+func (td *TopDomains) FromOptions(opts *model.TopDomainsOptions) (*TopDomains, error) {
+	if opts.CatFiltersWithSpellingVariants != nil {
+		td = td.fromCatFilters(opts.CatFiltersWithSpellingVariants)
+	}
+	if opts.NeuteredCatFilters != nil {
+		td = td.fromNeuteredCatFilters(opts.NeuteredCatFilters)
+	}
+	if opts.Period != "" {
+		td = td.duringPeriod(opts.Period)
+	}
+	if td.Error != nil {
+		return nil, td.Error
+	}
+	return td, nil
+}
+
+func (td *TopDomains) fromCatFilters(cat_filters []string) *TopDomains {
+	if len(cat_filters) == 0 {
+		return td
+	}
+
+	// Add CTE
+	td.Text = "WITH " + DOMAINS_CAT_FILTERS_CTES + "\n" + td.Text
+
+	// Add JOIN
+	td.Text = strings.Replace(
+		td.Text,
+		`FROM "Public Links" l`,
+		`FROM "Public Links" l`+"\n"+DOMAINS_CAT_FILTERS_JOIN,
+		1,
+	)
+
+	// Build MATCH arg
+	// (spelling variations already added in .FromRequestParams())
+	match_arg := strings.Join(cat_filters, " AND ")
+
+	// Add before LIMIT arg
+	// old: [DOMAINS_PAGE_LIMIT]
+	// new: [match_arg, DOMAINS_PAGE_LIMIT]
+	td.Args = append(td.Args[:len(td.Args)-1], match_arg, DOMAINS_PAGE_LIMIT)
+	return td
+}
+
+const DOMAINS_CAT_FILTERS_CTES = LINKS_CAT_FILTERS_CTE
+const DOMAINS_CAT_FILTERS_JOIN = LINKS_CAT_FILTERS_JOIN
+
+func (td *TopDomains) fromNeuteredCatFilters(neutered_cat_filters []string) *TopDomains {
+	if len(neutered_cat_filters) == 0 {
+		return td
+	}
+
+	// Build IN clause
+	in_clause := "WHERE LOWER(global_cat) IN (?" +
+		strings.Repeat(", ?", len(neutered_cat_filters)-1) +
+		")"
+
+	// Build CTEs
+	neutered_cat_filters_ctes := strings.Replace(
+		DOMAINS_NEUTERED_CAT_FILTERS_CTES,
+		"WHERE LOWER(global_cat) IN (?)",
+		in_clause,
+		1,
+	)
+
+	// Add CTEs
+	// (first determine whether to add the "WITH" or if it was already added
+	// by .fromCatFilters())
+	if strings.HasPrefix(td.Text, "WITH") {
+		td.Text = strings.Replace(
+			td.Text,
+			DOMAINS_CAT_FILTERS_CTES,
+			DOMAINS_CAT_FILTERS_CTES+",\n"+neutered_cat_filters_ctes,
+			1,
+		)
+	} else {
+		td.Text = "WITH " + neutered_cat_filters_ctes + "\n" + td.Text
+	}
+
+	// Add condition
+	td.Text = strings.Replace(
+		td.Text,
+		"GROUP BY l.domain",
+		LINKS_NEUTERED_CATS_AND+"\n"+"GROUP BY l.domain",
+		1,
+	)
+
+	// Insert args in front of LIMIT
+	// old: [(cat_filters,) DOMAINS_PAGE_LIMIT]
+	// new: [(cat_filters,) neutered_cat_filters..., DOMAINS_PAGE_LIMIT]
+	td.Args = td.Args[:len(td.Args)-1]
+	for _, cat := range neutered_cat_filters {
+		td.Args = append(td.Args, strings.ToLower(cat)) // casing matters
+	}
+	td.Args = append(td.Args, DOMAINS_PAGE_LIMIT)
+
+	return td
+}
+
+const DOMAINS_NEUTERED_CAT_FILTERS_CTES = LINKS_NEUTERED_CAT_FILTERS_CTES
+
+func (td *TopDomains) duringPeriod(period model.Period) *TopDomains {
+	if period == "all" {
+		return td
+	}
+
+	period_clause, err := getPeriodClause(period)
+	if err != nil {
+		td.Error = err
+		return td
+	}
+	period_clause = strings.Replace(
+		period_clause,
+		"submit_date",
+		"l.submit_date",
+		1,
+	)
+
+	td.Text = strings.Replace(
+		td.Text,
+		"GROUP BY l.domain",
+		"AND "+period_clause+"\n"+"GROUP BY l.domain",
+		1,
+	)
+
+	return td
+}
@@ -0,0 +1,204 @@
+package query
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+const TEST_DOMAIN_CAT = "domaintestcat"
+
+func insertDomainTestLinks(t *testing.T) func() {
+	test_links := []struct {
+		ID     string
+		URL    string
+		Domain string
+		Cats   string
+	}{
+		{"domain-test-a", "https://gist.github.com/a", "github.com", TEST_DOMAIN_CAT},
+		{"domain-test-b", "https://github.com/b", "github.com", TEST_DOMAIN_CAT + ",domaintestextra"},
+		{"domain-test-c", "https://notgithub.io/c", "notgithub.io", TEST_DOMAIN_CAT},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, domain)
+			VALUES (?, ?, ?, ?, ?, ?);`,
+			tl.ID,
+			tl.URL,
+			TEST_LOGIN_NAME,
+			time.Now().UTC().Format("2006-01-02 15:04:05"),
+			tl.Cats,
+			tl.Domain,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for _, tl := range test_links {
+			TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+		}
+	}
+}
+
+func countRows(t *testing.T, q *Query) int {
+	rows, err := q.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatalf("err: %v, sql text: %s, args: %v", err, q.Text, q.Args)
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		count++
+	}
+	return count
+}
+
+func TestTopDomains(t *testing.T) {
+	defer insertDomainTestLinks(t)()
+
+	var test_opts = []struct {
+		Opts           *model.TopDomainsOptions
+		ExpectedCounts []model.DomainCount
+	}{
+		{
+			&model.TopDomainsOptions{
+				CatFiltersWithSpellingVariants: GetCatsOptionalPluralOrSingularForms([]string{TEST_DOMAIN_CAT}),
+			},
+			[]model.DomainCount{{Domain: "github.com", Count: 2}, {Domain: "notgithub.io", Count: 1}},
+		},
+		{
+			&model.TopDomainsOptions{
+				CatFiltersWithSpellingVariants: GetCatsOptionalPluralOrSingularForms([]string{TEST_DOMAIN_CAT}),
+				NeuteredCatFilters:             []string{"DomainTestExtra"},
+				Period:                         model.PeriodDay,
+			},
+			[]model.DomainCount{{Domain: "github.com", Count: 1}, {Domain: "notgithub.io", Count: 1}},
+		},
+	}
+
+	for _, to := range test_opts {
+		domains_sql, err := NewTopDomains().FromOptions(to.Opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := domains_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var counts []model.DomainCount
+		for rows.Next() {
+			var c model.DomainCount
+			if err := rows.Scan(&c.Count, &c.Domain); err != nil {
+				t.Fatal(err)
+			}
+			counts = append(counts, c)
+		}
+		rows.Close()
+
+		if !slices.Equal(counts, to.ExpectedCounts) {
+			t.Fatalf("opts %+v: expected %v, got %v", to.Opts, to.ExpectedCounts, counts)
+		}
+	}
+
+	if _, err := NewTopDomains().FromOptions(&model.TopDomainsOptions{Period: "decade"}); err == nil {
+		t.Fatal("expected error for invalid period")
+	}
+}
+
+func TestWhereDomain(t *testing.T) {
+	defer insertDomainTestLinks(t)()
+	cat_filters := GetCatsOptionalPluralOrSingularForms([]string{TEST_DOMAIN_CAT})
+
+	// Top links
+	links_sql, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+		CatFiltersWithSpellingVariants: cat_filters,
+		Domain:                         "github.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if count := countRows(t, &links_sql.Query); count != 2 {
+		t.Fatalf("expected 2 top links with domain github.com, got %d", count)
+	}
+
+	// Treasure Map
+	for _, builder := range []TmapLinksQueryBuilder{
+		NewTmapSubmitted(TEST_LOGIN_NAME),
+		NewTmapStarred(TEST_LOGIN_NAME),
+		NewTmapTagged(TEST_LOGIN_NAME),
+	} {
+		tmap_sql, err := builder.FromOptions(&model.TmapOptions{
+			CatFiltersWithSpellingVariants: cat_filters,
+			Domain:                         "notgithub.io",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Only submitted links are inserted
+		expected_count := 0
+		if _, ok := builder.(*TmapSubmitted); ok {
+			expected_count = 1
+		}
+		if count := countRows(t, tmap_sql.Build()); count != expected_count {
+			t.Fatalf("%T: expected %d links with domain notgithub.io, got %d", builder, expected_count, count)
+		}
+	}
+	nsfw_count_sql, err := NewTmapNSFWLinksCount(TEST_LOGIN_NAME).FromOptions(&model.TmapNSFWLinksCountOptions{
+		Domain: "github.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if count := countRows(t, nsfw_count_sql.Query); count != 1 {
+		t.Fatalf("expected 1 NSFW links count row, got %d", count)
+	}
+
+	// Cat counts
+	counts_sql, err := NewTopGlobalCatCounts().FromOptions(&model.TopCatCountsOptions{
+		RawCatFilters:                  []string{TEST_DOMAIN_CAT},
+		CatFiltersWithSpellingVariants: cat_filters,
+		Domain:                         "github.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := counts_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cat_counts []model.CatCount
+	for rows.Next() {
+		var c model.CatCount
+		if err := rows.Scan(&c.Category, &c.Count); err != nil {
+			t.Fatal(err)
+		}
+		cat_counts = append(cat_counts, c)
+	}
+	rows.Close()
+	expected_cat_counts := []model.CatCount{{Category: "domaintestextra", Count: 1}}
+	if !slices.Equal(cat_counts, expected_cat_counts) {
+		t.Fatalf("expected cat counts %v, got %v", expected_cat_counts, cat_counts)
+	}
+
+	// Contributors
+	contributors_sql, err := NewTopContributors().FromOptions(&model.TopContributorsOptions{
+		CatFiltersWithSpellingVariants: cat_filters,
+		Domain:                         "github.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links_submitted int
+	var login_name string
+	if err := TestClient.QueryRow(contributors_sql.Text, contributors_sql.Args...).Scan(
+		&links_submitted,
+		&login_name,
+	); err != nil {
+		t.Fatal(err)
+	} else if login_name != TEST_LOGIN_NAME || links_submitted != 2 {
+		t.Fatalf("expected %s with 2 links, got %s with %d", TEST_LOGIN_NAME, login_name, links_submitted)
+	}
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// HOT SORT
+// Links score points for stars, average stars, clicks and likes on their
+// summaries, decayed by age since submission so that new links with some
+// traction can outrank old favorites.
+// (pow() is registered on each connection in db.LoadSpellfix())
+var Hot_gravity = getHotGravity()
+
+func getHotGravity() float64 {
+	gravity_env := os.Getenv("MODEEP_HOT_GRAVITY")
+	if gravity_env == "" {
+		return HOT_GRAVITY
+	}
+
+	gravity, err := strconv.ParseFloat(gravity_env, 64)
+	if err != nil || gravity < 0 {
+		log.Printf("Invalid $MODEEP_HOT_GRAVITY %q, using default %g", gravity_env, HOT_GRAVITY)
+		return HOT_GRAVITY
+	}
+	return gravity
+}
+
+// Shared by top links and Treasure Maps, which use the same CTE aliases.
+// Age is clamped at 0 so a submit date slightly in the future (e.g., clock
+// skew) can't make the denominator tiny or negative. Both julianday()s are
+// UTC since submit dates are stored in UTC.
+var HOT_SCORE = fmt.Sprintf(`(
+		COALESCE(ts.times_starred, 0)
+		+ %g * COALESCE(avs.avg_stars, 0)
+		+ %g * COALESCE(clc.click_count, 0)
+		+ %g * (
+			SELECT count(*)
+			FROM "Summary Likes" sl
+			INNER JOIN "Visible Summaries" s ON s.id = sl.summary_id
+			WHERE s.link_id = l.id
+		)
+	) / pow(MAX((julianday('now') - julianday(l.submit_date)) * 24, 0.0) + 2, %g)`,
+	HOT_AVG_STARS_WEIGHT,
+	HOT_CLICK_WEIGHT,
+	HOT_SUMMARY_LIKES_WEIGHT,
+	Hot_gravity,
+)
+
+// Same as HOT_SCORE, for links already loaded (e.g., merged Treasure Map
+// sections in feeds)
+func HotScore(times_starred int64, avg_stars float32, click_count int64, summary_likes int, submit_date string) float64 {
+	points := float64(times_starred) +
+		HOT_AVG_STARS_WEIGHT*float64(avg_stars) +
+		HOT_CLICK_WEIGHT*float64(click_count) +
+		HOT_SUMMARY_LIKES_WEIGHT*float64(summary_likes)
+
+	var age_hours float64
+	if submitted, err := mutil.ParseSubmitDate(submit_date); err == nil {
+		age_hours = max(time.Since(submitted).Hours(), 0)
+	}
+
+	return points / math.Pow(age_hours+2, Hot_gravity)
+}
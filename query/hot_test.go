@@ -0,0 +1,78 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHotScore(t *testing.T) {
+	now := time.Now().UTC()
+	hour_ago := now.Add(-time.Hour).Format("2006-01-02 15:04:05")
+	year_ago := now.AddDate(-1, 0, 0).Format("2006-01-02 15:04:05")
+
+	if HotScore(0, 0, 0, 0, hour_ago) != 0 {
+		t.Fatal("expected 0 score without any points")
+	}
+
+	// decay
+	if HotScore(5, 4, 10, 2, year_ago) >= HotScore(5, 4, 10, 2, hour_ago) {
+		t.Fatal("expected older link with same points to score lower")
+	} else if HotScore(50, 5, 100, 10, year_ago) >= HotScore(1, 3, 0, 0, hour_ago) {
+		t.Fatal("expected new link with some points to outrank year-old favorite")
+	}
+
+	// (time passes between calls)
+	rfc3339_score := HotScore(1, 1, 1, 1, now.Add(-time.Hour).Format(time.RFC3339))
+	if math.Abs(rfc3339_score-HotScore(1, 1, 1, 1, hour_ago)) > 1e-6 {
+		t.Fatal("expected same score for RFC 3339 date")
+	}
+
+	// each kind of point counts
+	base := HotScore(1, 1, 1, 1, hour_ago)
+	for _, score := range []float64{
+		HotScore(2, 1, 1, 1, hour_ago),
+		HotScore(1, 2, 1, 1, hour_ago),
+		HotScore(1, 1, 2, 1, hour_ago),
+		HotScore(1, 1, 1, 2, hour_ago),
+	} {
+		if score <= base {
+			t.Fatalf("expected score %f above %f", score, base)
+		}
+	}
+}
+
+func TestHotScoreClampsFutureSubmitDates(t *testing.T) {
+	test_link_id := "hot-score-future-test"
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02 15:04:05")
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+		VALUES (?, 'https://hot-score-future-test.com', 'jlk', ?, 'test');`,
+		test_link_id,
+		tomorrow,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", test_link_id)
+
+	var score float64
+	if err := TestClient.QueryRow(
+		`SELECT `+HOT_SCORE+`
+		FROM Links l
+		LEFT JOIN (SELECT 1 AS times_starred) ts
+		LEFT JOIN (SELECT 0 AS avg_stars) avs
+		LEFT JOIN (SELECT 0 AS click_count) clc
+		WHERE l.id = ?;`,
+		test_link_id,
+	).Scan(&score); err != nil {
+		t.Fatal(err)
+	}
+
+	// same as if just submitted
+	want := HotScore(1, 0, 0, 0, tomorrow)
+	if math.Abs(score-want) > 1e-9 {
+		t.Fatalf("expected score %f, got %f", want, score)
+	} else if want != 1/math.Pow(2, Hot_gravity) {
+		t.Fatalf("expected score %f for future link, got %f", 1/math.Pow(2, Hot_gravity), want)
+	}
+}
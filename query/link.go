@@ -14,6 +14,10 @@ type TopLinks struct {
 	selectedSortBy model.SortBy
 	// for consistent strings replaces
 	hasAndAfterJoins bool
+	selectedPage     uint
+	// so CountNSFWLinks() can count from the start, by rebuilding
+	// without the cursor
+	cursorlessOpts *model.TopLinksOptions
 }
 
 func NewTopLinks() *TopLinks {
@@ -40,6 +44,14 @@ func (tl *TopLinks) FromOptions(opts *model.TopLinksOptions) (*TopLinks, error)
 	if opts.NeuteredCatFilters != nil {
 		tl = tl.fromNeuteredCatFilters(opts.NeuteredCatFilters)
 	}
+	// (after CTE-level filters, before WHERE-level filters, so that
+	// its arg lands in the right place)
+	if opts.ContentContains != "" {
+		tl = tl.whereContentContains(opts.ContentContains)
+		if opts.SortBy == "" {
+			tl = tl.sortBy(model.SortByRelevance)
+		}
+	}
 	if opts.GlobalSummaryContains != "" {
 		tl = tl.whereGlobalSummaryContains(opts.GlobalSummaryContains)
 	}
@@ -49,16 +61,31 @@ func (tl *TopLinks) FromOptions(opts *model.TopLinksOptions) (*TopLinks, error)
 	if opts.URLLacks != "" {
 		tl = tl.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		tl = tl.whereDomain(opts.Domain)
+	}
 	if opts.Period != "" {
 		tl = tl.duringPeriod(opts.Period)
 	}
+	if opts.ExcludeDead {
+		tl = tl.excludeDead()
+	}
+	// (last WHERE-level filter, so that its args are right before LIMIT)
+	if opts.Cursor != nil {
+		tl = tl.afterCursor(opts.Cursor)
+
+		cursorless_opts := *opts
+		cursorless_opts.Cursor = nil
+		cursorless_opts.Page = 1
+		tl.cursorlessOpts = &cursorless_opts
+	}
 	if opts.AsSignedInUser != "" {
 		tl = tl.asSignedInUser(opts.AsSignedInUser)
 	}
 	if opts.IncludeNSFW {
 		tl = tl.includeNSFW()
 	}
-	if opts.Page != 1 {
+	if opts.Page != 1 && opts.Cursor == nil {
 		tl = tl.page(opts.Page)
 	}
 	if tl.Error != nil {
@@ -209,6 +236,41 @@ ExcludedLinksDueToNeutering AS (
 )`
 const LINKS_NEUTERED_CATS_AND = "AND l.id NOT IN ExcludedLinksDueToNeutering"
 
+// Only links whose fetched page content matches snippet. Also makes
+// content_rank and content_snippet available for sorting and display.
+func (tl *TopLinks) whereContentContains(snippet string) *TopLinks {
+	tl.Text = strings.Replace(
+		tl.Text,
+		LINKS_CONTENT_MATCHES_JOIN,
+		LINKS_CONTENT_MATCHES_FILTER_JOIN,
+		1,
+	)
+
+	// insert into args in 2nd-to-last position
+	last_arg := tl.Args[len(tl.Args)-1]
+	tl.Args = tl.Args[:len(tl.Args)-1]
+	tl.Args = append(tl.Args, getContentMatchArg(snippet))
+	tl.Args = append(tl.Args, last_arg)
+
+	return tl
+}
+
+// Placeholder so that content_snippet is always a valid field
+const LINKS_CONTENT_MATCHES_JOIN = `
+LEFT JOIN (
+	SELECT NULL AS link_id, NULL AS content_rank, NULL AS content_snippet
+) cm ON l.id = cm.link_id`
+
+const LINKS_CONTENT_MATCHES_FILTER_JOIN = `
+INNER JOIN (
+	SELECT 
+		link_id, 
+		bm25(page_content_fts) AS content_rank,
+		snippet(page_content_fts, 1, '<mark>', '</mark>', '…', 16) AS content_snippet
+	FROM page_content_fts
+	WHERE page_content_fts MATCH ?
+) cm ON l.id = cm.link_id`
+
 func (tl *TopLinks) whereGlobalSummaryContains(snippet string) *TopLinks {
 	selected_order_by_clause := links_order_by_clauses[tl.selectedSortBy]
 	tl.Text = strings.Replace(
@@ -275,6 +337,28 @@ func (tl *TopLinks) whereURLLacks(snippet string) *TopLinks {
 	return tl
 }
 
+// domain is the registrable domain (eTLD+1) stored in Links.domain,
+// e.g., "github.com" (unlike url_contains=github, doesn't match
+// "notgithub.io")
+func (tl *TopLinks) whereDomain(domain string) *TopLinks {
+	selected_order_by_clause := links_order_by_clauses[tl.selectedSortBy]
+	tl.Text = strings.Replace(
+		tl.Text,
+		selected_order_by_clause,
+		"\n"+"AND l.domain = ?"+selected_order_by_clause,
+		1,
+	)
+	tl.hasAndAfterJoins = true
+
+	// insert into args in 2nd-to-last position
+	last_arg := tl.Args[len(tl.Args)-1]
+	tl.Args = tl.Args[:len(tl.Args)-1]
+	tl.Args = append(tl.Args, domain)
+	tl.Args = append(tl.Args, last_arg)
+
+	return tl
+}
+
 func (tl *TopLinks) duringPeriod(period model.Period) *TopLinks {
 	if period == "all" {
 		return tl
@@ -300,6 +384,67 @@ func (tl *TopLinks) duringPeriod(period model.Period) *TopLinks {
 	return tl
 }
 
+func (tl *TopLinks) excludeDead() *TopLinks {
+	selected_order_by_clause := links_order_by_clauses[tl.selectedSortBy]
+	tl.Text = strings.Replace(
+		tl.Text,
+		selected_order_by_clause,
+		// As long as this is called before .includeNSFW() and the
+		// LINKS_NO_NSFW_CATS_WHERE clause is still there, this should be an
+		// AND.
+		"\n"+LINKS_EXCLUDE_DEAD_AND+selected_order_by_clause,
+		1,
+	)
+	tl.hasAndAfterJoins = true
+
+	return tl
+}
+
+const LINKS_EXCLUDE_DEAD_AND = "AND COALESCE(l.health, '') != 'dead'"
+
+func (tl *TopLinks) afterCursor(cursor *model.LinksCursor) *TopLinks {
+	if cursor.SortBy != tl.selectedSortBy {
+		tl.Error = e.ErrInvalidCursor
+		return tl
+	}
+	condition, cursor_args := getCursorCondition(cursor)
+
+	selected_order_by_clause := links_order_by_clauses[tl.selectedSortBy]
+	tl.Text = strings.Replace(
+		tl.Text,
+		selected_order_by_clause,
+		// As long as this is called before .includeNSFW() and the
+		// LINKS_NO_NSFW_CATS_WHERE clause is still there, this should be an
+		// AND.
+		"\n"+"AND "+condition+selected_order_by_clause,
+		1,
+	)
+	tl.hasAndAfterJoins = true
+
+	// insert into args before last (LIMIT)
+	last_arg := tl.Args[len(tl.Args)-1]
+	tl.Args = tl.Args[:len(tl.Args)-1]
+	tl.Args = append(tl.Args, cursor_args...)
+	tl.Args = append(tl.Args, last_arg)
+
+	return tl
+}
+
+// Cursor for the page after the one ending with last_link, or "" if
+// that was the last page
+func (tl *TopLinks) NextCursor(last_link *model.Link, pages int) string {
+	// With a cursor, pages are only counted from that cursor
+	if int(max(tl.selectedPage, 1)) >= pages {
+		return ""
+	}
+	return EncodeLinksCursor(tl.selectedSortBy, last_link)
+}
+
+// If so, NextCursor is always "" and pages must be requested by number
+func (tl *TopLinks) OffsetOnly() bool {
+	return SortByIsOffsetOnly(tl.selectedSortBy)
+}
+
 func (tl *TopLinks) asSignedInUser(req_user_id string) *TopLinks {
 	auth_replacer := strings.NewReplacer(
 		LINKS_BASE_CTES, LINKS_BASE_CTES+LINKS_AUTH_CTE,
@@ -366,6 +511,7 @@ func (tl *TopLinks) page(page uint) *TopLinks {
 	if page < 1 {
 		return tl
 	}
+	tl.selectedPage = page
 
 	// Pop limit arg and replace with limit + 1
 	tl.Args = tl.Args[:len(tl.Args)-1]
@@ -390,6 +536,16 @@ func (tl *TopLinks) CountNSFWLinks() *TopLinks {
 	count_select := `
 	SELECT count(l.id)`
 
+	// Count all NSFW links, not just those after the cursor
+	if tl.cursorlessOpts != nil {
+		cursorless_tl, err := NewTopLinks().FromOptions(tl.cursorlessOpts)
+		if err != nil {
+			tl.Error = err
+			return tl
+		}
+		tl = cursorless_tl
+	}
+
 	// Replace either base or base + auth fields
 	// (if first works second will be no-op)
 	tl.Text = strings.Replace(
@@ -485,16 +641,47 @@ func (tl *TopLinks) CountNSFWLinks() *TopLinks {
 	return tl
 }
 
-const LINKS_BASE_CTES = `WITH TimesStarred AS (
+// Shared by top links, single links and Treasure Maps.
+// rating is a Bayesian average: RATING_PRIOR_WEIGHT phantom stars at
+// the mean of all stars are added to a link's own, so one 3-star rating
+// doesn't outrank dozens averaging 2.9.
+var AVERAGE_STARS_CTE = fmt.Sprintf(`AverageStars AS (
+	SELECT 
+		link_id, 
+		ROUND(AVG(num_stars), 2) AS avg_stars,
+		ROUND(
+			(SUM(num_stars) + %[1]d * %[2]s)
+			/ (COUNT(*) + %[1]d),
+			2
+		) AS rating
+	FROM Stars
+	GROUP BY link_id
+)`,
+	RATING_PRIOR_WEIGHT,
+	ALL_STARS_MEAN,
+)
+
+const ALL_STARS_MEAN = `(SELECT AVG(num_stars) FROM Stars)`
+
+// Links without stars have only the phantom ones, so are rated at the
+// mean of all stars (or 0 if there are none yet)
+var RATING_FIELD = `COALESCE(avs.rating, ROUND(COALESCE(` + ALL_STARS_MEAN + `, 0), 2))`
+
+// Shared by top links, single links and Treasure Maps.
+// Deleted comments are not counted.
+const COMMENT_COUNT_CTE = `CommentCount AS (
+    SELECT link_id, COUNT(*) AS comment_count
+    FROM Comments
+    WHERE deleted = 0
+    GROUP BY link_id
+)`
+
+var LINKS_BASE_CTES = `WITH TimesStarred AS (
     SELECT link_id, COUNT(*) AS times_starred 
     FROM Stars
     GROUP BY link_id
 ),
-AverageStars AS (
-	SELECT link_id, ROUND(AVG(num_stars), 2) AS avg_stars
-	FROM Stars
-	GROUP BY link_id
-),
+` + AVERAGE_STARS_CTE + `,
 EarliestStarrers AS (
     SELECT 
         link_id,
@@ -514,18 +701,20 @@ EarliestStarrers AS (
 ClickCount AS (
 	SELECT link_id, count(*) AS click_count
 	FROM Clicks
+	WHERE disqualified_reason = ''
 	GROUP BY link_id
 ),
 TagCount AS (
     SELECT link_id, COUNT(*) AS tag_count
-    FROM Tags
+    FROM "Visible Tags"
     GROUP BY link_id
 ),
 SummaryCount AS (
     SELECT link_id, COUNT(*) AS summary_count
-    FROM Summaries
+    FROM "Visible Summaries"
     GROUP BY link_id
-)`
+),
+` + COMMENT_COUNT_CTE
 
 var LINKS_BASE_FIELDS = fmt.Sprintf(` 
 SELECT 
@@ -538,17 +727,24 @@ SELECT
     COALESCE(sc.summary_count, 0) AS summary_count,
     COALESCE(ts.times_starred, 0) AS times_starred,
 	COALESCE(avs.avg_stars, 0) AS avg_stars,
+	%s AS rating,
 	COALESCE(es.earliest_starrers, '') AS earliest_starrers,
 	COALESCE(clc.click_count, 0) AS click_count, 
     COALESCE(tc.tag_count, 0) AS tag_count,
+    COALESCE(cmc.comment_count, 0) AS comment_count,
     COALESCE(l.img_file, '') AS img_file,
+    COALESCE(l.health, '') AS health,
+    COALESCE(l.last_checked_at, '') AS last_checked_at,
+    COALESCE(cm.content_snippet, '') AS content_snippet,
 	(COUNT(*) OVER() + %d - 1) / %d AS pages`,
+	RATING_FIELD,
 	LINKS_PAGE_LIMIT,
 	LINKS_PAGE_LIMIT)
 
+// Private links are only visible on their submitter's own Treasure Map
 const LINKS_FROM = `
 FROM
-	Links l`
+	"Public Links" l`
 
 const LINKS_BASE_JOINS = `
 LEFT JOIN TimesStarred ts ON l.id = ts.link_id
@@ -556,7 +752,8 @@ LEFT JOIN AverageStars avs ON l.id = avs.link_id
 LEFT JOIN EarliestStarrers es ON l.id = es.link_id
 LEFT JOIN ClickCount clc ON l.id = clc.link_id
 LEFT JOIN TagCount tc ON l.id = tc.link_id
-LEFT JOIN SummaryCount sc ON l.id = sc.link_id`
+LEFT JOIN SummaryCount sc ON l.id = sc.link_id
+LEFT JOIN CommentCount cmc ON l.id = cmc.link_id`
 
 const LINKS_NO_NSFW_CATS_WHERE = `
 WHERE l.id NOT IN (
@@ -569,6 +766,9 @@ var links_order_by_clauses = map[model.SortBy]string{
 	model.SortByNewest:       LINKS_ORDER_BY_NEWEST,
 	model.SortByOldest:       LINKS_ORDER_BY_OLDEST,
 	model.SortByClicks:       LINKS_ORDER_BY_CLICKS,
+	model.SortByRelevance:    LINKS_ORDER_BY_RELEVANCE,
+	model.SortByHot:          LINKS_ORDER_BY_HOT,
+	model.SortByRating:       LINKS_ORDER_BY_RATING,
 }
 
 const LINKS_ORDER_BY_TIMES_STARRED = ` 
@@ -591,6 +791,17 @@ ORDER BY
 	submit_date DESC,
 	l.id DESC`
 
+const LINKS_ORDER_BY_RATING = `
+ORDER BY 
+	rating DESC, 
+	times_starred DESC,
+	avg_stars DESC,
+	click_count DESC,
+	tag_count DESC,
+	summary_count DESC, 
+	submit_date DESC,
+	l.id DESC`
+
 const LINKS_ORDER_BY_NEWEST = `
 ORDER BY 
 	submit_date DESC, 
@@ -620,6 +831,29 @@ ORDER BY
 	summary_count DESC, 
 	l.id DESC`
 
+// bm25() is lower for better matches; without a content filter
+// content_rank is NULL for all links so this falls back to times starred
+const LINKS_ORDER_BY_RELEVANCE = `
+ORDER BY 
+	cm.content_rank ASC, 
+	times_starred DESC, 
+	avg_stars DESC,
+	click_count DESC, 
+	tag_count DESC, 
+	summary_count DESC, 
+	l.id DESC`
+
+var LINKS_ORDER_BY_HOT = `
+ORDER BY 
+	` + HOT_SCORE + ` DESC, 
+	times_starred DESC, 
+	avg_stars DESC,
+	click_count DESC, 
+	tag_count DESC, 
+	summary_count DESC, 
+	submit_date DESC,
+	l.id DESC`
+
 const LINKS_LIMIT = `
 LIMIT ?;`
 
@@ -627,6 +861,7 @@ var links_base_query = LINKS_BASE_CTES +
 	LINKS_BASE_FIELDS +
 	LINKS_FROM +
 	LINKS_BASE_JOINS +
+	LINKS_CONTENT_MATCHES_JOIN +
 	LINKS_NO_NSFW_CATS_WHERE +
 	LINKS_ORDER_BY_TIMES_STARRED +
 	LINKS_LIMIT
@@ -651,7 +886,7 @@ func NewSingleLink(link_id string) *SingleLink {
 	}
 }
 
-const SINGLE_LINK_BASE_CTES = `WITH 
+var SINGLE_LINK_BASE_CTES = `WITH 
 Base AS (
     SELECT
         id as link_id,
@@ -660,7 +895,9 @@ Base AS (
         submit_date as sd,
         COALESCE(global_cats, "") as cats,
         COALESCE(global_summary, "") as summary,
-        COALESCE(img_file, "") as img_file
+        COALESCE(img_file, "") as img_file,
+        COALESCE(health, "") as health,
+        COALESCE(last_checked_at, "") as last_checked_at
     FROM Links
     WHERE id = ?
 ),
@@ -668,7 +905,7 @@ SummaryCount AS (
     SELECT 
         link_id, 
         COUNT(*) AS summary_count
-    FROM Summaries
+    FROM "Visible Summaries"
     GROUP BY link_id
 ),
 TimesStarred AS (
@@ -678,13 +915,7 @@ TimesStarred AS (
     FROM Stars
     GROUP BY link_id
 ),
-AverageStars AS (
-	SELECT 
-		link_id, 
-		ROUND(AVG(num_stars), 2) AS avg_stars
-	FROM Stars
-	GROUP BY link_id
-),
+` + AVERAGE_STARS_CTE + `,
 EarliestStarrers AS (
     SELECT 
         link_id,
@@ -706,17 +937,19 @@ ClickCount AS (
         link_id, 
         COUNT(*) AS click_count
     FROM Clicks
+    WHERE disqualified_reason = ''
     GROUP BY link_id
 ),
 TagCount AS (
     SELECT 
         link_id, 
         COUNT(*) as tag_count
-    FROM Tags
+    FROM "Visible Tags"
     GROUP BY link_id
-)`
+),
+` + COMMENT_COUNT_CTE
 
-const SINGLE_LINK_BASE_FIELDS = `
+var SINGLE_LINK_BASE_FIELDS = `
 SELECT
     b.link_id,
     b.url,
@@ -727,10 +960,14 @@ SELECT
     COALESCE(sc.summary_count, 0) as summary_count,
     COALESCE(ts.times_starred, 0) as times_starred,
     COALESCE(avs.avg_stars, 0) as avg_stars,
+    ` + RATING_FIELD + ` as rating,
     COALESCE(es.earliest_starrers, "") as earliest_starrers,
     COALESCE(ckc.click_count, 0) as click_count,
     COALESCE(tc.tag_count, 0) as tag_count,
-    b.img_file`
+    COALESCE(cmc.comment_count, 0) as comment_count,
+    b.img_file,
+    b.health,
+    b.last_checked_at`
 
 const SINGLE_LINK_FROM = `
 FROM Base b`
@@ -741,7 +978,8 @@ LEFT JOIN TimesStarred ts ON ts.link_id = b.link_id
 LEFT JOIN AverageStars avs ON avs.link_id = b.link_id
 LEFT JOIN EarliestStarrers es ON es.link_id = b.link_id
 LEFT JOIN ClickCount ckc ON ckc.link_id = b.link_id
-LEFT JOIN TagCount tc ON tc.link_id = b.link_id`
+LEFT JOIN TagCount tc ON tc.link_id = b.link_id
+LEFT JOIN CommentCount cmc ON cmc.link_id = b.link_id`
 
 func (sl *SingleLink) AsSignedInUser(user_id string) *SingleLink {
 	sl.Text = strings.Replace(
@@ -2,6 +2,9 @@ package query
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -39,10 +42,15 @@ func TestNewTopLinks(t *testing.T) {
 		{"summary_count"},
 		{"times_starred"},
 		{"avg_stars"},
+		{"rating"},
 		{"earliest_starrers"},
 		{"click_count"},
 		{"tag_count"},
+		{"comment_count"},
 		{"img_file"},
+		{"health"},
+		{"last_checked_at"},
+		{"content_snippet"},
 		{"pages"},
 	}
 
@@ -120,10 +128,15 @@ func TestTopLinksFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 		); err != nil {
 			t.Fatal(err)
@@ -169,10 +182,15 @@ func TestTopLinksFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 		); err != nil {
 			t.Fatal(err)
@@ -217,10 +235,15 @@ func TestTopLinksWhereGlobalSummaryContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 		); err != nil {
 			t.Fatal(err)
@@ -269,10 +292,15 @@ func TestTopLinksWhereGlobalSummaryContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 			&l.StarsAssigned,
 		); err != nil {
@@ -316,10 +344,15 @@ func TestTopLinksWhereURLContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 		); err != nil {
 			t.Fatal(err)
@@ -364,10 +397,15 @@ func TestTopLinksWhereURLContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 			&l.StarsAssigned,
 		); err != nil {
@@ -412,10 +450,15 @@ func TestTopLinksWhereURLLacks(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 		); err != nil {
 			t.Fatal(err)
@@ -459,10 +502,15 @@ func TestTopLinksWhereURLLacks(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 			&l.StarsAssigned,
 		); err != nil {
@@ -541,6 +589,8 @@ func TestTopLinksSortBy(t *testing.T) {
 		{"avg_stars", true},
 		{"oldest", true},
 		{"clicks", true},
+		{"hot", true},
+		{"rating", true},
 		{"random", false},
 		{"invalid", false},
 	}
@@ -579,10 +629,15 @@ func TestTopLinksSortBy(t *testing.T) {
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
+				&l.CommentCount,
 				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
 				&pages,
 			)
 			if err != nil {
@@ -643,6 +698,15 @@ func TestTopLinksSortBy(t *testing.T) {
 					last_date = sd
 				}
 			}
+		case model.SortByRating:
+			var last_rating float32 = 999
+			for _, link := range links {
+				if link.Rating > last_rating {
+					t.Fatalf("link rating %f above previous min %f", link.Rating, last_rating)
+				} else if link.Rating < last_rating {
+					last_rating = link.Rating
+				}
+			}
 		case model.SortByClicks:
 			var last_click_count int64 = 999 // arbitrary high number
 			for _, link := range links {
@@ -657,6 +721,221 @@ func TestTopLinksSortBy(t *testing.T) {
 	}
 }
 
+func TestTopLinksSortByHot(t *testing.T) {
+	test_links := []struct {
+		ID         string
+		SubmitDate string
+		NumStars   int
+	}{
+		{"hot-sort-test-old", "2020-01-01 00:00:00", 3},
+		{"hot-sort-test-new", time.Now().Add(-time.Hour).Format("2006-01-02 15:04:05"), 1},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, ?, 'test');`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			TEST_LOGIN_NAME,
+			tl.SubmitDate,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+
+		for i := range tl.NumStars {
+			if _, err := TestClient.Exec(
+				`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp)
+				VALUES (?, ?, ?, 3, ?);`,
+				fmt.Sprintf("%s-%d", tl.ID, i),
+				tl.ID,
+				fmt.Sprintf("hot-sort-test-user-%d", i),
+				tl.SubmitDate,
+			); err != nil {
+				t.Fatal(err)
+			}
+		}
+		defer TestClient.Exec("DELETE FROM Stars WHERE link_id = ?;", tl.ID)
+	}
+
+	var test_sorts = []struct {
+		SortBy      model.SortBy
+		ExpectedIDs []string
+	}{
+		{model.SortByTimesStarred, []string{"hot-sort-test-old", "hot-sort-test-new"}},
+		{model.SortByHot, []string{"hot-sort-test-new", "hot-sort-test-old"}},
+	}
+	for _, ts := range test_sorts {
+		links_sql, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+			SortBy:      ts.SortBy,
+			URLContains: "hot-sort-test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := links_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		for rows.Next() {
+			l := model.Link{}
+			var pages int
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+			); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, l.ID)
+		}
+		rows.Close()
+
+		if !slices.Equal(ids, ts.ExpectedIDs) {
+			t.Fatalf("sort_by %s: expected %v, got %v", ts.SortBy, ts.ExpectedIDs, ids)
+		}
+	}
+}
+
+func TestTopLinksSortByRating(t *testing.T) {
+	// 1 rating of 3 vs. 20 averaging 2.95 vs. none (rated at the mean)
+	test_links := []struct {
+		ID       string
+		NumStars []int
+	}{
+		{"rating-sort-test-few", []int{3}},
+		{"rating-sort-test-many", append(slices.Repeat([]int{3}, 19), 2)},
+		{"rating-sort-test-none", nil},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, '2025-01-01 00:00:00', 'test');`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			TEST_LOGIN_NAME,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+
+		for i, num_stars := range tl.NumStars {
+			if _, err := TestClient.Exec(
+				`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp)
+				VALUES (?, ?, ?, ?, '2025-01-01 00:00:00');`,
+				fmt.Sprintf("%s-%d", tl.ID, i),
+				tl.ID,
+				fmt.Sprintf("rating-sort-test-user-%d", i),
+				num_stars,
+			); err != nil {
+				t.Fatal(err)
+			}
+		}
+		defer TestClient.Exec("DELETE FROM Stars WHERE link_id = ?;", tl.ID)
+	}
+
+	var mean_stars float64
+	if err := TestClient.QueryRow(
+		"SELECT AVG(num_stars) FROM Stars;",
+	).Scan(&mean_stars); err != nil {
+		t.Fatal(err)
+	}
+	expected_ratings := make(map[string]float64)
+	for _, tl := range test_links {
+		var sum int
+		for _, n := range tl.NumStars {
+			sum += n
+		}
+		expected_ratings[tl.ID] = (float64(sum) + RATING_PRIOR_WEIGHT*mean_stars) /
+			float64(len(tl.NumStars)+RATING_PRIOR_WEIGHT)
+	}
+
+	for _, sort_by := range []model.SortBy{model.SortByAverageStars, model.SortByRating} {
+		links_sql, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+			SortBy:      sort_by,
+			URLContains: "rating-sort-test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := links_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var links []model.Link
+		for rows.Next() {
+			l := model.Link{}
+			var pages int
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+			); err != nil {
+				t.Fatal(err)
+			}
+			links = append(links, l)
+		}
+		rows.Close()
+
+		if len(links) != len(test_links) {
+			t.Fatalf("expected %d links, got %d", len(test_links), len(links))
+		}
+		for _, l := range links {
+			if math.Abs(float64(l.Rating)-expected_ratings[l.ID]) > 0.006 {
+				t.Fatalf("expected rating %f for %s, got %f", expected_ratings[l.ID], l.ID, l.Rating)
+			}
+		}
+
+		switch sort_by {
+		case model.SortByAverageStars:
+			if links[0].ID != "rating-sort-test-few" {
+				t.Fatalf("expected link with single 3-star rating first by avg_stars, got %s", links[0].ID)
+			}
+		case model.SortByRating:
+			for i := 1; i < len(links); i++ {
+				if expected_ratings[links[i-1].ID] < expected_ratings[links[i].ID] {
+					t.Fatalf("expected links sorted by rating, got %+v", links)
+				}
+			}
+		}
+	}
+}
+
 func TestTopLinksAsSignedInUser(t *testing.T) {
 	links_sql := NewTopLinks().asSignedInUser(TEST_USER_ID)
 	rows, err := links_sql.ValidateAndExecuteRows()
@@ -744,10 +1023,15 @@ func TestTopLinksIncludeNSFW(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 			&l.StarsAssigned,
 		); err != nil {
@@ -784,10 +1068,15 @@ func TestTopLinksIncludeNSFW(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&pages,
 			&l.StarsAssigned,
 		); err != nil {
@@ -798,6 +1087,185 @@ func TestTopLinksIncludeNSFW(t *testing.T) {
 	}
 }
 
+func TestTopLinksExcludeDead(t *testing.T) {
+	var test_link_id, test_link_url string
+	if err := TestClient.QueryRow(
+		"SELECT id, url FROM Links WHERE id = '1';",
+	).Scan(&test_link_id, &test_link_url); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := TestClient.Exec(
+		"UPDATE Links SET health = 'dead' WHERE id = ?;",
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec(
+		"UPDATE Links SET health = NULL WHERE id = ?;",
+		test_link_id,
+	)
+
+	var test_queries = []struct {
+		Query         *TopLinks
+		ExpectPresent bool
+	}{
+		{NewTopLinks().whereURLContains(test_link_url).includeNSFW(), true},
+		{NewTopLinks().whereURLContains(test_link_url).excludeDead().includeNSFW(), false},
+		{NewTopLinks().
+			whereURLContains(test_link_url).
+			duringPeriod("all").
+			excludeDead().
+			asSignedInUser(TEST_USER_ID).
+			sortBy("newest").
+			includeNSFW(), false},
+	}
+
+	for _, tq := range test_queries {
+		rows, err := tq.Query.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found bool
+		for rows.Next() {
+			// only ID is needed
+			var id string
+			cols, err := rows.Columns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest := []any{&id}
+			for range cols[1:] {
+				var v any
+				dest = append(dest, &v)
+			}
+
+			if err := rows.Scan(dest...); err != nil {
+				t.Fatal(err)
+			} else if id == test_link_id {
+				found = true
+			}
+		}
+		rows.Close()
+
+		if found != tq.ExpectPresent {
+			t.Fatalf(
+				"expected link %s present: %t, got %t (sql: %s)",
+				test_link_id,
+				tq.ExpectPresent,
+				found,
+				tq.Query.Text,
+			)
+		}
+	}
+}
+
+func TestTopLinksContentContains(t *testing.T) {
+	// more matches = better bm25 rank
+	var test_contents = map[string]string{
+		"1": "quokka habitats and the quokka diet of rottnest island quokka",
+		"2": "a passing mention of a quokka among other animals",
+	}
+	for link_id, content := range test_contents {
+		if _, err := TestClient.Exec(
+			"INSERT INTO page_content_fts (link_id, content) VALUES (?, ?);",
+			link_id,
+			content,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer TestClient.Exec("DELETE FROM page_content_fts WHERE link_id IN ('1', '2');")
+
+	var test_options = []*model.TopLinksOptions{
+		{ContentContains: "quokka", IncludeNSFW: true},
+		{
+			ContentContains: "quokka",
+			URLLacks:        "nonexistent-snippet",
+			Period:          "all",
+			AsSignedInUser:  TEST_USER_ID,
+			IncludeNSFW:     true,
+			Page:            1,
+		},
+	}
+
+	for _, opts := range test_options {
+		tl, err := NewTopLinks().FromOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := tl.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids, snippets []string
+		for rows.Next() {
+			var id string
+			cols, err := rows.Columns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest := []any{&id}
+			var snippet string
+			for _, col := range cols[1:] {
+				if col == "content_snippet" {
+					dest = append(dest, &snippet)
+				} else {
+					var v any
+					dest = append(dest, &v)
+				}
+			}
+
+			if err := rows.Scan(dest...); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+			snippets = append(snippets, snippet)
+		}
+		rows.Close()
+
+		if len(ids) != 2 {
+			t.Fatalf("expected 2 links matching content, got %d: %v", len(ids), ids)
+		} else if ids[0] != "1" || ids[1] != "2" {
+			t.Fatalf("expected links ordered by relevance [1 2], got %v", ids)
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(snippet, "<mark>quokka</mark>") {
+				t.Fatalf("expected highlighted snippet, got %q", snippet)
+			}
+		}
+	}
+
+	// Filter-only: explicit sort overrides relevance
+	tl, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+		ContentContains: "passing mention",
+		SortBy:          model.SortByNewest,
+		IncludeNSFW:     true,
+		Page:            1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(tl.Text, LINKS_ORDER_BY_NEWEST) {
+		t.Fatalf("expected newest sort to be kept, got: %s", tl.Text)
+	}
+	rows, err := tl.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 link matching 'passing mention', got %d", count)
+	}
+}
+
 func TestTopLinksPage(t *testing.T) {
 	var test_cases = []struct {
 		Page         uint
@@ -928,3 +1396,211 @@ func TestTopLinksCountNSFWLinks(t *testing.T) {
 		)
 	}
 }
+
+func TestTopLinksAfterCursor(t *testing.T) {
+	scan_links := func(links_sql *TopLinks) []model.Link {
+		rows, err := links_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		var links []model.Link
+		var pages int
+		for rows.Next() {
+			l := model.Link{}
+			if err := rows.Scan(
+				&l.ID,
+				&l.URL,
+				&l.SubmittedBy,
+				&l.SubmitDate,
+				&l.Cats,
+				&l.Summary,
+				&l.SummaryCount,
+				&l.TimesStarred,
+				&l.AvgStars,
+				&l.Rating,
+				&l.EarliestStarrers,
+				&l.ClickCount,
+				&l.TagCount,
+				&l.CommentCount,
+				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
+				&pages,
+			); err != nil {
+				t.Fatal(err)
+			}
+			links = append(links, l)
+		}
+		return links
+	}
+
+	for sort_by := range links_cursor_keys {
+		for _, include_nsfw := range []bool{false, true} {
+			opts := &model.TopLinksOptions{
+				SortBy:      sort_by,
+				IncludeNSFW: include_nsfw,
+			}
+			links_sql, err := NewTopLinks().FromOptions(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			links := scan_links(links_sql)
+			if len(links) < 2 {
+				t.Fatalf("sort_by %s: expected 2+ links to test cursors, got %d", sort_by, len(links))
+			}
+
+			// the link after each cursor should be the next one
+			// from the first page
+			for i := 0; i < len(links)-1; i++ {
+				cursor, err := DecodeLinksCursor(
+					EncodeLinksCursor(sort_by, &links[i]),
+					sort_by,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.Cursor = cursor
+				links_sql, err := NewTopLinks().FromOptions(opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				links_after := scan_links(links_sql)
+				if len(links_after) == 0 || links_after[0].ID != links[i+1].ID {
+					t.Fatalf(
+						"sort_by %s: expected link %s after cursor for link %s, got %+v",
+						sort_by,
+						links[i+1].ID,
+						links[i].ID,
+						links_after,
+					)
+				}
+			}
+
+			// NSFW links are counted from the start regardless of cursor
+			opts.Cursor = nil
+			links_sql, err = NewTopLinks().FromOptions(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want_nsfw_links int
+			row, err := links_sql.CountNSFWLinks().ValidateAndExecuteRow()
+			if err != nil {
+				t.Fatal(err)
+			} else if err := row.Scan(&want_nsfw_links); err != nil {
+				t.Fatal(err)
+			}
+
+			opts.Cursor, _ = DecodeLinksCursor(
+				EncodeLinksCursor(sort_by, &links[len(links)-1]),
+				sort_by,
+			)
+			links_sql, err = NewTopLinks().FromOptions(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var nsfw_links int
+			row, err = links_sql.CountNSFWLinks().ValidateAndExecuteRow()
+			if err != nil {
+				t.Fatalf("err: %v, sql text was %s, args were %v", err, links_sql.Text, links_sql.Args)
+			} else if err := row.Scan(&nsfw_links); err != nil {
+				t.Fatal(err)
+			} else if nsfw_links != want_nsfw_links {
+				t.Fatalf("sort_by %s: expected %d NSFW links with cursor, got %d", sort_by, want_nsfw_links, nsfw_links)
+			}
+		}
+	}
+
+	// Cursor for a different sort
+	cursor, err := DecodeLinksCursor(
+		EncodeLinksCursor(model.SortByNewest, &model.Link{ID: "1"}),
+		model.SortByNewest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+		SortBy: model.SortByClicks,
+		Cursor: cursor,
+	}); err == nil {
+		t.Fatal("expected error for cursor issued for a different sort_by")
+	}
+}
+
+func TestTopLinksExcludePrivate(t *testing.T) {
+	test_links := []struct {
+		ID         string
+		Cats       string
+		Visibility model.LinkVisibility
+	}{
+		{"private-test-public", "publictestcat", model.LinkPublic},
+		{"private-test-private", "privatetestcat", model.LinkPrivate},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, visibility)
+			VALUES (?, ?, ?, ?, ?, ?);`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			TEST_LOGIN_NAME,
+			time.Now().UTC().Format("2006-01-02 15:04:05"),
+			tl.Cats,
+			tl.Visibility,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+	}
+
+	// Top links
+	for _, include_nsfw := range []bool{false, true} {
+		links_sql, err := NewTopLinks().FromOptions(&model.TopLinksOptions{
+			URLContains: "private-test",
+			IncludeNSFW: include_nsfw,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := links_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		for rows.Next() {
+			count++
+		}
+		rows.Close()
+
+		if count != 1 {
+			t.Fatalf("expected only the public link (include NSFW: %t), got %d links", include_nsfw, count)
+		}
+	}
+
+	// Global cat counts
+	cats_sql, err := NewTopGlobalCatCounts().FromOptions(&model.TopCatCountsOptions{
+		URLContains: "private-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := cats_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var cats []string
+	for rows.Next() {
+		var cat string
+		var count int
+		if err := rows.Scan(&cat, &count); err != nil {
+			t.Fatal(err)
+		}
+		cats = append(cats, cat)
+	}
+	if !slices.Equal(cats, []string{"publictestcat"}) {
+		t.Fatalf("expected only the public link's cat, got %v", cats)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/julianlk522/modeep/db"
@@ -60,29 +61,59 @@ func GetCatsOptionalPluralOrSingularForms(cats []string) []string {
 	return modified_cats
 }
 
+// Approved synonyms and their variants are included too, e.g.,
+// "golang" => ("golang" OR "golangs" OR "go" OR "gos")
 func withOptionalPluralOrSingularForm(cat string) string {
+	return getMatchArgFromForms(getCatMatchForms(cat))
+}
+
+// Same as withOptionalPluralOrSingularForm(), plus the cat's descendants
+// and their variants, e.g.,
+// "databases" => ("databases" OR "databaseses" OR "database" OR "sqlite" OR "sqlites")
+func withOptionalPluralOrSingularFormAndDescendants(cat string) string {
+	forms := getCatMatchForms(cat)
+	for _, descendant := range GetCatDescendants(cat) {
+		for _, form := range getCatMatchForms(descendant) {
+			if !slices.Contains(forms, form) {
+				forms = append(forms, form)
+			}
+		}
+	}
+
+	return getMatchArgFromForms(forms)
+}
+
+func getCatMatchForms(cat string) []string {
+	forms := getPluralOrSingularForms(cat)
+	for _, synonym := range GetCatSynonyms(cat) {
+		for _, form := range getPluralOrSingularForms(synonym) {
+			if !slices.Contains(forms, form) {
+				forms = append(forms, form)
+			}
+		}
+	}
+
+	return forms
+}
+
+func getMatchArgFromForms(forms []string) string {
+	quoted_forms := make([]string, len(forms))
+	for i := range forms {
+		quoted_forms[i] = getCatSurroundedInDoubleQuotes(forms[i])
+	}
+	return "(" + strings.Join(quoted_forms, " OR ") + ")"
+}
+
+func getPluralOrSingularForms(cat string) []string {
 	lc_cat := strings.ToLower(cat)
 	if strings.HasSuffix(lc_cat, "ss") {
-		return fmt.Sprintf("(%s OR %s)",
-			getCatSurroundedInDoubleQuotes(lc_cat),
-			getCatSurroundedInDoubleQuotes(lc_cat+"es"),
-		)
+		return []string{lc_cat, lc_cat + "es"}
 	} else if strings.HasSuffix(lc_cat, "sses") {
-		return fmt.Sprintf("(%s OR %s)",
-			getCatSurroundedInDoubleQuotes(lc_cat),
-			getCatSurroundedInDoubleQuotes(strings.TrimSuffix(lc_cat, "es")),
-		)
+		return []string{lc_cat, strings.TrimSuffix(lc_cat, "es")}
 	} else if strings.HasSuffix(lc_cat, "s") {
-		return fmt.Sprintf("(%s OR %s OR %s)",
-			getCatSurroundedInDoubleQuotes(lc_cat),
-			getCatSurroundedInDoubleQuotes(lc_cat+"es"),
-			getCatSurroundedInDoubleQuotes(strings.TrimSuffix(lc_cat, "s")),
-		)
+		return []string{lc_cat, lc_cat + "es", strings.TrimSuffix(lc_cat, "s")}
 	} else {
-		return fmt.Sprintf("(%s OR %s)",
-			getCatSurroundedInDoubleQuotes(lc_cat),
-			getCatSurroundedInDoubleQuotes(lc_cat+"s"),
-		)
+		return []string{lc_cat, lc_cat + "s"}
 	}
 }
 
@@ -90,6 +121,19 @@ func getCatSurroundedInDoubleQuotes(cat string) string {
 	return fmt.Sprintf(`"%s"`, cat)
 }
 
+// PAGE CONTENT
+// for FTS5 MATCH clause: each word is quoted so that FTS5 operators and
+// punctuation in user input are matched literally, and all words must
+// be present (implicit AND)
+func getContentMatchArg(snippet string) string {
+	words := strings.Fields(snippet)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+
+	return strings.Join(words, " ")
+}
+
 // PERIOD
 func getPeriodClause(period model.Period) (clause string, err error) {
 	days, ok := model.ValidPeriodsInDays[period]
@@ -52,3 +52,22 @@ func TestWithOptionalPluralOrSingularForm(t *testing.T) {
 		}
 	}
 }
+
+func TestGetContentMatchArg(t *testing.T) {
+	var test_snippets = []struct {
+		Snippet        string
+		ExpectedResult string
+	}{
+		{"golang", `"golang"`},
+		{"  go   generics ", `"go" "generics"`},
+		{`say "hi"`, `"say" """hi"""`},
+		{"c++ OR NOT", `"c++" "OR" "NOT"`},
+	}
+
+	for _, ts := range test_snippets {
+		got := getContentMatchArg(ts.Snippet)
+		if got != ts.ExpectedResult {
+			t.Fatalf("got %s, want %s", got, ts.ExpectedResult)
+		}
+	}
+}
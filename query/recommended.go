@@ -0,0 +1,132 @@
+package query
+
+import (
+	"strings"
+
+	e "github.com/julianlk522/modeep/error"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Everything a user has starred, tagged or clicked, for building their
+// cat affinity profile (see handler/util.GetCatAffinityProfile())
+type CatAffinitySources struct {
+	Query
+}
+
+func NewCatAffinitySources(user_id string, login_name string) *CatAffinitySources {
+	return &CatAffinitySources{
+		Query: Query{
+			Text: CAT_AFFINITY_SOURCES,
+			Args: []any{user_id, login_name, user_id},
+		},
+	}
+}
+
+// weight: stars assigned, 1 per tag or number of qualified clicks
+const CAT_AFFINITY_SOURCES = `SELECT
+	'star' AS source,
+	l.id,
+	l.url,
+	COALESCE(l.global_cats, '') AS cats,
+	s.num_stars AS weight
+FROM Stars s
+INNER JOIN Links l ON l.id = s.link_id
+WHERE s.user_id = ?
+UNION ALL
+SELECT
+	'tag' AS source,
+	l.id,
+	l.url,
+	t.cats,
+	1 AS weight
+FROM Tags t
+INNER JOIN Links l ON l.id = t.link_id
+WHERE t.submitted_by = ?
+UNION ALL
+SELECT
+	'click' AS source,
+	l.id,
+	l.url,
+	COALESCE(l.global_cats, '') AS cats,
+	COUNT(*) AS weight
+FROM Clicks c
+INNER JOIN Links l ON l.id = c.link_id
+WHERE c.user_id = ?
+AND c.disqualified_reason = ''
+GROUP BY l.id;`
+
+// Candidates for a user's recommended links: non-NSFW links with any of
+// their top affinity cats (or their plural/singular variants) that they
+// haven't submitted, starred, tagged or clicked. Final ranking happens
+// after scanning (see handler/util.ScanRecommendedLinks()).
+type RecommendedLinks struct {
+	Query
+}
+
+func NewRecommendedLinks(user_id string, login_name string, cats []string) *RecommendedLinks {
+	rl := &RecommendedLinks{
+		Query: Query{
+			Text: recommended_links_base_query,
+			Args: []any{
+				mutil.EARLIEST_STARRERS_LIMIT,
+				user_id,
+				// Any cat, not all
+				strings.Join(GetCatsOptionalPluralOrSingularForms(cats), " OR "),
+				login_name,
+				user_id,
+				login_name,
+				user_id,
+				RECOMMENDED_LINKS_CANDIDATES_LIMIT,
+			},
+		},
+	}
+	if len(cats) == 0 || cats[0] == "" {
+		rl.Error = e.ErrNoCats
+	}
+
+	return rl
+}
+
+const RECOMMENDED_LINKS_CTES = `,
+AffinityCats AS (
+	SELECT link_id
+	FROM global_cats_fts
+	WHERE global_cats MATCH ?
+)`
+
+const RECOMMENDED_LINKS_JOIN = `
+INNER JOIN AffinityCats ac ON l.id = ac.link_id`
+
+const RECOMMENDED_LINKS_UNSEEN_AND = `
+AND l.submitted_by != ?
+AND l.id NOT IN (
+	SELECT link_id FROM Stars WHERE user_id = ?
+)
+AND l.id NOT IN (
+	SELECT link_id FROM Tags WHERE submitted_by = ?
+)
+AND l.id NOT IN (
+	SELECT link_id FROM Clicks WHERE user_id = ?
+)`
+
+const RECOMMENDED_LINKS_ORDER_BY = `
+ORDER BY
+	rating DESC,
+	times_starred DESC,
+	submit_date DESC,
+	l.id DESC`
+
+var recommended_links_base_query = LINKS_BASE_CTES +
+	LINKS_AUTH_CTE +
+	RECOMMENDED_LINKS_CTES +
+	LINKS_BASE_FIELDS +
+	LINKS_AUTH_FIELD +
+	LINKS_FROM +
+	RECOMMENDED_LINKS_JOIN +
+	LINKS_BASE_JOINS +
+	LINKS_AUTH_JOIN +
+	LINKS_CONTENT_MATCHES_JOIN +
+	LINKS_NO_NSFW_CATS_WHERE +
+	RECOMMENDED_LINKS_UNSEEN_AND +
+	RECOMMENDED_LINKS_ORDER_BY +
+	LINKS_LIMIT
@@ -0,0 +1,189 @@
+package query
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+	mutil "github.com/julianlk522/modeep/model/util"
+)
+
+// Candidates for links related to a link: those sharing any of its
+// global cats (or their plural/singular variants) and those starred by
+// anyone who also starred it. Final ranking happens after scanning
+// (see handler/util.ScanRelatedLinks()) since cats count as shared when
+// they only resemble each other.
+type RelatedLinks struct {
+	Query
+}
+
+func NewRelatedLinks(link_id string, cats []string) *RelatedLinks {
+	rl := &RelatedLinks{
+		Query: Query{
+			Text: related_links_base_query,
+			Args: []any{
+				mutil.EARLIEST_STARRERS_LIMIT,
+				link_id,
+			},
+		},
+	}
+
+	if len(cats) == 0 || cats[0] == "" {
+		rl.Text = strings.Replace(
+			rl.Text,
+			RELATED_LINKS_SHARED_CATS_CTE,
+			RELATED_LINKS_NO_SHARED_CATS_CTE,
+			1,
+		)
+	} else {
+		// One match per cat so shared_cats counts how many of them a
+		// link has
+		match_args := slices.Compact(slices.Sorted(slices.Values(
+			GetCatsOptionalPluralOrSingularForms(cats),
+		)))
+		rl.Text = strings.Replace(
+			rl.Text,
+			RELATED_LINKS_CAT_MATCH,
+			strings.Repeat(
+				RELATED_LINKS_CAT_MATCH+"\n\t\tUNION ALL\n\t\t",
+				len(match_args)-1,
+			)+RELATED_LINKS_CAT_MATCH,
+			1,
+		)
+		for _, match_arg := range match_args {
+			rl.Args = append(rl.Args, match_arg)
+		}
+	}
+
+	rl.Args = append(
+		rl.Args,
+		RELATED_LINKS_CANDIDATES_LIMIT,
+		RELATED_LINKS_CANDIDATES_LIMIT,
+		link_id,
+	)
+	return rl
+}
+
+func (rl *RelatedLinks) FromOptions(opts *model.RelatedLinksOptions) (*RelatedLinks, error) {
+	if opts.AsSignedInUser != "" {
+		rl = rl.asSignedInUser(opts.AsSignedInUser)
+	}
+	if opts.IncludeNSFW {
+		rl = rl.includeNSFW()
+	}
+	if rl.Error != nil {
+		return nil, rl.Error
+	}
+	return rl, nil
+}
+
+func (rl *RelatedLinks) asSignedInUser(req_user_id string) *RelatedLinks {
+	auth_replacer := strings.NewReplacer(
+		LINKS_BASE_CTES, LINKS_BASE_CTES+LINKS_AUTH_CTE,
+		LINKS_BASE_FIELDS, LINKS_BASE_FIELDS+LINKS_AUTH_FIELD,
+		LINKS_BASE_JOINS, LINKS_BASE_JOINS+LINKS_AUTH_JOIN,
+	)
+	rl.Text = auth_replacer.Replace(rl.Text)
+
+	// old: [EARLIEST_STARRERS_LIMIT, link_id, ...]
+	// new: [EARLIEST_STARRERS_LIMIT, req_user_id, link_id, ...]
+	new_args := make([]any, 0, len(rl.Args)+1)
+	new_args = append(new_args, rl.Args[0], req_user_id)
+	new_args = append(new_args, rl.Args[1:]...)
+	rl.Args = new_args
+
+	return rl
+}
+
+func (rl *RelatedLinks) includeNSFW() *RelatedLinks {
+	rl.Text = strings.Replace(
+		rl.Text,
+		LINKS_NO_NSFW_CATS_WHERE+"\nAND",
+		"\nWHERE",
+		1,
+	)
+	return rl
+}
+
+// co_stars: number of users who starred both the link and the candidate.
+// Candidates are the links sharing the most cats plus the most
+// co-starred, so that neither crowds the other out before re-ranking.
+const RELATED_LINKS_CTES = `,
+CoStars AS (
+	SELECT s2.link_id, COUNT(DISTINCT s2.user_id) AS co_stars
+	FROM Stars s1
+	INNER JOIN Stars s2 ON s2.user_id = s1.user_id
+	WHERE s1.link_id = ?
+	AND s2.link_id != s1.link_id
+	GROUP BY s2.link_id
+),` + RELATED_LINKS_SHARED_CATS_CTE + `,
+Candidates AS (
+	SELECT link_id FROM (
+		SELECT cs.link_id
+		FROM CoStars cs
+		INNER JOIN "Public Links" pl ON pl.id = cs.link_id
+		ORDER BY cs.co_stars DESC, cs.link_id DESC
+		LIMIT ?
+	)
+	UNION
+	SELECT link_id FROM (
+		SELECT shc.link_id
+		FROM SharedCats shc
+		INNER JOIN "Public Links" pl ON pl.id = shc.link_id
+		LEFT JOIN TimesStarred ts ON ts.link_id = shc.link_id
+		ORDER BY
+			shc.shared_cats DESC,
+			COALESCE(ts.times_starred, 0) DESC,
+			shc.link_id DESC
+		LIMIT ?
+	)
+)`
+
+// Repeated for each of the link's cats (see NewRelatedLinks())
+const RELATED_LINKS_CAT_MATCH = `SELECT link_id FROM global_cats_fts WHERE global_cats MATCH ?`
+
+const RELATED_LINKS_SHARED_CATS_CTE = `
+SharedCats AS (
+	SELECT link_id, COUNT(*) AS shared_cats
+	FROM (
+		` + RELATED_LINKS_CAT_MATCH + `
+	)
+	GROUP BY link_id
+)`
+
+// Link has no cats: only co-starred links are candidates
+const RELATED_LINKS_NO_SHARED_CATS_CTE = `
+SharedCats AS (
+	SELECT NULL AS link_id, 0 AS shared_cats
+	WHERE 0
+)`
+
+const RELATED_LINKS_FIELD = `,
+	COALESCE(cs.co_stars, 0) AS co_stars`
+
+const RELATED_LINKS_JOINS = `
+INNER JOIN Candidates c ON l.id = c.link_id
+LEFT JOIN CoStars cs ON l.id = cs.link_id`
+
+const RELATED_LINKS_NOT_SELF_AND = `
+AND l.id != ?`
+
+const RELATED_LINKS_ORDER_BY = `
+ORDER BY
+	co_stars DESC,
+	times_starred DESC,
+	avg_stars DESC,
+	submit_date DESC,
+	l.id DESC`
+
+var related_links_base_query = LINKS_BASE_CTES +
+	RELATED_LINKS_CTES +
+	LINKS_BASE_FIELDS +
+	RELATED_LINKS_FIELD +
+	LINKS_FROM +
+	RELATED_LINKS_JOINS +
+	LINKS_BASE_JOINS +
+	LINKS_CONTENT_MATCHES_JOIN +
+	LINKS_NO_NSFW_CATS_WHERE +
+	RELATED_LINKS_NOT_SELF_AND +
+	RELATED_LINKS_ORDER_BY + ";"
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+func TestRelatedLinks(t *testing.T) {
+	test_links := []struct {
+		ID   string
+		Cats string
+	}{
+		{"related-test-src", "relatedtestcat,relatedtestother"},
+		// plural variant
+		{"related-test-cat", "relatedtestcats"},
+		{"related-test-co-starred", "zzcostarred"},
+		{"related-test-nsfw", "relatedtestother,NSFW"},
+		{"related-test-none", "zznothing"},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, ?, ?);`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			TEST_LOGIN_NAME,
+			time.Now().UTC().Format("2006-01-02 15:04:05"),
+			tl.Cats,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+	}
+	for _, link_id := range []string{"related-test-src", "related-test-co-starred"} {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp)
+			VALUES (?, ?, 'related-test-user', 3, ?);`,
+			link_id+"-star",
+			link_id,
+			time.Now().UTC().Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Stars WHERE link_id = ?;", link_id)
+	}
+
+	var test_cases = []struct {
+		Cats        []string
+		Opts        *model.RelatedLinksOptions
+		ExpectedIDs []string
+	}{
+		{
+			[]string{"relatedtestcat", "relatedtestother"},
+			&model.RelatedLinksOptions{},
+			[]string{"related-test-co-starred", "related-test-cat"},
+		},
+		{
+			[]string{"relatedtestcat", "relatedtestother"},
+			&model.RelatedLinksOptions{IncludeNSFW: true, AsSignedInUser: TEST_USER_ID},
+			[]string{"related-test-co-starred", "related-test-nsfw", "related-test-cat"},
+		},
+		// only co-starred links without cats
+		{
+			nil,
+			&model.RelatedLinksOptions{AsSignedInUser: TEST_USER_ID},
+			[]string{"related-test-co-starred"},
+		},
+	}
+
+	for _, tc := range test_cases {
+		related_sql, err := NewRelatedLinks("related-test-src", tc.Cats).FromOptions(tc.Opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := related_sql.ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatalf("err: %v, sql text: %s, args: %v", err, related_sql.Text, related_sql.Args)
+		}
+
+		cols, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for rows.Next() {
+			vals := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatal(err)
+			}
+
+			// ignore links already in test DB
+			if id := vals[0].(string); strings.HasPrefix(id, "related-test-") {
+				ids = append(ids, id)
+			}
+		}
+		rows.Close()
+
+		if !slices.Equal(ids, tc.ExpectedIDs) {
+			t.Fatalf("cats %v, opts %+v: expected %v, got %v", tc.Cats, tc.Opts, tc.ExpectedIDs, ids)
+		}
+	}
+}
+
+func TestRelatedLinksCandidatesShareMostCats(t *testing.T) {
+	// More links sharing 1 cat (and starred) than there are candidate
+	// slots shouldn't crowd out one sharing both
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	test_links := map[string]string{"related-test-both": "relatedtestcat,relatedtestother"}
+	for i := range RELATED_LINKS_CANDIDATES_LIMIT {
+		test_links[fmt.Sprintf("related-test-filler-%03d", i)] = "relatedtestcat"
+	}
+	for id, cats := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats)
+			VALUES (?, ?, ?, ?, ?);`,
+			id,
+			"https://"+id+".com",
+			TEST_LOGIN_NAME,
+			now,
+			cats,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", id)
+
+		if id == "related-test-both" {
+			continue
+		}
+		if _, err := TestClient.Exec(
+			`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp)
+			VALUES (?, ?, 'related-test-user', 3, ?);`,
+			id+"-star",
+			id,
+			now,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Stars WHERE link_id = ?;", id)
+	}
+
+	related_sql := NewRelatedLinks("related-test-src", []string{"relatedtestcat", "relatedtestother"})
+	rows, err := related_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatalf("err: %v, sql text: %s, args: %v", err, related_sql.Text, related_sql.Args)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		cols, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		vals := make([]any, len(cols))
+		vals[0] = &id
+		for i := 1; i < len(vals); i++ {
+			vals[i] = new(any)
+		}
+		if err := rows.Scan(vals...); err != nil {
+			t.Fatal(err)
+		}
+		if id == "related-test-both" {
+			return
+		}
+	}
+	t.Fatal("expected link sharing both cats among candidates")
+}
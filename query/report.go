@@ -0,0 +1,83 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/julianlk522/modeep/model"
+)
+
+// Moderation queue: open reports by default, oldest first
+type Reports struct {
+	*Query
+}
+
+func NewReports() *Reports {
+	return &Reports{
+		&Query{
+			Text: REPORTS,
+			// 1 extra row is queried to tell if there is a next page
+			Args: []any{model.ReportOpen, REPORTS_PAGE_LIMIT + 1},
+		},
+	}
+}
+
+const REPORTS = `SELECT
+	r.id,
+	r.content_type,
+	r.content_id,
+	r.reason,
+	r.details,
+	r.reported_by,
+	r.reported_at,
+	r.status,
+	r.resolved_by,
+	r.resolved_at,
+	(
+		SELECT COUNT(*)
+		FROM Reports o
+		WHERE o.content_type = r.content_type
+		AND o.content_id = r.content_id
+		AND o.status = 'open'
+	) AS open_report_count
+FROM Reports r
+WHERE r.status = ?
+ORDER BY r.reported_at ASC, r.id ASC
+LIMIT ?;`
+
+func (r *Reports) FromOptions(opts *model.ReportsOptions) (*Reports, error) {
+	if opts.Status != "" {
+		r.withStatus(opts.Status)
+	}
+	if opts.Page > 1 {
+		r.page(opts.Page)
+	}
+
+	return r, nil
+}
+
+// Closed reports are ordered most recently resolved first
+func (r *Reports) withStatus(status model.ReportStatus) *Reports {
+	r.Args[0] = status
+	if status != model.ReportOpen {
+		r.Text = strings.Replace(
+			r.Text,
+			"ORDER BY r.reported_at ASC",
+			"ORDER BY r.resolved_at DESC, r.reported_at ASC",
+			1,
+		)
+	}
+
+	return r
+}
+
+func (r *Reports) page(page uint) *Reports {
+	r.Text = strings.Replace(
+		r.Text,
+		"LIMIT ?;",
+		"LIMIT ? OFFSET ?;",
+		1,
+	)
+	r.Args = append(r.Args, (page-1)*REPORTS_PAGE_LIMIT)
+
+	return r
+}
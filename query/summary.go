@@ -41,7 +41,7 @@ FROM
 	FROM 
 		(
 		SELECT id as sumid, text, submitted_by as sb, last_updated
-		FROM Summaries
+		FROM "Visible Summaries"
 		WHERE link_id = ?
 		) 
 	JOIN Users 
@@ -41,7 +41,7 @@ func NewTagRankingsForLink(link_id string) *TagRankingsForLink {
 	t.cats, 
 	t.submitted_by, 
 	t.last_updated
-FROM Tags t
+FROM "Visible Tags" t
 INNER JOIN Links l
 ON l.id = t.link_id
 WHERE t.link_id = ?
@@ -64,7 +64,7 @@ func NewGlobalCatsForLink(link_id string) *GlobalCatsForLink {
         (julianday('now') - julianday(t.last_updated)) / (julianday('now') - julianday(l.submit_date)) * 100 AS lifespan_overlap,
         t.link_id,
         t.cats as cats
-    FROM Tags t
+    FROM "Visible Tags" t
     INNER JOIN Links l ON l.id = t.link_id
     WHERE t.link_id = ?
     ORDER BY lifespan_overlap DESC
@@ -95,11 +95,8 @@ NormalizedCats AS (
         link_id,
 	lifespan_overlap,
         cat,
-        CASE
-            WHEN LOWER(cat) LIKE '%sses' THEN substr(LOWER(cat), 1, length(LOWER(cat)) - 2)
-            WHEN LOWER(cat) LIKE '%s' AND NOT LOWER(cat) LIKE '%ss' THEN substr(LOWER(cat), 1, length(LOWER(cat)) - 1)
-            ELSE LOWER(cat)
-        END as normalized_cat
+        ` + singularCatSQL("cat") + ` as singular_cat,
+        ` + normalizedCatSQL("cat") + ` as normalized_cat
     FROM IndividualCats
 ),
 IdealSpellingVariants AS (
@@ -111,7 +108,11 @@ IdealSpellingVariants AS (
 	    cat FROM NormalizedCats nc2 
 	    WHERE nc2.link_id = nc1.link_id 
 	    AND nc2.normalized_cat = nc1.normalized_cat
-	    ORDER BY length(cat) DESC, cat DESC 
+	    ORDER BY
+		-- prefer the cat that synonyms were approved for
+		singular_cat = normalized_cat DESC,
+		length(cat) DESC,
+		cat DESC 
 	    LIMIT 1
         ) as ideal_cat_spelling
     FROM NormalizedCats nc1
@@ -151,7 +152,7 @@ func NewTopGlobalCatCounts() *TopGlobalCatCounts {
 
 const TOP_GLOBAL_CATS_BASE = `WITH RECURSIVE GlobalCatsSplit(id, global_cat, str) AS (
     SELECT id, '', global_cats||','
-    FROM Links
+    FROM "Public Links"
     UNION ALL SELECT
         id,
         substr(str, 0, instr(str, ',')),
@@ -200,8 +201,16 @@ ORDER BY count_across_spelling_variations DESC
 LIMIT ?;`
 
 func (gcc *TopGlobalCatCounts) FromOptions(opts *model.TopCatCountsOptions) (*TopGlobalCatCounts, error) {
-	if opts.RawCatFilters != nil {
-		gcc = gcc.fromCatFilters(opts.RawCatFilters)
+	if opts.Tree {
+		gcc = gcc.rolledUpToAncestors()
+	}
+	if opts.CatFiltersWithSpellingVariants != nil {
+		gcc = gcc.fromCatFilters(opts.RawCatFilters, opts.CatFiltersWithSpellingVariants)
+	}
+	// (after .fromCatFilters(), which resets args, and before
+	// .fromNeuteredCatFilters(), whose CTEs and arg come first)
+	if opts.Domain != "" {
+		gcc = gcc.whereDomain(opts.Domain)
 	}
 	if opts.NeuteredCatFilters != nil {
 		gcc = gcc.fromNeuteredCatFilters(opts.NeuteredCatFilters)
@@ -236,8 +245,39 @@ func (gcc *TopGlobalCatCounts) FromOptions(opts *model.TopCatCountsOptions) (*To
 	return gcc, nil
 }
 
-func (gcc *TopGlobalCatCounts) fromCatFilters(raw_cat_filters []string) *TopGlobalCatCounts {
-	if len(raw_cat_filters) == 0 {
+// Each link counts toward the ancestors of its cats as well (once per
+// ancestor, even if several of its cats share it)
+func (gcc *TopGlobalCatCounts) rolledUpToAncestors() *TopGlobalCatCounts {
+	gcc.Text = strings.Replace(
+		gcc.Text,
+		`IndividualCatCounts AS (
+    SELECT global_cat, count(DISTINCT id) as count
+    FROM GlobalCatsSplit`,
+		GLOBAL_CATS_WITH_ANCESTORS_CTE+`,
+IndividualCatCounts AS (
+    SELECT global_cat, count(DISTINCT id) as count
+    FROM GlobalCatsWithAncestors`,
+		1,
+	)
+
+	return gcc
+}
+
+// (UNION, not UNION ALL, so that the recursion ends even if "Cat Parents"
+// somehow has a cycle)
+var GLOBAL_CATS_WITH_ANCESTORS_CTE = `GlobalCatsWithAncestors(id, global_cat) AS (
+    SELECT id, global_cat
+    FROM GlobalCatsSplit
+    UNION
+    SELECT gcwa.id, cp.parent
+    FROM GlobalCatsWithAncestors gcwa
+    INNER JOIN "Cat Parents" cp ON cp.cat = ` + singularCatSQL("gcwa.global_cat") + `
+)`
+
+// raw_cat_filters are omitted from counts and may be empty if the cats
+// query only has prefixes, e.g., "data*"
+func (gcc *TopGlobalCatCounts) fromCatFilters(raw_cat_filters []string, cat_filters_with_spelling_variants []string) *TopGlobalCatCounts {
+	if len(cat_filters_with_spelling_variants) == 0 {
 		return gcc
 	}
 
@@ -250,17 +290,14 @@ func (gcc *TopGlobalCatCounts) fromCatFilters(raw_cat_filters []string) *TopGlob
 		)`
 
 	// Build NOT IN clauses
-	individual_cat_counts_not_in_clause := `
-	AND LOWER(global_cat) NOT IN (?`
-	normalized_cat_counts_not_in_clause := `
-	WHERE normalized_global_cat NOT IN (?`
-
-	for i := 1; i < len(raw_cat_filters); i++ {
-		individual_cat_counts_not_in_clause += ", ?"
-		normalized_cat_counts_not_in_clause += ", ?"
+	var individual_cat_counts_not_in_clause, normalized_cat_counts_not_in_clause string
+	if len(raw_cat_filters) > 0 {
+		placeholders := "?" + strings.Repeat(", ?", len(raw_cat_filters)-1)
+		individual_cat_counts_not_in_clause = `
+	AND LOWER(global_cat) NOT IN (` + placeholders + ")"
+		normalized_cat_counts_not_in_clause = `
+	WHERE normalized_global_cat NOT IN (` + placeholders + ")"
 	}
-	individual_cat_counts_not_in_clause += ")"
-	normalized_cat_counts_not_in_clause += ")"
 
 	// Add clauses
 	gcc.Text = strings.Replace(
@@ -280,21 +317,14 @@ func (gcc *TopGlobalCatCounts) fromCatFilters(raw_cat_filters []string) *TopGlob
 	)
 
 	// Build NOT IN args
-	not_in_args := []any{strings.ToLower(raw_cat_filters[0])}
-	for i := 1; i < len(raw_cat_filters); i++ {
-		not_in_args = append(not_in_args, strings.ToLower(raw_cat_filters[i]))
+	not_in_args := make([]any, len(raw_cat_filters))
+	for i, cat := range raw_cat_filters {
+		not_in_args[i] = strings.ToLower(cat)
 	}
 
 	// Build MATCH arg
-	// cat_filters_With_spelling_variants := GetCatsOptionalPluralOrSingularForms(raw_cat_filters)
-	// match_arg := cat_filters_With_spelling_variants[0]
-	// for i := 1; i < len(cat_filters_With_spelling_variants); i++ {
-	// 	match_arg += " AND " + cat_filters_With_spelling_variants[i]
-	// }
-	match_arg := strings.Join(
-		GetCatsOptionalPluralOrSingularForms(raw_cat_filters),
-		" AND ",
-	)
+	// (spelling variations already added in .FromRequestParams())
+	match_arg := strings.Join(cat_filters_with_spelling_variants, " AND ")
 
 	// Add args: {not_in_args...}, match_arg
 	// old: [GLOBAL_CATS_PAGE_LIMIT]
@@ -311,19 +341,21 @@ func (gcc *TopGlobalCatCounts) fromNeuteredCatFilters(neutered_cat_filters []str
 		return gcc
 	}
 
-	// Add CTEs
+	// SELECT from new CTEs
+	// (before adding them since LinksWithNonNeuteredCats also selects
+	// FROM "Public Links")
 	gcc.Text = strings.Replace(
 		gcc.Text,
-		"WITH RECURSIVE ",
-		"WITH\n"+GLOBAL_CAT_COUNTS_NEUTERED_CATS_CTES+",\n",
+		`FROM "Public Links"`,
+		"FROM LinksWithNonNeuteredCats",
 		1,
 	)
 
-	// SELECT from new CTEs
+	// Add CTEs
 	gcc.Text = strings.Replace(
 		gcc.Text,
-		"FROM Links",
-		"FROM LinksWithNonNeuteredCats",
+		"WITH RECURSIVE ",
+		"WITH\n"+GLOBAL_CAT_COUNTS_NEUTERED_CATS_CTES+",\n",
 		1,
 	)
 
@@ -358,6 +390,7 @@ LinksWithNonNeuteredCats AS (
 	SELECT link_id as id, global_cats
 	FROM global_cats_fts
 	WHERE link_id NOT IN LinksWithNeuteredCats
+	AND link_id IN (SELECT id FROM "Public Links")
 )`
 
 func (gcc *TopGlobalCatCounts) whereGlobalSummaryContains(snippet string) *TopGlobalCatCounts {
@@ -552,6 +585,20 @@ func (gcc *TopGlobalCatCounts) whereURLLacks(snippet string) *TopGlobalCatCounts
 	return gcc
 }
 
+func (gcc *TopGlobalCatCounts) whereDomain(domain string) *TopGlobalCatCounts {
+	gcc.Text = strings.Replace(
+		gcc.Text,
+		"WHERE str != ''",
+		"WHERE str != ''\nAND id IN (SELECT id FROM Links WHERE domain = ?)",
+		1,
+	)
+
+	// prepend arg
+	gcc.Args = append([]any{domain}, gcc.Args...)
+
+	return gcc
+}
+
 func (gcc *TopGlobalCatCounts) duringPeriod(period model.Period) *TopGlobalCatCounts {
 	if period == "all" {
 		return gcc
@@ -565,9 +612,9 @@ func (gcc *TopGlobalCatCounts) duringPeriod(period model.Period) *TopGlobalCatCo
 
 	gcc.Text = strings.Replace(
 		gcc.Text,
-		"FROM Links",
+		`FROM "Public Links"`,
 		fmt.Sprintf(
-			`FROM Links
+			`FROM "Public Links"
 			WHERE %s`,
 			clause,
 		),
@@ -612,18 +659,30 @@ const SPELLFIX_MATCHES_CTE = `SpellfixMatches AS (
     ORDER BY distance, rank DESC
 )`
 
-// e.g., "Tests" => "test"
-const NORMALIZED_MATCHES_CTE = `NormalizedMatches AS (
+// e.g., "Tests" => "test", "JS" => "javascript"
+// Approved synonyms of matches are added so that their ranks combine,
+// e.g., "js" suggests "javascript" if it is more popular
+var NORMALIZED_MATCHES_CTE = `NormalizedMatches AS (
 	SELECT 
 		word,
 		rank,
 		distance,
-		CASE
-			WHEN LOWER(word) LIKE '%sses' THEN substr(LOWER(word), 1, length(LOWER(word)) - 2)
-			WHEN LOWER(word) LIKE '%s' AND NOT LOWER(word) LIKE '%ss' THEN substr(LOWER(word), 1, length(LOWER(word)) - 1)
-			ELSE LOWER(word)
-		END as normalized_word
-	FROM SpellfixMatches
+		` + normalizedCatSQL("word") + ` as normalized_word
+	FROM (
+		SELECT word, rank, distance
+		FROM SpellfixMatches
+		UNION ALL
+		-- (NULL distance: the matched synonym's is used for the group)
+		SELECT gcs.word, gcs.rank, NULL
+		FROM global_cats_spellfix gcs
+		WHERE LOWER(gcs.word) IN (
+			SELECT syn.cat
+			FROM "Approved Cat Synonyms" syn
+			INNER JOIN "Approved Cat Synonyms" matched ON matched.canonical = syn.canonical
+			WHERE matched.cat IN (SELECT LOWER(word) FROM SpellfixMatches)
+		)
+		AND gcs.word NOT IN (SELECT word FROM SpellfixMatches)
+	)
 )`
 
 // Combine ranks for variations of the same normalized form
@@ -664,12 +723,12 @@ func (sm *SpellfixMatches) FromOptions(opts *model.SpellfixMatchesOptions) (*Spe
 	if opts.YouAreAddingCats {
 		sm.youAreAddingCats = true
 	}
-	if len(opts.CatFilters) > 0 {
-		if sm.youAreAddingCats {
+	if sm.youAreAddingCats {
+		if len(opts.CatFilters) > 0 {
 			sm = sm.fromCatFiltersWhileAddingCats(opts.CatFilters)
-		} else {
-			sm = sm.fromCatFilters(opts.CatFilters)
 		}
+	} else if len(opts.CatFiltersWithSpellingVariants) > 0 {
+		sm = sm.fromCatFilters(opts.CatFilters, opts.CatFiltersWithSpellingVariants)
 	}
 	if sm.Error != nil {
 		return nil, sm.Error
@@ -717,6 +776,7 @@ var TMAP_SPELLFIX_BASE = `WITH UserCatsMatches AS (
     FROM user_cats_fts ucfts
     WHERE ucfts.submitted_by = ?
     AND cats MATCH ?
+    AND link_id IN (SELECT id FROM "Public Links")
 ),
 GlobalCatsMatches AS (
     SELECT
@@ -725,7 +785,7 @@ GlobalCatsMatches AS (
     FROM global_cats_fts
     WHERE global_cats MATCH ?
     AND link_id IN (
-		SELECT id FROM Links
+		SELECT id FROM "Public Links"
 		WHERE submitted_by = ?
 		UNION 
 		SELECT link_id FROM Stars
@@ -825,30 +885,29 @@ FROM FilteredSpellfixMatches
 ORDER BY (MAX(distance, %d) / rank_in_context), rank_in_context DESC
 LIMIT ?;`, DISTANCE_LOWER_BOUND)
 
-func (sm *SpellfixMatches) fromCatFilters(cat_filters []string) *SpellfixMatches {
-	if len(cat_filters) == 0 || cat_filters[0] == "" {
+// cat_filters are omitted from matches and may be empty if the cats query
+// only has prefixes, e.g., "data*"
+func (sm *SpellfixMatches) fromCatFilters(cat_filters []string, cat_filters_with_spelling_variants []string) *SpellfixMatches {
+	if len(cat_filters_with_spelling_variants) == 0 || cat_filters_with_spelling_variants[0] == "" {
 		sm.Error = e.ErrNoCatFilters
 		return sm
 	}
 
 	// Add placeholders to MatchingGlobalCats / MatchingCats CTEs NOT IN clause
-	// if more than 1 cat filter applied.
+	// if more than 1 cat filter applied, or remove it if none.
 	// (MatchingGlobalCats or MatchingCats depending on if .FromTmap() called first)
-	not_in_clause := "AND cat NOT IN (?"
+	var not_in_clause string
 	not_in_args := []any{}
-	for i, cat := range cat_filters {
-		if i > 0 {
-			not_in_clause += ", ?"
+	if len(cat_filters) > 0 {
+		not_in_clause = "AND cat NOT IN (?" + strings.Repeat(", ?", len(cat_filters)-1) + ")"
+		for _, cat := range cat_filters {
+			not_in_args = append(not_in_args, cat)
 		}
-		not_in_args = append(not_in_args, cat)
 	}
-	not_in_clause += ")"
 
 	// WHERE global_cats MATCH '("dog" OR "dogs") AND ("cat" OR "cats")', etc.
-	fts_match_subcats_arg := strings.Join(
-		GetCatsOptionalPluralOrSingularForms(cat_filters),
-		" AND ",
-	)
+	// (spelling variations already added in .FromRequestParams())
+	fts_match_subcats_arg := strings.Join(cat_filters_with_spelling_variants, " AND ")
 
 	// Determine if .FromTmap() was called first
 	// (likely a better way...)
@@ -858,7 +917,7 @@ func (sm *SpellfixMatches) fromCatFilters(cat_filters []string) *SpellfixMatches
 		// .FromTmap() not called
 		// Update CTEs
 		cat_filters_global_cats_ctes := CAT_FILTERS_GLOBAL_CATS_CTES
-		if len(not_in_args) > 1 {
+		if len(not_in_args) != 1 {
 			cat_filters_global_cats_ctes = strings.Replace(
 				cat_filters_global_cats_ctes,
 				"AND cat NOT IN (?)",
@@ -980,11 +1039,7 @@ var FILTERED_NORMALIZED_MATCHES_CTE = `NormalizedMatches AS (
 		word,
 		rank_in_context as rank,
 		distance,
-		CASE
-			WHEN LOWER(word) LIKE '%sses' THEN substr(LOWER(word), 1, length(LOWER(word)) - 2)
-			WHEN LOWER(word) LIKE '%s' AND NOT LOWER(word) LIKE '%ss' THEN substr(LOWER(word), 1, length(LOWER(word)) - 1)
-			ELSE LOWER(word)
-		END as normalized_word
+		` + normalizedCatSQL("word") + ` as normalized_word
 	FROM FilteredSpellfixMatches
 )`
 
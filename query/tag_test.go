@@ -119,7 +119,7 @@ func TestNewTopGlobalCatCounts(t *testing.T) {
 }
 
 func TestTopGlobalCatCountsFromCatFilters(t *testing.T) {
-	counts_sql := NewTopGlobalCatCounts().fromCatFilters(test_cats)
+	counts_sql := NewTopGlobalCatCounts().fromCatFilters(test_cats, GetCatsOptionalPluralOrSingularForms(test_cats))
 	rows, err := counts_sql.ValidateAndExecuteRows()
 	if err != nil {
 		t.Fatal(err)
@@ -268,7 +268,7 @@ func TestTopGlobalCatCountsWhereSummaryContains(t *testing.T) {
 
 	// verify does not conflict w/ other methods
 	counts_sql = NewTopGlobalCatCounts().
-		fromCatFilters([]string{"flowers"}).
+		fromCatFilters([]string{"flowers"}, GetCatsOptionalPluralOrSingularForms([]string{"flowers"})).
 		whereGlobalSummaryContains("test").
 		whereURLContains("www").
 		whereURLLacks("donut").
@@ -374,7 +374,7 @@ func TestTopGlobalCatCountsWhereURLContains(t *testing.T) {
 
 func TestTopGlobalCatCountsWhereURLLacks(t *testing.T) {
 	counts_sql := NewTopGlobalCatCounts().
-		fromCatFilters(test_cats).
+		fromCatFilters(test_cats, GetCatsOptionalPluralOrSingularForms(test_cats)).
 		whereURLLacks("GooGlE")
 
 	if counts_sql.Error != nil {
@@ -458,7 +458,7 @@ func TestTopGlobalCatCountsDuringPeriod(t *testing.T) {
 	// Verify no conflict with .fromCatFilters()
 	for _, tp := range test_periods {
 		tags_sql := NewTopGlobalCatCounts().
-			fromCatFilters(test_cats).
+			fromCatFilters(test_cats, GetCatsOptionalPluralOrSingularForms(test_cats)).
 			duringPeriod(tp.Period)
 		if tp.Valid && tags_sql.Error != nil {
 			t.Fatalf("unexpected error for period %s", tp.Period)
@@ -538,10 +538,15 @@ func TestSpellfixMatchesFromTmap(t *testing.T) {
 				&l.SummaryCount,
 				&l.TimesStarred,
 				&l.AvgStars,
+				&l.Rating,
 				&l.EarliestStarrers,
 				&l.ClickCount,
 				&l.TagCount,
+				&l.CommentCount,
 				&l.PreviewImgFilename,
+				&l.Health,
+				&l.LastCheckedAt,
+				&l.ContentSnippet,
 			); err != nil {
 				t.Fatal(err)
 			}
@@ -564,7 +569,7 @@ func TestSpellfixMatchesFromTmap(t *testing.T) {
 }
 
 func TestSpellfixMatchesFromCatFilters(t *testing.T) {
-	matches_sql := NewSpellfixMatchesForSnippet(TEST_SNIPPET).fromCatFilters([]string{TEST_SNIPPET})
+	matches_sql := NewSpellfixMatchesForSnippet(TEST_SNIPPET).fromCatFilters([]string{TEST_SNIPPET}, GetCatsOptionalPluralOrSingularForms([]string{TEST_SNIPPET}))
 	rows, err := matches_sql.ValidateAndExecuteRows()
 	if err != nil {
 		t.Fatal(err)
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"slices"
 	"strings"
 
 	e "github.com/julianlk522/modeep/error"
@@ -20,8 +21,11 @@ type TmapLinksQueryBuilder interface {
 	includeNSFW() TmapLinksQueryBuilder
 	duringPeriod(period model.Period) TmapLinksQueryBuilder
 	whereSummaryContains(snippet string) TmapLinksQueryBuilder
+	whereContentContains(snippet string) TmapLinksQueryBuilder
 	whereURLContains(snippet string) TmapLinksQueryBuilder
 	whereURLLacks(snippet string) TmapLinksQueryBuilder
+	whereDomain(domain string) TmapLinksQueryBuilder
+	excludeDead() TmapLinksQueryBuilder
 }
 
 type TmapSubmitted struct {
@@ -55,7 +59,8 @@ func NewTmapSubmitted(login_name string) *TmapSubmitted {
 				TMAP_BASE_FIELDS +
 				TMAP_FROM +
 				TMAP_BASE_JOINS +
-				NSFW_JOINS + "\n" +
+				NSFW_JOINS +
+				TMAP_CONTENT_MATCHES_JOIN + "\n" +
 				TMAP_NO_NSFW_CATS_WHERE +
 				SUBMITTED_AND +
 				TMAP_ORDER_BY_TIMES_STARRED,
@@ -77,12 +82,23 @@ func (ts *TmapSubmitted) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBui
 	if opts == nil {
 		return ts, nil
 	}
+	if opts.IncludePrivate {
+		ts.includePrivate()
+	}
 	if len(opts.CatFiltersWithSpellingVariants) > 0 {
 		ts.fromCatFilters(opts.CatFiltersWithSpellingVariants)
 	}
 	if len(opts.NeuteredCatFiltersWithSpellingVariants) > 0 {
 		ts.fromNeuteredCatFilters(opts.NeuteredCatFiltersWithSpellingVariants)
 	}
+	// (after CTE-level filters, before WHERE-level filters, so that
+	// its arg lands in the right place)
+	if opts.ContentContains != "" {
+		ts.whereContentContains(opts.ContentContains)
+		if opts.SortBy == "" {
+			ts.sortBy(model.SortByRelevance)
+		}
+	}
 	if opts.AsSignedInUser != "" {
 		ts.asSignedInUser(opts.AsSignedInUser)
 	}
@@ -104,6 +120,12 @@ func (ts *TmapSubmitted) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBui
 	if opts.URLLacks != "" {
 		ts.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		ts.whereDomain(opts.Domain)
+	}
+	if opts.ExcludeDead {
+		ts.excludeDead()
+	}
 	if ts.Error != nil {
 		return nil, ts.Error
 	}
@@ -287,6 +309,20 @@ WHERE l.submitted_by = ?`,
 	return ts
 }
 
+// Only for the Treasure Map owner. (Starred and Tagged have no
+// equivalent since they only include others' links, and others' private
+// links are never visible.)
+func (ts *TmapSubmitted) includePrivate() TmapLinksQueryBuilder {
+	ts.Text = strings.Replace(
+		ts.Text,
+		TMAP_FROM,
+		TMAP_FROM_INCLUDING_PRIVATE,
+		1,
+	)
+
+	return ts
+}
+
 func (ts *TmapSubmitted) sortBy(metric model.SortBy) TmapLinksQueryBuilder {
 	if metric != "" && metric != model.SortByTimesStarred {
 		order_by_clause, ok := tmap_order_by_clauses[metric]
@@ -351,6 +387,21 @@ func (ts *TmapSubmitted) whereSummaryContains(snippet string) TmapLinksQueryBuil
 	return ts
 }
 
+func (ts *TmapSubmitted) whereContentContains(snippet string) TmapLinksQueryBuilder {
+	ts.Text = strings.Replace(
+		ts.Text,
+		TMAP_CONTENT_MATCHES_JOIN,
+		TMAP_CONTENT_MATCHES_FILTER_JOIN,
+		1,
+	)
+
+	// insert 2nd-to-last before login_name
+	login_name := ts.Args[len(ts.Args)-1]
+	ts.Args = append(ts.Args[:len(ts.Args)-1], getContentMatchArg(snippet), login_name)
+
+	return ts
+}
+
 func (ts *TmapSubmitted) whereURLContains(snippet string) TmapLinksQueryBuilder {
 	for _, order_by_clause := range tmap_order_by_clauses {
 		ts.Text = strings.Replace(
@@ -379,6 +430,35 @@ func (ts *TmapSubmitted) whereURLLacks(snippet string) TmapLinksQueryBuilder {
 	return ts
 }
 
+func (ts *TmapSubmitted) whereDomain(domain string) TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		ts.Text = strings.Replace(
+			ts.Text,
+			order_by_clause,
+			"\nAND l.domain = ?"+order_by_clause,
+			1,
+		)
+	}
+
+	ts.Args = append(ts.Args, domain)
+	return ts
+}
+
+func (ts *TmapSubmitted) excludeDead() TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		ts.Text = strings.Replace(
+			ts.Text,
+			order_by_clause,
+			"\n"+TMAP_EXCLUDE_DEAD_AND+order_by_clause,
+			1,
+		)
+	}
+
+	return ts
+}
+
+const TMAP_EXCLUDE_DEAD_AND = LINKS_EXCLUDE_DEAD_AND
+
 // STARRED
 func NewTmapStarred(login_name string) *TmapStarred {
 	q := &TmapStarred{
@@ -392,7 +472,8 @@ func NewTmapStarred(login_name string) *TmapStarred {
 				TMAP_FROM +
 				STARRED_JOIN +
 				TMAP_BASE_JOINS +
-				NSFW_JOINS + "\n" +
+				NSFW_JOINS +
+				TMAP_CONTENT_MATCHES_JOIN + "\n" +
 				TMAP_NO_NSFW_CATS_WHERE +
 				STARRED_AND +
 				TMAP_ORDER_BY_TIMES_STARRED,
@@ -423,6 +504,14 @@ func (ts *TmapStarred) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBuild
 	if len(opts.NeuteredCatFiltersWithSpellingVariants) > 0 {
 		ts.fromNeuteredCatFilters(opts.NeuteredCatFiltersWithSpellingVariants)
 	}
+	// (after CTE-level filters, before WHERE-level filters, so that
+	// its arg lands in the right place)
+	if opts.ContentContains != "" {
+		ts.whereContentContains(opts.ContentContains)
+		if opts.SortBy == "" {
+			ts.sortBy(model.SortByRelevance)
+		}
+	}
 	if opts.AsSignedInUser != "" {
 		ts.asSignedInUser(opts.AsSignedInUser)
 	}
@@ -444,6 +533,12 @@ func (ts *TmapStarred) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBuild
 	if opts.URLLacks != "" {
 		ts.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		ts.whereDomain(opts.Domain)
+	}
+	if opts.ExcludeDead {
+		ts.excludeDead()
+	}
 	if ts.Error != nil {
 		return nil, ts.Error
 	}
@@ -695,6 +790,21 @@ func (ts *TmapStarred) whereSummaryContains(snippet string) TmapLinksQueryBuilde
 	return ts
 }
 
+func (ts *TmapStarred) whereContentContains(snippet string) TmapLinksQueryBuilder {
+	ts.Text = strings.Replace(
+		ts.Text,
+		TMAP_CONTENT_MATCHES_JOIN,
+		TMAP_CONTENT_MATCHES_FILTER_JOIN,
+		1,
+	)
+
+	// insert 2nd-to-last before login_name
+	login_name := ts.Args[len(ts.Args)-1]
+	ts.Args = append(ts.Args[:len(ts.Args)-1], getContentMatchArg(snippet), login_name)
+
+	return ts
+}
+
 func (ts *TmapStarred) whereURLContains(snippet string) TmapLinksQueryBuilder {
 	for _, order_by_clause := range tmap_order_by_clauses {
 		ts.Text = strings.Replace(
@@ -723,6 +833,33 @@ func (ts *TmapStarred) whereURLLacks(snippet string) TmapLinksQueryBuilder {
 	return ts
 }
 
+func (ts *TmapStarred) whereDomain(domain string) TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		ts.Text = strings.Replace(
+			ts.Text,
+			order_by_clause,
+			"\nAND l.domain = ?"+order_by_clause,
+			1,
+		)
+	}
+
+	ts.Args = append(ts.Args, domain)
+	return ts
+}
+
+func (ts *TmapStarred) excludeDead() TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		ts.Text = strings.Replace(
+			ts.Text,
+			order_by_clause,
+			"\n"+TMAP_EXCLUDE_DEAD_AND+order_by_clause,
+			1,
+		)
+	}
+
+	return ts
+}
+
 // TAGGED
 func NewTmapTagged(login_name string) *TmapTagged {
 	q := &TmapTagged{
@@ -736,7 +873,8 @@ func NewTmapTagged(login_name string) *TmapTagged {
 				TAGGED_FIELDS +
 				TMAP_FROM +
 				TAGGED_JOINS +
-				NSFW_JOINS + "\n" +
+				NSFW_JOINS +
+				TMAP_CONTENT_MATCHES_JOIN + "\n" +
 				TAGGED_NO_NSFW_CATS_WHERE +
 				TAGGED_AND +
 				TMAP_ORDER_BY_TIMES_STARRED,
@@ -801,6 +939,14 @@ func (tt *TmapTagged) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBuilde
 	if len(opts.NeuteredCatFiltersWithSpellingVariants) > 0 {
 		tt.fromNeuteredCatFilters(opts.NeuteredCatFiltersWithSpellingVariants)
 	}
+	// (after CTE-level filters, before WHERE-level filters, so that
+	// its arg lands in the right place)
+	if opts.ContentContains != "" {
+		tt.whereContentContains(opts.ContentContains)
+		if opts.SortBy == "" {
+			tt.sortBy(model.SortByRelevance)
+		}
+	}
 	if opts.AsSignedInUser != "" {
 		tt.asSignedInUser(opts.AsSignedInUser)
 	}
@@ -822,6 +968,12 @@ func (tt *TmapTagged) FromOptions(opts *model.TmapOptions) (TmapLinksQueryBuilde
 	if opts.URLLacks != "" {
 		tt.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		tt.whereDomain(opts.Domain)
+	}
+	if opts.ExcludeDead {
+		tt.excludeDead()
+	}
 	if tt.Error != nil {
 		return nil, tt.Error
 	}
@@ -1065,6 +1217,21 @@ func (tt *TmapTagged) whereSummaryContains(snippet string) TmapLinksQueryBuilder
 	return tt
 }
 
+func (tt *TmapTagged) whereContentContains(snippet string) TmapLinksQueryBuilder {
+	tt.Text = strings.Replace(
+		tt.Text,
+		TMAP_CONTENT_MATCHES_JOIN,
+		TMAP_CONTENT_MATCHES_FILTER_JOIN,
+		1,
+	)
+
+	// insert 2nd-to-last before login_name
+	login_name := tt.Args[len(tt.Args)-1]
+	tt.Args = append(tt.Args[:len(tt.Args)-1], getContentMatchArg(snippet), login_name)
+
+	return tt
+}
+
 func (tt *TmapTagged) whereURLContains(snippet string) TmapLinksQueryBuilder {
 	for _, order_by_clause := range tmap_order_by_clauses {
 		tt.Text = strings.Replace(
@@ -1093,10 +1260,37 @@ func (tt *TmapTagged) whereURLLacks(snippet string) TmapLinksQueryBuilder {
 	return tt
 }
 
+func (tt *TmapTagged) whereDomain(domain string) TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		tt.Text = strings.Replace(
+			tt.Text,
+			order_by_clause,
+			"\nAND l.domain = ?"+order_by_clause,
+			1,
+		)
+	}
+
+	tt.Args = append(tt.Args, domain)
+	return tt
+}
+
+func (tt *TmapTagged) excludeDead() TmapLinksQueryBuilder {
+	for _, order_by_clause := range tmap_order_by_clauses {
+		tt.Text = strings.Replace(
+			tt.Text,
+			order_by_clause,
+			"\n"+TMAP_EXCLUDE_DEAD_AND+order_by_clause,
+			1,
+		)
+	}
+
+	return tt
+}
+
 // LINKS SHARED BUILDING BLOCKS
-const TMAP_BASE_CTES = `SummaryCount AS (
+var TMAP_BASE_CTES = `SummaryCount AS (
     SELECT link_id, COUNT(*) AS summary_count
-    FROM Summaries
+    FROM "Visible Summaries"
     GROUP BY link_id
 ),
 TimesStarred AS (
@@ -1104,11 +1298,7 @@ TimesStarred AS (
     FROM Stars
     GROUP BY link_id
 ),
-AverageStars AS (
-	SELECT link_id, ROUND(AVG(num_stars), 2) AS avg_stars
-	FROM Stars
-	GROUP BY link_id
-),
+` + AVERAGE_STARS_CTE + `,
 EarliestStarrers AS (
     SELECT 
         link_id,
@@ -1128,15 +1318,17 @@ EarliestStarrers AS (
 ClickCount AS (
 	SELECT link_id, count(*) AS click_count
 	FROM Clicks
+	WHERE disqualified_reason = ''
 	GROUP BY link_id
 ),
 TagCount AS (
     SELECT link_id, COUNT(*) AS tag_count
-    FROM Tags
+    FROM "Visible Tags"
     GROUP BY link_id
-),`
+),
+` + COMMENT_COUNT_CTE + `,`
 
-const TMAP_BASE_FIELDS = `
+var TMAP_BASE_FIELDS = `
 SELECT 
 	l.id AS link_id,
     l.url,
@@ -1148,12 +1340,17 @@ SELECT
     COALESCE(sc.summary_count, 0) AS summary_count,
     COALESCE(ts.times_starred, 0) AS times_starred,
 	COALESCE(avs.avg_stars, 0) AS avg_stars,
+	` + RATING_FIELD + ` AS rating,
 	COALESCE(es.earliest_starrers, '') AS earliest_starrers,
 	COALESCE(clc.click_count, 0) AS click_count,
     COALESCE(tc.tag_count, 0) AS tag_count,
-    COALESCE(l.img_file, '') AS img_file`
+    COALESCE(cmc.comment_count, 0) AS comment_count,
+    COALESCE(l.img_file, '') AS img_file,
+    COALESCE(l.health, '') AS health,
+    COALESCE(l.last_checked_at, '') AS last_checked_at,
+    COALESCE(cm.content_snippet, '') AS content_snippet`
 
-const TMAP_FROM_CATS_FIELDS = `
+var TMAP_FROM_CATS_FIELDS = `
 SELECT 
 	l.id AS link_id,
     l.url,
@@ -1165,13 +1362,22 @@ SELECT
     COALESCE(sc.summary_count, 0) AS summary_count,
     COALESCE(ts.times_starred, 0) AS times_starred,
 	COALESCE(avs.avg_stars, 0) AS avg_stars,
+	` + RATING_FIELD + ` AS rating,
 	COALESCE(es.earliest_starrers, '') AS earliest_starrers,
 	COALESCE(clc.click_count, 0) AS click_count,
     COALESCE(tc.tag_count, 0) AS tag_count,
-    COALESCE(l.img_file, '') AS img_file`
+    COALESCE(cmc.comment_count, 0) AS comment_count,
+    COALESCE(l.img_file, '') AS img_file,
+    COALESCE(l.health, '') AS health,
+    COALESCE(l.last_checked_at, '') AS last_checked_at,
+    COALESCE(cm.content_snippet, '') AS content_snippet`
 
 const TMAP_FROM = LINKS_FROM
 
+const TMAP_FROM_INCLUDING_PRIVATE = `
+FROM
+	Links l`
+
 const TMAP_BASE_JOINS = `
 LEFT JOIN PossibleUserCatsAny puca ON l.id = puca.link_id
 LEFT JOIN PossibleUserSummary pus ON l.id = pus.link_id
@@ -1180,7 +1386,8 @@ LEFT JOIN AverageStars avs ON l.id = avs.link_id
 LEFT JOIN EarliestStarrers es ON l.id = es.link_id
 LEFT JOIN ClickCount clc ON l.id = clc.link_id
 LEFT JOIN TagCount tc ON l.id = tc.link_id
-LEFT JOIN SummaryCount sc ON l.id = sc.link_id`
+LEFT JOIN SummaryCount sc ON l.id = sc.link_id
+LEFT JOIN CommentCount cmc ON l.id = cmc.link_id`
 
 var tmap_order_by_clauses = map[model.SortBy]string{
 	model.SortByTimesStarred: TMAP_ORDER_BY_TIMES_STARRED,
@@ -1188,6 +1395,9 @@ var tmap_order_by_clauses = map[model.SortBy]string{
 	model.SortByNewest:       TMAP_ORDER_BY_NEWEST,
 	model.SortByOldest:       TMAP_ORDER_BY_OLDEST,
 	model.SortByClicks:       TMAP_ORDER_BY_CLICKS,
+	model.SortByRelevance:    TMAP_ORDER_BY_RELEVANCE,
+	model.SortByHot:          TMAP_ORDER_BY_HOT,
+	model.SortByRating:       TMAP_ORDER_BY_RATING,
 }
 
 const TMAP_ORDER_BY_TIMES_STARRED = `
@@ -1196,7 +1406,7 @@ ORDER BY
 	avs.avg_stars DESC,
 	clc.click_count DESC,
 	tc.tag_count DESC,
-	sc.summary_count DESC, l.id DESC,
+	sc.summary_count DESC,
 	l.submit_date DESC,
 	l.id DESC;`
 
@@ -1210,6 +1420,17 @@ ORDER BY
 	l.submit_date DESC,
 	l.id DESC`
 
+const TMAP_ORDER_BY_RATING = `
+ORDER BY 
+	rating DESC, 
+	ts.times_starred DESC,
+	avs.avg_stars DESC,
+	clc.click_count DESC,
+	tc.tag_count DESC,
+	sc.summary_count DESC, 
+	l.submit_date DESC,
+	l.id DESC;`
+
 const TMAP_ORDER_BY_NEWEST = `
 ORDER BY 
 	l.submit_date DESC, 
@@ -1239,6 +1460,30 @@ ORDER BY
 	sc.summary_count DESC, 
 	l.id DESC;`
 
+const TMAP_ORDER_BY_RELEVANCE = `
+ORDER BY
+	cm.content_rank ASC, 
+	ts.times_starred DESC, 
+	avs.avg_stars DESC,
+	clc.click_count DESC,
+	tc.tag_count DESC,
+	sc.summary_count DESC, 
+	l.id DESC;`
+
+var TMAP_ORDER_BY_HOT = `
+ORDER BY
+	` + HOT_SCORE + ` DESC, 
+	ts.times_starred DESC, 
+	avs.avg_stars DESC,
+	clc.click_count DESC,
+	tc.tag_count DESC,
+	sc.summary_count DESC, 
+	l.submit_date DESC,
+	l.id DESC;`
+
+const TMAP_CONTENT_MATCHES_JOIN = LINKS_CONTENT_MATCHES_JOIN
+const TMAP_CONTENT_MATCHES_FILTER_JOIN = LINKS_CONTENT_MATCHES_FILTER_JOIN
+
 // Authenticated
 const TMAP_AUTH_CTE = `
 StarsAssigned AS (
@@ -1269,7 +1514,7 @@ const POSSIBLE_USER_SUMMARY_CTE = `PossibleUserSummary AS (
 	SELECT
 		link_id, 
 		text as user_summary
-	FROM Summaries
+	FROM "Visible Summaries"
 	INNER JOIN Users u ON u.id = submitted_by
 	WHERE u.login_name = ?
 )`
@@ -1285,6 +1530,7 @@ INNER JOIN UserStars us ON l.id = us.link_id`
 // (because some are hidden).
 type TmapNSFWLinksCount struct {
 	*Query
+	loginName string
 }
 
 func NewTmapNSFWLinksCount(login_name string) *TmapNSFWLinksCount {
@@ -1294,7 +1540,7 @@ func NewTmapNSFWLinksCount(login_name string) *TmapNSFWLinksCount {
 				NSFW_CATS_CTES + "\n" +
 				USER_STARS_CTE + `
 			SELECT count(*) as NSFW_link_count
-				FROM Links l` + "\n" +
+				FROM "Public Links" l` + "\n" +
 				"LEFT JOIN PossibleUserCatsAny puca ON l.id = puca.link_id" +
 				NSFW_JOINS + "\n" +
 				NSFW_LINKS_COUNT_WHERE +
@@ -1306,6 +1552,7 @@ func NewTmapNSFWLinksCount(login_name string) *TmapNSFWLinksCount {
 				login_name,
 			},
 		},
+		login_name,
 	}
 }
 
@@ -1323,6 +1570,9 @@ func (tnlc *TmapNSFWLinksCount) FromOptions(opts *model.TmapNSFWLinksCountOption
 			return tnlc, nil
 		}
 	}
+	if opts.IncludePrivate {
+		tnlc.includePrivate()
+	}
 	if len(opts.CatFiltersWithSpellingVariants) > 0 {
 		tnlc.fromCatFilters(opts.CatFiltersWithSpellingVariants)
 	}
@@ -1332,12 +1582,21 @@ func (tnlc *TmapNSFWLinksCount) FromOptions(opts *model.TmapNSFWLinksCountOption
 	if opts.SummaryContains != "" {
 		tnlc.whereSummaryContains(opts.SummaryContains)
 	}
+	if opts.ContentContains != "" {
+		tnlc.whereContentContains(opts.ContentContains)
+	}
 	if opts.URLContains != "" {
 		tnlc.whereURLContains(opts.URLContains)
 	}
 	if opts.URLLacks != "" {
 		tnlc.whereURLLacks(opts.URLLacks)
 	}
+	if opts.Domain != "" {
+		tnlc.whereDomain(opts.Domain)
+	}
+	if opts.ExcludeDead {
+		tnlc.excludeDead()
+	}
 	if tnlc.Error != nil {
 		return nil, tnlc.Error
 	}
@@ -1380,6 +1639,34 @@ func (tnlc *TmapNSFWLinksCount) taggedOnly() *TmapNSFWLinksCount {
 	return tnlc
 }
 
+// Only for the Treasure Map owner: their own private links are counted,
+// but not others' which they starred or tagged before those were made
+// private
+func (tnlc *TmapNSFWLinksCount) includePrivate() *TmapNSFWLinksCount {
+	tnlc.Text = strings.Replace(
+		tnlc.Text,
+		`FROM "Public Links" l`,
+		"FROM Links l",
+		1,
+	)
+	// Bind login_name where the condition's placeholder lands, i.e., after
+	// every placeholder before it
+	where_index := strings.Index(tnlc.Text, NSFW_LINKS_COUNT_WHERE)
+	arg_index := strings.Count(tnlc.Text[:where_index+len(NSFW_LINKS_COUNT_WHERE)], "?")
+	tnlc.Text = strings.Replace(
+		tnlc.Text,
+		NSFW_LINKS_COUNT_WHERE,
+		NSFW_LINKS_COUNT_WHERE+TMAP_OWN_PRIVATE_LINKS_AND,
+		1,
+	)
+	tnlc.Args = slices.Insert(tnlc.Args, arg_index, any(tnlc.loginName))
+
+	return tnlc
+}
+
+const TMAP_OWN_PRIVATE_LINKS_AND = `
+AND (l.id IN (SELECT id FROM "Public Links") OR l.submitted_by = ?)`
+
 func (tnlc *TmapNSFWLinksCount) fromCatFilters(cat_filters []string) *TmapNSFWLinksCount {
 	if len(cat_filters) == 0 || cat_filters[0] == "" {
 		return tnlc
@@ -1501,6 +1788,17 @@ func (tnlc *TmapNSFWLinksCount) whereSummaryContains(snippet string) *TmapNSFWLi
 	return tnlc
 }
 
+func (tnlc *TmapNSFWLinksCount) whereContentContains(snippet string) *TmapNSFWLinksCount {
+	tnlc.Text = strings.Replace(
+		tnlc.Text,
+		";",
+		"\nAND l.id IN (SELECT link_id FROM page_content_fts WHERE page_content_fts MATCH ?);",
+		1,
+	)
+	tnlc.Args = append(tnlc.Args, getContentMatchArg(snippet))
+	return tnlc
+}
+
 func (tnlc *TmapNSFWLinksCount) whereURLContains(snippet string) *TmapNSFWLinksCount {
 	tnlc.Text = strings.Replace(
 		tnlc.Text,
@@ -1523,6 +1821,27 @@ func (tnlc *TmapNSFWLinksCount) whereURLLacks(snippet string) *TmapNSFWLinksCoun
 	return tnlc
 }
 
+func (tnlc *TmapNSFWLinksCount) whereDomain(domain string) *TmapNSFWLinksCount {
+	tnlc.Text = strings.Replace(
+		tnlc.Text,
+		";",
+		"\nAND l.domain = ?;",
+		1,
+	)
+	tnlc.Args = append(tnlc.Args, domain)
+	return tnlc
+}
+
+func (tnlc *TmapNSFWLinksCount) excludeDead() *TmapNSFWLinksCount {
+	tnlc.Text = strings.Replace(
+		tnlc.Text,
+		";",
+		"\n"+TMAP_EXCLUDE_DEAD_AND+";",
+		1,
+	)
+	return tnlc
+}
+
 // SHARED BUILDING BLOCKS FOR LINKS AND NSFW COUNT QUERIES
 const NSFW_CATS_CTES = `PossibleUserCatsNSFW AS (
     SELECT 
@@ -1678,3 +1997,37 @@ const TMAP_PROFILE = `SELECT
 	created
 FROM Users 
 WHERE login_name = ?;`
+
+// EXPORT
+// Owner's stars and tag dates for every link in their Treasure Map, plus
+// global cats (not included in TMAP_BASE_FIELDS since they are replaced
+// by the owner's cats where present)
+type TmapExportDetails struct {
+	*Query
+}
+
+func NewTmapExportDetails(login_name string) *TmapExportDetails {
+	return &TmapExportDetails{
+		&Query{
+			Text: TMAP_EXPORT_DETAILS,
+			Args: []any{login_name, login_name, login_name},
+		},
+	}
+}
+
+const TMAP_EXPORT_DETAILS = `SELECT
+	l.id,
+	COALESCE(l.global_cats, '') AS global_cats,
+	COALESCE(s.num_stars, 0) AS stars_assigned,
+	COALESCE(s.timestamp, '') AS starred_at,
+	COALESCE(t.last_updated, '') AS tagged_at
+FROM Links l
+LEFT JOIN Stars s
+	ON s.link_id = l.id
+	AND s.user_id = (SELECT id FROM Users WHERE login_name = ?)
+LEFT JOIN Tags t
+	ON t.link_id = l.id
+	AND t.submitted_by = ?
+WHERE l.submitted_by = ?
+OR s.id IS NOT NULL
+OR t.id IS NOT NULL;`
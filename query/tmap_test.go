@@ -41,10 +41,15 @@ func TestNewTmapSubmitted(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if l.SubmittedBy != TEST_LOGIN_NAME {
@@ -107,10 +112,15 @@ func TestTmapSubmittedFromCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if !strings.Contains(l.Cats, test_cats[0]) || !strings.Contains(l.Cats, test_cats[1]) {
@@ -146,10 +156,15 @@ func TestTmapSubmittedFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -192,10 +207,15 @@ func TestTmapSubmittedFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -233,10 +253,15 @@ func TestTmapSubmittedAsSignedInUser(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&l.StarsAssigned,
 		); err != nil {
 			t.Fatal(err)
@@ -261,6 +286,8 @@ func TestTmapSubmittedSortBy(t *testing.T) {
 		{"avg_stars", true},
 		{"oldest", true},
 		{"clicks", true},
+		{"hot", true},
+		{"rating", true},
 		{"random", false},
 		{"invalid", false},
 	}
@@ -292,10 +319,15 @@ func TestTmapSubmittedSortBy(t *testing.T) {
 					&l.SummaryCount,
 					&l.TimesStarred,
 					&l.AvgStars,
+					&l.Rating,
 					&l.EarliestStarrers,
 					&l.ClickCount,
 					&l.TagCount,
+					&l.CommentCount,
 					&l.PreviewImgFilename,
+					&l.Health,
+					&l.LastCheckedAt,
+					&l.ContentSnippet,
 				); err != nil {
 					t.Fatal(err)
 				}
@@ -409,10 +441,15 @@ func TestTmapSubmittedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else {
@@ -440,10 +477,15 @@ func TestTmapSubmittedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else {
@@ -497,10 +539,15 @@ func TestTmapSubmittedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else {
@@ -561,10 +608,15 @@ AND l.submitted_by = ?;`
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -615,10 +667,15 @@ func TestTmapSubmittedWhereURLContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -633,6 +690,169 @@ func TestTmapSubmittedWhereURLContains(t *testing.T) {
 }
 
 // Starred
+func TestTmapSubmittedExcludeDead(t *testing.T) {
+	var test_link_id string
+	if err := TestClient.QueryRow(
+		"SELECT id FROM Links WHERE submitted_by = ? LIMIT 1;",
+		TEST_LOGIN_NAME,
+	).Scan(&test_link_id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := TestClient.Exec(
+		"UPDATE Links SET health = 'dead' WHERE id = ?;",
+		test_link_id,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec(
+		"UPDATE Links SET health = NULL WHERE id = ?;",
+		test_link_id,
+	)
+
+	submitted_sql := NewTmapSubmitted(TEST_LOGIN_NAME).
+		includeNSFW().
+		excludeDead().
+		Build()
+	rows, err := submitted_sql.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l := model.TmapLink{}
+		if err := rows.Scan(
+			&l.ID,
+			&l.URL,
+			&l.SubmittedBy,
+			&l.SubmitDate,
+			&l.Cats,
+			&l.CatsFromUser,
+			&l.Summary,
+			&l.SummaryCount,
+			&l.TimesStarred,
+			&l.AvgStars,
+			&l.Rating,
+			&l.EarliestStarrers,
+			&l.ClickCount,
+			&l.TagCount,
+			&l.CommentCount,
+			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
+		); err != nil {
+			t.Fatal(err)
+		} else if l.Health == model.LinkHealthDead {
+			t.Fatalf("got dead link %s, should be excluded", l.ID)
+		}
+	}
+}
+
+func TestTmapContentContains(t *testing.T) {
+	// Index the same content for every link so that filtering by it should
+	// return exactly what the unfiltered query does
+	if _, err := TestClient.Exec(
+		`INSERT INTO page_content_fts (link_id, content)
+		SELECT id, 'an article about the elusive pangolin'
+		FROM Links;`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM page_content_fts;")
+
+	var test_builders = []func() TmapLinksQueryBuilder{
+		func() TmapLinksQueryBuilder { return NewTmapSubmitted(TEST_LOGIN_NAME) },
+		func() TmapLinksQueryBuilder { return NewTmapStarred(TEST_LOGIN_NAME) },
+		func() TmapLinksQueryBuilder { return NewTmapTagged(TEST_LOGIN_NAME) },
+	}
+	var test_options = []model.TmapOptions{
+		{IncludeNSFW: true},
+		{
+			NeuteredCatFiltersWithSpellingVariants: []string{"nonexistent-cat"},
+			AsSignedInUser:                         TEST_USER_ID,
+			SortBy:                                 model.SortByNewest,
+			Period:                                 "all",
+		},
+	}
+
+	for _, new_builder := range test_builders {
+		for _, opts := range test_options {
+			unfiltered, err := new_builder().FromOptions(&opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			unfiltered_ids, _ := getTmapLinkIDsAndSnippets(t, unfiltered.Build())
+
+			opts.ContentContains = "elusive pangolin"
+			filtered, err := new_builder().FromOptions(&opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filtered_ids, snippets := getTmapLinkIDsAndSnippets(t, filtered.Build())
+
+			if len(filtered_ids) != len(unfiltered_ids) {
+				t.Fatalf(
+					"expected %d links matching content, got %d (sql: %s)",
+					len(unfiltered_ids),
+					len(filtered_ids),
+					filtered.Build().Text,
+				)
+			}
+			for _, snippet := range snippets {
+				if !strings.Contains(snippet, "<mark>elusive</mark>") {
+					t.Fatalf("expected highlighted snippet, got %q", snippet)
+				}
+			}
+
+			opts.ContentContains = "nonexistent-content"
+			filtered, err = new_builder().FromOptions(&opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filtered_ids, _ = getTmapLinkIDsAndSnippets(t, filtered.Build()); len(filtered_ids) != 0 {
+				t.Fatalf("expected no links matching content, got %d", len(filtered_ids))
+			}
+		}
+	}
+}
+
+func getTmapLinkIDsAndSnippets(t *testing.T, q *Query) ([]string, []string) {
+	rows, err := q.ValidateAndExecuteRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids, snippets []string
+	for rows.Next() {
+		var id, snippet string
+		dest := []any{&id}
+		for _, col := range cols[1:] {
+			if col == "content_snippet" {
+				dest = append(dest, &snippet)
+			} else {
+				var v any
+				dest = append(dest, &v)
+			}
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		snippets = append(snippets, snippet)
+	}
+
+	return ids, snippets
+}
+
 func TestNewTmapStarred(t *testing.T) {
 	starred_sql := NewTmapStarred(TEST_LOGIN_NAME)
 	rows, err := starred_sql.Build().ValidateAndExecuteRows()
@@ -654,10 +874,15 @@ func TestNewTmapStarred(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if l.TagCount == 0 {
@@ -702,10 +927,15 @@ func TestTmapStarredFromCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if !strings.Contains(l.Cats, test_cats[0]) || !strings.Contains(l.Cats, test_cats[1]) {
@@ -754,10 +984,15 @@ func TestTmapStarredFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -798,10 +1033,15 @@ func TestTmapStarredFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -838,10 +1078,15 @@ func TestTmapStarredAsSignedInUser(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&l.StarsAssigned,
 		); err != nil {
 			t.Fatal(err)
@@ -923,10 +1168,15 @@ func TestTmapStarredAsSignedInUser(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 			&l.StarsAssigned,
 		); err != nil {
 			t.Fatal(err)
@@ -970,10 +1220,15 @@ func TestTmapStarredIncludeNSFW(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if strings.Contains(l.Cats, "NSFW") {
@@ -1008,10 +1263,15 @@ func TestTmapStarredDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1039,10 +1299,15 @@ func TestTmapStarredDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1073,10 +1338,15 @@ func TestTmapStarredDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1137,10 +1407,15 @@ func TestTmapStarredWhereSummaryContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -1193,10 +1468,15 @@ func TestTmapStarredWhereURLContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -1231,10 +1511,15 @@ func TestNewTmapTagged(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if l.TagCount == 0 {
@@ -1277,10 +1562,15 @@ func TestTmapTaggedFromCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if !strings.Contains(l.Cats, test_cats[0]) || !strings.Contains(l.Cats, test_cats[1]) {
@@ -1330,10 +1620,15 @@ func TestTmapTaggedFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1375,10 +1670,15 @@ func TestTmapTaggedFromNeuteredCatFilters(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1422,10 +1722,15 @@ func TestTmapTaggedIncludeNSFW(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		} else if strings.Contains(l.Cats, "NSFW") {
@@ -1460,10 +1765,15 @@ func TestTmapTaggedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1491,10 +1801,15 @@ func TestTmapTaggedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1525,10 +1840,15 @@ func TestTmapTaggedDuringPeriod(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		); err != nil {
 			t.Fatal(err)
 		}
@@ -1598,10 +1918,15 @@ func TestTmapTaggedWhereSummaryContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -1673,10 +1998,15 @@ func TestTmapTaggedWhereURLContains(t *testing.T) {
 			&l.SummaryCount,
 			&l.TimesStarred,
 			&l.AvgStars,
+			&l.Rating,
 			&l.EarliestStarrers,
 			&l.ClickCount,
 			&l.TagCount,
+			&l.CommentCount,
 			&l.PreviewImgFilename,
+			&l.Health,
+			&l.LastCheckedAt,
+			&l.ContentSnippet,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -2261,3 +2591,117 @@ func TestNewTmapProfile(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestTmapSubmittedIncludePrivate(t *testing.T) {
+	if _, err := TestClient.Exec(
+		`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, visibility)
+		VALUES ('tmap-private-test', 'https://tmap-private-test.com', ?, ?, 'test', 'private');`,
+		TEST_LOGIN_NAME,
+		time.Now().UTC().Format("2006-01-02 15:04:05"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Links WHERE id = 'tmap-private-test';")
+
+	var test_cases = []struct {
+		IncludePrivate bool
+		ExpectedCount  int
+	}{
+		{false, 0},
+		{true, 1},
+	}
+	for _, tc := range test_cases {
+		submitted_sql, err := NewTmapSubmitted(TEST_LOGIN_NAME).FromOptions(&model.TmapOptions{
+			IncludePrivate: tc.IncludePrivate,
+			URLContains:    "tmap-private-test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := submitted_sql.Build().ValidateAndExecuteRows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		for rows.Next() {
+			count++
+		}
+		rows.Close()
+
+		if count != tc.ExpectedCount {
+			t.Fatalf(
+				"expected %d links (include private: %t), got %d",
+				tc.ExpectedCount,
+				tc.IncludePrivate,
+				count,
+			)
+		}
+	}
+}
+
+func TestTmapNSFWLinksCountIncludePrivate(t *testing.T) {
+	test_links := []struct {
+		ID          string
+		SubmittedBy string
+	}{
+		{"tmap-nsfw-private-test-own", TEST_LOGIN_NAME},
+		// starred by the owner, but private to someone else
+		{"tmap-nsfw-private-test-other", "bradley"},
+	}
+	for _, tl := range test_links {
+		if _, err := TestClient.Exec(
+			`INSERT INTO Links (id, url, submitted_by, submit_date, global_cats, visibility)
+			VALUES (?, ?, ?, ?, 'NSFW', 'private');`,
+			tl.ID,
+			"https://"+tl.ID+".com",
+			tl.SubmittedBy,
+			time.Now().UTC().Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer TestClient.Exec("DELETE FROM Links WHERE id = ?;", tl.ID)
+	}
+	if _, err := TestClient.Exec(
+		`INSERT INTO Stars (id, link_id, user_id, num_stars, timestamp)
+		VALUES ('tmap-nsfw-private-test-star', 'tmap-nsfw-private-test-other', ?, 3, ?);`,
+		TEST_USER_ID,
+		time.Now().UTC().Format("2006-01-02 15:04:05"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer TestClient.Exec("DELETE FROM Stars WHERE id = 'tmap-nsfw-private-test-star';")
+
+	var test_cases = []struct {
+		IncludePrivate bool
+		ExpectedCount  int
+	}{
+		{false, 0},
+		{true, 1},
+	}
+	for _, tc := range test_cases {
+		count_sql, err := NewTmapNSFWLinksCount(TEST_LOGIN_NAME).FromOptions(&model.TmapNSFWLinksCountOptions{
+			IncludePrivate: tc.IncludePrivate,
+			URLContains:    "tmap-nsfw-private-test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		row, err := count_sql.ValidateAndExecuteRow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		if err := row.Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+
+		if count != tc.ExpectedCount {
+			t.Fatalf(
+				"expected %d NSFW links (include private: %t), got %d",
+				tc.ExpectedCount,
+				tc.IncludePrivate,
+				count,
+			)
+		}
+	}
+}
@@ -6,11 +6,12 @@ func NewTotals() *Query {
 	return &Query{
 		Text: `WITH LinksTotal AS (
 			SELECT COUNT(*) AS link_count
-			FROM Links
+			FROM "Public Links"
 		),
 		ClicksTotal AS (
 			SELECT COUNT(*) AS click_count
 			FROM Clicks
+			WHERE disqualified_reason = ''
 		),
 		ContributorsTotal AS (
 			SELECT COUNT(*) AS user_count
@@ -23,11 +24,11 @@ func NewTotals() *Query {
 		),
 		TagsTotal AS (
 			SELECT COUNT(*) AS tag_count
-			FROM Tags
+			FROM "Visible Tags"
 		),
 		SummariesTotal AS (
 			SELECT COUNT(*) AS summary_count
-			FROM Summaries
+			FROM "Visible Summaries"
 			WHERE submitted_by != ?
 		)
 		SELECT *